@@ -0,0 +1,358 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package kms
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/minio/kms-go/kms/cmds"
+	"github.com/minio/kms-go/kms/internal/pool"
+	pb "github.com/minio/kms-go/kms/protobuf"
+)
+
+// Default batching limits used by a Batcher.
+const (
+	DefaultBatchWindow  = 1 * time.Millisecond
+	DefaultBatchSize    = 100
+	DefaultBatchTimeout = 30 * time.Second
+)
+
+// BatcherConfig is a structure containing configuration
+// options for a Batcher.
+type BatcherConfig struct {
+	// Window is the amount of time a Batcher waits for
+	// further requests before sending a batch. If zero,
+	// DefaultBatchWindow is used.
+	Window time.Duration
+
+	// MaxSize is the max. number of requests within a
+	// batch. Once reached, the batch is sent immediately.
+	// If zero, DefaultBatchSize is used.
+	MaxSize int
+
+	// Timeout limits how long a Batcher waits for the KMS
+	// to respond to a batch. Once exceeded, all requests
+	// of the batch fail, regardless of the callers' own
+	// contexts. If zero, DefaultBatchTimeout is used.
+	Timeout time.Duration
+}
+
+// Batcher coalesces concurrent Encrypt, Decrypt, GenerateKey
+// and MAC requests for the same enclave into a single request.
+//
+// Each call blocks until the batch containing its request has
+// been sent and the response has been received. A batch is sent
+// once the batch window has elapsed or the batch is full.
+//
+// A single invalid request, for example a ciphertext that is not
+// authentic, does not fail other requests of the same batch. The
+// Batcher asks the KMS for partial results and, if the KMS does
+// not support them, resends the requests of a batch that failed
+// due to a single request separately.
+//
+// A Batcher is safe for concurrent use by multiple goroutines.
+type Batcher struct {
+	client  *Client
+	window  time.Duration
+	maxSize int
+	timeout time.Duration
+
+	mu      sync.Mutex
+	batches map[string]*batch // Pending batches per enclave
+}
+
+// NewBatcher returns a new Batcher that sends batches using
+// the given client. If conf is nil, the default configuration
+// is used.
+func NewBatcher(client *Client, conf *BatcherConfig) *Batcher {
+	if conf == nil {
+		conf = &BatcherConfig{}
+	}
+	window := conf.Window
+	if window <= 0 {
+		window = DefaultBatchWindow
+	}
+	maxSize := conf.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultBatchSize
+	}
+	timeout := conf.Timeout
+	if timeout <= 0 {
+		timeout = DefaultBatchTimeout
+	}
+	return &Batcher{
+		client:  client,
+		window:  window,
+		maxSize: maxSize,
+		timeout: timeout,
+		batches: map[string]*batch{},
+	}
+}
+
+// Encrypt encrypts the req.Plaintext, like Client.Encrypt, as
+// part of the next batch for the enclave.
+func (b *Batcher) Encrypt(ctx context.Context, enclave string, req *EncryptRequest) (*EncryptResponse, error) {
+	body, err := cmds.Encode(nil, cmds.KeyEncrypt, req)
+	if err != nil {
+		return nil, hostError("", err)
+	}
+
+	var resp EncryptResponse
+	err = b.do(ctx, enclave, body, func(buf []byte) ([]byte, error) {
+		return cmds.Decode[pb.EncryptResponse](buf, cmds.KeyEncrypt, &resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Decrypt decrypts the req.Ciphertext, like Client.Decrypt, as
+// part of the next batch for the enclave.
+func (b *Batcher) Decrypt(ctx context.Context, enclave string, req *DecryptRequest) (*DecryptResponse, error) {
+	body, err := cmds.Encode(nil, cmds.KeyDecrypt, req)
+	if err != nil {
+		return nil, hostError("", err)
+	}
+
+	var resp DecryptResponse
+	err = b.do(ctx, enclave, body, func(buf []byte) ([]byte, error) {
+		return cmds.Decode[pb.DecryptResponse](buf, cmds.KeyDecrypt, &resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GenerateKey generates a new data encryption key, like
+// Client.GenerateKey, as part of the next batch for the
+// enclave.
+func (b *Batcher) GenerateKey(ctx context.Context, enclave string, req *GenerateKeyRequest) (*GenerateKeyResponse, error) {
+	body, err := cmds.Encode(nil, cmds.KeyGenerate, req)
+	if err != nil {
+		return nil, hostError("", err)
+	}
+
+	var resp GenerateKeyResponse
+	err = b.do(ctx, enclave, body, func(buf []byte) ([]byte, error) {
+		return cmds.Decode[pb.GenerateKeyResponse](buf, cmds.KeyGenerate, &resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// MAC computes a message authentication code, like Client.MAC,
+// as part of the next batch for the enclave.
+func (b *Batcher) MAC(ctx context.Context, enclave string, req *MACRequest) (*MACResponse, error) {
+	body, err := cmds.Encode(nil, cmds.KeyMAC, req)
+	if err != nil {
+		return nil, hostError("", err)
+	}
+
+	var resp MACResponse
+	err = b.do(ctx, enclave, body, func(buf []byte) ([]byte, error) {
+		return cmds.Decode[pb.MACResponse](buf, cmds.KeyMAC, &resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Flush sends all pending batches immediately.
+func (b *Batcher) Flush() {
+	b.mu.Lock()
+	batches := b.batches
+	b.batches = map[string]*batch{}
+	b.mu.Unlock()
+
+	for enclave, batch := range batches {
+		batch.Timer.Stop()
+		go b.send(enclave, batch.Calls)
+	}
+}
+
+// batch is a list of pending requests for one enclave.
+type batch struct {
+	Calls []*batchCall
+	Timer *time.Timer
+}
+
+// batchCall is a single request within a batch.
+type batchCall struct {
+	Body   []byte                       // The encoded command
+	Decode func([]byte) ([]byte, error) // Decodes the command response
+	Done   chan error                   // Receives the result once the batch has been sent
+}
+
+// do adds the encoded command to the next batch for the
+// enclave and waits until the batch has been sent or the
+// ctx is done.
+func (b *Batcher) do(ctx context.Context, enclave string, body []byte, decode func([]byte) ([]byte, error)) error {
+	call := &batchCall{
+		Body:   body,
+		Decode: decode,
+		Done:   make(chan error, 1),
+	}
+
+	b.mu.Lock()
+	bt, ok := b.batches[enclave]
+	if !ok {
+		bt = &batch{}
+		bt.Timer = time.AfterFunc(b.window, func() { b.flush(enclave, bt) })
+		b.batches[enclave] = bt
+	}
+	bt.Calls = append(bt.Calls, call)
+	if len(bt.Calls) >= b.maxSize {
+		bt.Timer.Stop()
+		delete(b.batches, enclave)
+		go b.send(enclave, bt.Calls)
+	}
+	b.mu.Unlock()
+
+	select {
+	case err := <-call.Done:
+		return err
+	case <-ctx.Done():
+		return hostError("", ctx.Err())
+	}
+}
+
+// flush sends the batch if it is still pending.
+func (b *Batcher) flush(enclave string, bt *batch) {
+	b.mu.Lock()
+	if b.batches[enclave] != bt {
+		b.mu.Unlock()
+		return
+	}
+	delete(b.batches, enclave)
+	b.mu.Unlock()
+
+	b.send(enclave, bt.Calls)
+}
+
+// send sends all calls as a single request to the KMS
+// and passes the result to each call. The request is not
+// bound to any caller's context since the batch is shared
+// by all calls. Instead, it is canceled once the Batcher's
+// timeout is exceeded.
+//
+// The KMS is asked to execute all commands, even if some
+// of them fail. If the KMS does not support this and rejects
+// the request with an error caused by a single command, send
+// resends each call separately. Any other error fails all
+// calls.
+func (b *Batcher) send(enclave string, calls []*batchCall) {
+	var size int
+	for _, call := range calls {
+		size += len(call.Body)
+	}
+	p := pool.Get(size)
+	defer pool.Put(p)
+
+	body := (*p)[:0]
+	for _, call := range calls {
+		body = append(body, call.Body...)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+
+	resp, err := b.client.Send(ctx, &Request{
+		Enclave: enclave,
+		Body:    body,
+		Partial: true,
+	})
+	if err != nil {
+		if len(calls) > 1 && isCommandError(err) {
+			for _, call := range calls {
+				go b.send(enclave, []*batchCall{call})
+			}
+			return
+		}
+		for _, call := range calls {
+			call.Done <- err
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	errs, err := decodeBatch(resp, calls)
+	if err != nil {
+		for _, call := range calls {
+			call.Done <- err
+		}
+		return
+	}
+	for i, call := range calls {
+		call.Done <- errs[i]
+	}
+}
+
+// isCommandError reports whether err may be caused by a single
+// command of a request, like an invalid ciphertext or a key that
+// does not exist, instead of the request as a whole. For example,
+// ErrPermission or ErrEnclaveNotFound apply to all commands.
+func isCommandError(err error) bool {
+	var e Error
+	if !errors.As(err, &e) {
+		return false
+	}
+	return e.Code == http.StatusBadRequest || e == ErrKeyNotFound
+}
+
+// decodeBatch decodes the response to a batch. The
+// response contains one command per call in the same
+// order. It returns the error of each failed call.
+func decodeBatch(r *http.Response, calls []*batchCall) ([]error, error) {
+	if r.ContentLength < 0 {
+		return nil, &HostError{
+			Host: r.Request.Host,
+			Err:  Error{http.StatusLengthRequired, "request content length is negative"},
+		}
+	}
+
+	p := pool.Get(int(r.ContentLength))
+	defer pool.Put(p)
+
+	buf := (*p)[:r.ContentLength]
+	if _, err := io.ReadFull(r.Body, buf); err != nil {
+		return nil, hostError(r.Request.Host, err)
+	}
+
+	errs := make([]error, len(calls))
+	for i, call := range calls {
+		var (
+			e   Error
+			ok  bool
+			err error
+		)
+		if buf, e, ok, err = decodeError(buf); err != nil {
+			return nil, hostError(r.Request.Host, err)
+		}
+		if ok {
+			errs[i] = hostError(r.Request.Host, e)
+			continue
+		}
+		if buf, err = call.Decode(buf); err != nil {
+			return nil, hostError(r.Request.Host, err)
+		}
+	}
+	if len(buf) != 0 {
+		return nil, &HostError{
+			Host: r.Request.Host,
+			Err:  errors.New("kms: response body contains additional data"),
+		}
+	}
+	return errs, nil
+}
@@ -0,0 +1,210 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package kms_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minio/kms-go/kms"
+	"github.com/minio/kms-go/kms/kmstest"
+)
+
+func TestBatcher(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	const Enclave = kmstest.DefaultEnclave
+	ctx := context.Background()
+	client := srv.Client()
+	if err := client.CreateKey(ctx, Enclave, &kms.CreateKeyRequest{Name: "my-key"}); err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+
+	batcher := kms.NewBatcher(client, &kms.BatcherConfig{
+		Window:  10 * time.Millisecond,
+		MaxSize: 8,
+	})
+
+	const N = 20
+	var wg sync.WaitGroup
+	errs := make([]error, N)
+	for i := 0; i < N; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			plaintext := []byte("message-" + strconv.Itoa(i))
+			enc, err := batcher.Encrypt(ctx, Enclave, &kms.EncryptRequest{Name: "my-key", Plaintext: plaintext})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			if i%5 == 0 { // Corrupt some ciphertexts
+				enc.Ciphertext[0] ^= 1
+			}
+
+			dec, err := batcher.Decrypt(ctx, Enclave, &kms.DecryptRequest{Name: "my-key", Version: enc.Version, Ciphertext: enc.Ciphertext})
+			if i%5 == 0 {
+				if !errors.Is(err, kms.ErrDecrypt) {
+					errs[i] = fmt.Errorf("decrypting a corrupted ciphertext should have failed: got '%v' - want '%v'", err, kms.ErrDecrypt)
+				}
+				return
+			}
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			if !bytes.Equal(dec.Plaintext, plaintext) {
+				errs[i] = errors.New("plaintext mismatch")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Request %d: %v", i, err)
+		}
+	}
+}
+
+func TestBatcher_Timeout(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	// hang blocks all requests until they are canceled,
+	// like an unresponsive KMS server.
+	hang := func(ctx context.Context, _ *kms.Call, _ kms.Invoker) (*http.Response, error) {
+		<-ctx.Done()
+		return nil, &kms.HostError{Host: srv.Host(), Err: ctx.Err()}
+	}
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(srv.Certificate())
+	client, err := kms.NewClient(&kms.Config{
+		Endpoints:    []string{srv.Host()},
+		APIKey:       srv.APIKey,
+		TLS:          &tls.Config{RootCAs: rootCAs},
+		Interceptors: []kms.Interceptor{hang},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	batcher := kms.NewBatcher(client, &kms.BatcherConfig{
+		Timeout: 50 * time.Millisecond,
+	})
+	_, err = batcher.Encrypt(context.Background(), kmstest.DefaultEnclave, &kms.EncryptRequest{Name: "my-key", Plaintext: []byte("Hello World")})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Encrypting with an unresponsive server should have failed: got '%v' - want '%v'", err, context.DeadlineExceeded)
+	}
+}
+
+func TestBatcher_NoPartialResults(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	const (
+		Enclave = kmstest.DefaultEnclave
+		N       = 4
+	)
+	ctx := context.Background()
+	if err := srv.Client().CreateKey(ctx, Enclave, &kms.CreateKeyRequest{Name: "my-key"}); err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+
+	// noPartial simulates a KMS server that does not support
+	// partial results and fails a batch as a whole.
+	noPartial := func(ctx context.Context, call *kms.Call, next kms.Invoker) (*http.Response, error) {
+		call.Request.Partial = false
+		return next(ctx, call)
+	}
+	// denyBatch simulates a KMS server that rejects requests
+	// with multiple commands.
+	denyBatch := func(ctx context.Context, call *kms.Call, next kms.Invoker) (*http.Response, error) {
+		if len(call.Commands) > 1 {
+			return nil, &kms.HostError{Host: srv.Host(), Err: kms.ErrPermission}
+		}
+		return next(ctx, call)
+	}
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(srv.Certificate())
+	for i, test := range []struct {
+		Interceptor kms.Interceptor
+		Requests    int     // Number of requests sent to the server
+		Errs        []error // Error of the i-th call, if any
+	}{
+		{Interceptor: noPartial, Requests: 1 + N, Errs: []error{kms.ErrKeyNotFound, nil, nil, nil}},                                      // 0
+		{Interceptor: denyBatch, Requests: 1, Errs: []error{kms.ErrPermission, kms.ErrPermission, kms.ErrPermission, kms.ErrPermission}}, // 1
+	} {
+		var (
+			mu       sync.Mutex
+			requests int
+		)
+		count := func(ctx context.Context, call *kms.Call, next kms.Invoker) (*http.Response, error) {
+			mu.Lock()
+			requests++
+			mu.Unlock()
+			return next(ctx, call)
+		}
+
+		client, err := kms.NewClient(&kms.Config{
+			Endpoints:    []string{srv.Host()},
+			APIKey:       srv.APIKey,
+			TLS:          &tls.Config{RootCAs: rootCAs},
+			Interceptors: []kms.Interceptor{count, test.Interceptor},
+		})
+		if err != nil {
+			t.Fatalf("Test %d: failed to create client: %v", i, err)
+		}
+		batcher := kms.NewBatcher(client, &kms.BatcherConfig{
+			Window:  time.Second,
+			MaxSize: N,
+		})
+
+		var wg sync.WaitGroup
+		errs := make([]error, N)
+		for j := 0; j < N; j++ {
+			wg.Add(1)
+			go func(j int) {
+				defer wg.Done()
+
+				name := "my-key"
+				if j == 0 {
+					name = "unknown-key"
+				}
+				_, errs[j] = batcher.Encrypt(ctx, Enclave, &kms.EncryptRequest{Name: name, Plaintext: []byte("Hello World")})
+			}(j)
+		}
+		wg.Wait()
+		client.Close()
+
+		for j, err := range errs {
+			if !errors.Is(err, test.Errs[j]) {
+				t.Fatalf("Test %d: call %d: got '%v' - want '%v'", i, j, err, test.Errs[j])
+			}
+		}
+		if requests != test.Requests {
+			t.Fatalf("Test %d: got %d requests - want %d", i, requests, test.Requests)
+		}
+	}
+}
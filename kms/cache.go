@@ -0,0 +1,322 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package kms
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"aead.dev/mem"
+)
+
+// DefaultKeyCacheSize is the default size limit of a KeyCache.
+const DefaultKeyCacheSize = 1 * mem.MiB
+
+// KeyCacheConfig is a structure containing configuration
+// options for a KeyCache.
+type KeyCacheConfig struct {
+	// TTL is the amount of time a plaintext data encryption
+	// key stays in the cache. Once it expires, the next
+	// Decrypt call fetches the plaintext from the KMS
+	// again. If zero, cache entries do not expire.
+	TTL time.Duration
+
+	// MaxUses is the number of times a plaintext data
+	// encryption key is handed out before it gets evicted
+	// from the cache. If zero, the number of uses is not
+	// limited.
+	//
+	// The GenerateKey or Decrypt call that fetches the
+	// plaintext from the KMS counts as the first use.
+	// Hence, a plaintext is served from the cache at most
+	// MaxUses-1 times and a MaxUses of 1 disables caching.
+	MaxUses int
+
+	// MaxSize is the total size of all plaintext data
+	// encryption keys in the cache. Once reached, the
+	// least recently used keys are evicted. If zero,
+	// DefaultKeyCacheSize is used.
+	MaxSize mem.Size
+}
+
+// KeyCacheStats contains statistics about a KeyCache.
+type KeyCacheStats struct {
+	Hits      uint64   // Number of Decrypt requests served from the cache
+	Misses    uint64   // Number of Decrypt requests sent to the KMS
+	Evictions uint64   // Number of evicted cache entries
+	Entries   int      // Number of entries currently in the cache
+	Size      mem.Size // Total size of all entries currently in the cache
+}
+
+// KeyCache is a client-side cache for plaintext data encryption
+// keys. It wraps a Client and serves repeated Decrypt requests
+// for the same data encryption key from memory.
+//
+// Entries are identified by the enclave, key name, key version
+// and the SHA-256 hashes of the ciphertext and the associated
+// data. Plaintext keys returned by GenerateKey are cached as
+// well, such that decrypting a freshly generated key does not
+// require a round trip to the KMS.
+//
+// Evicted plaintext keys are overwritten with zeros. However,
+// the cache always returns copies. Callers are responsible for
+// clearing the plaintexts they receive.
+//
+// A KeyCache is safe for concurrent use by multiple goroutines.
+type KeyCache struct {
+	client  *Client
+	ttl     time.Duration
+	maxUses int
+	maxSize mem.Size
+
+	mu      sync.Mutex
+	entries map[keyCacheKey]*list.Element
+	lru     *list.List // Front is most recently used
+	size    mem.Size
+	stats   KeyCacheStats
+}
+
+// NewKeyCache returns a new KeyCache that fetches plaintext
+// keys using the given client. If conf is nil, the default
+// configuration is used.
+func NewKeyCache(client *Client, conf *KeyCacheConfig) *KeyCache {
+	if conf == nil {
+		conf = &KeyCacheConfig{}
+	}
+	maxSize := conf.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultKeyCacheSize
+	}
+	return &KeyCache{
+		client:  client,
+		ttl:     conf.TTL,
+		maxUses: conf.MaxUses,
+		maxSize: maxSize,
+		entries: map[keyCacheKey]*list.Element{},
+		lru:     list.New(),
+	}
+}
+
+// GenerateKey generates new data encryption keys, like
+// Client.GenerateKey, and adds the plaintext keys to the
+// cache. It never returns a cached key.
+func (c *KeyCache) GenerateKey(ctx context.Context, enclave string, reqs ...*GenerateKeyRequest) ([]*GenerateKeyResponse, error) {
+	resps, err := c.client.GenerateKey(ctx, enclave, reqs...)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, resp := range resps {
+		if i >= len(reqs) {
+			break
+		}
+		key := newKeyCacheKey(enclave, reqs[i].Name, resp.Version, resp.Ciphertext, reqs[i].AssociatedData)
+		c.add(key, resp.Plaintext, now)
+	}
+	return resps, nil
+}
+
+// Decrypt decrypts the ciphertexts, like Client.Decrypt. Requests
+// for cached keys are served from the cache. All other requests
+// are sent to the KMS in a single call and their plaintexts are
+// added to the cache.
+//
+// Requests without an explicit key version always refer to the
+// latest key version and are never served from the cache.
+func (c *KeyCache) Decrypt(ctx context.Context, enclave string, reqs ...*DecryptRequest) ([]*DecryptResponse, error) {
+	if len(reqs) == 0 {
+		return []*DecryptResponse{}, nil
+	}
+
+	var (
+		resps  = make([]*DecryptResponse, len(reqs))
+		keys   = make([]keyCacheKey, len(reqs))
+		misses = make([]int, 0, len(reqs))
+		now    = time.Now()
+	)
+
+	c.mu.Lock()
+	for i, req := range reqs {
+		if req.Version <= 0 {
+			misses = append(misses, i)
+			continue
+		}
+
+		keys[i] = newKeyCacheKey(enclave, req.Name, req.Version, req.Ciphertext, req.AssociatedData)
+		if plaintext, ok := c.get(keys[i], now); ok {
+			resps[i] = &DecryptResponse{Plaintext: plaintext}
+			c.stats.Hits++
+		} else {
+			misses = append(misses, i)
+		}
+	}
+	c.stats.Misses += uint64(len(misses))
+	c.mu.Unlock()
+
+	if len(misses) == 0 {
+		return resps, nil
+	}
+
+	missReqs := make([]*DecryptRequest, 0, len(misses))
+	for _, i := range misses {
+		missReqs = append(missReqs, reqs[i])
+	}
+	missResps, err := c.client.Decrypt(ctx, enclave, missReqs...)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for j, resp := range missResps {
+		if j >= len(misses) {
+			break
+		}
+		i := misses[j]
+		resps[i] = resp
+		if reqs[i].Version > 0 {
+			c.add(keys[i], resp.Plaintext, now)
+		}
+	}
+	return resps, nil
+}
+
+// Invalidate removes all cached plaintext keys of the
+// key ring with the given name within the enclave. It
+// should be called once the key has been deleted.
+func (c *KeyCache) Invalidate(enclave, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, elem := range c.entries {
+		if key.Enclave == enclave && key.Name == name {
+			c.evict(elem)
+		}
+	}
+}
+
+// Purge removes all entries from the cache.
+func (c *KeyCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, elem := range c.entries {
+		c.evict(elem)
+	}
+}
+
+// Stats returns statistics about the cache.
+func (c *KeyCache) Stats() KeyCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = len(c.entries)
+	stats.Size = c.size
+	return stats
+}
+
+// get returns a copy of the plaintext for the given key,
+// if present. It evicts expired and used up entries. It
+// must only be called when c.mu is locked.
+func (c *KeyCache) get(key keyCacheKey, now time.Time) ([]byte, bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*keyCacheEntry)
+	if !entry.ExpiresAt.IsZero() && !now.Before(entry.ExpiresAt) {
+		c.evict(elem)
+		return nil, false
+	}
+
+	plaintext := make([]byte, len(entry.Plaintext))
+	copy(plaintext, entry.Plaintext)
+
+	entry.Uses++
+	if c.maxUses > 0 && entry.Uses >= c.maxUses {
+		c.evict(elem)
+	} else {
+		c.lru.MoveToFront(elem)
+	}
+	return plaintext, true
+}
+
+// add adds a copy of the plaintext to the cache. It
+// evicts the least recently used entries when the cache
+// is full. It must only be called when c.mu is locked.
+func (c *KeyCache) add(key keyCacheKey, plaintext []byte, now time.Time) {
+	size := mem.Size(len(plaintext))
+	if size > c.maxSize || (c.maxUses > 0 && c.maxUses <= 1) {
+		return
+	}
+	if elem, ok := c.entries[key]; ok {
+		c.evict(elem)
+	}
+	for c.size+size > c.maxSize {
+		c.evict(c.lru.Back())
+	}
+
+	entry := &keyCacheEntry{
+		Key:       key,
+		Plaintext: make([]byte, len(plaintext)),
+		Uses:      1, // The plaintext has been handed out by the fetching call
+	}
+	copy(entry.Plaintext, plaintext)
+	if c.ttl > 0 {
+		entry.ExpiresAt = now.Add(c.ttl)
+	}
+
+	c.entries[key] = c.lru.PushFront(entry)
+	c.size += size
+}
+
+// evict removes the entry from the cache and overwrites
+// its plaintext with zeros. It must only be called when
+// c.mu is locked.
+func (c *KeyCache) evict(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*keyCacheEntry)
+	delete(c.entries, entry.Key)
+
+	c.size -= mem.Size(len(entry.Plaintext))
+	clear(entry.Plaintext)
+	c.stats.Evictions++
+}
+
+// keyCacheKey identifies a plaintext key within a KeyCache.
+type keyCacheKey struct {
+	Enclave        string
+	Name           string
+	Version        int
+	Ciphertext     [sha256.Size]byte
+	AssociatedData [sha256.Size]byte
+}
+
+func newKeyCacheKey(enclave, name string, version int, ciphertext, associatedData []byte) keyCacheKey {
+	return keyCacheKey{
+		Enclave:        enclave,
+		Name:           name,
+		Version:        version,
+		Ciphertext:     sha256.Sum256(ciphertext),
+		AssociatedData: sha256.Sum256(associatedData),
+	}
+}
+
+// keyCacheEntry is a plaintext key within a KeyCache.
+type keyCacheEntry struct {
+	Key       keyCacheKey
+	Plaintext []byte
+	ExpiresAt time.Time
+	Uses      int
+}
@@ -0,0 +1,120 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package kms_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/minio/kms-go/kms"
+	"github.com/minio/kms-go/kms/kmstest"
+)
+
+func TestKeyCache(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	const Enclave = kmstest.DefaultEnclave
+	ctx := context.Background()
+	client := srv.Client()
+	if err := client.CreateKey(ctx, Enclave, &kms.CreateKeyRequest{Name: "my-key"}); err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+
+	cache := kms.NewKeyCache(client, &kms.KeyCacheConfig{MaxUses: 3})
+	dek, err := cache.GenerateKey(ctx, Enclave, &kms.GenerateKeyRequest{Name: "my-key", AssociatedData: []byte("ad")})
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	req := &kms.DecryptRequest{
+		Name:           "my-key",
+		Version:        dek[0].Version,
+		Ciphertext:     dek[0].Ciphertext,
+		AssociatedData: []byte("ad"),
+	}
+	for i := 0; i < 3; i++ {
+		resp, err := cache.Decrypt(ctx, Enclave, req)
+		if err != nil {
+			t.Fatalf("Failed to decrypt key: %v", err)
+		}
+		if !bytes.Equal(resp[0].Plaintext, dek[0].Plaintext) {
+			t.Fatalf("Plaintext mismatch: got '%x' - want '%x'", resp[0].Plaintext, dek[0].Plaintext)
+		}
+	}
+	if stats := cache.Stats(); stats.Hits != 2 || stats.Misses != 1 || stats.Entries != 1 {
+		t.Fatalf("Stats mismatch: got %+v - want 2 hits, 1 miss and 1 entry", stats)
+	}
+
+	_, err = cache.Decrypt(ctx, Enclave, &kms.DecryptRequest{
+		Name:           req.Name,
+		Version:        req.Version,
+		Ciphertext:     req.Ciphertext,
+		AssociatedData: []byte("invalid"),
+	})
+	if !errors.Is(err, kms.ErrDecrypt) {
+		t.Fatalf("Decrypting with invalid associated data should have failed: got '%v' - want '%v'", err, kms.ErrDecrypt)
+	}
+
+	cache.Invalidate(Enclave, "my-key")
+	if stats := cache.Stats(); stats.Entries != 0 || stats.Size != 0 {
+		t.Fatalf("Stats mismatch: got %+v - want an empty cache", stats)
+	}
+}
+
+func TestKeyCache_Evict(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	const Enclave = kmstest.DefaultEnclave
+	ctx := context.Background()
+	client := srv.Client()
+	if err := client.CreateKey(ctx, Enclave, &kms.CreateKeyRequest{Name: "my-key"}); err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+
+	cache := kms.NewKeyCache(client, &kms.KeyCacheConfig{
+		TTL:     time.Hour,
+		MaxSize: 64,
+	})
+	for i := 0; i < 3; i++ {
+		if _, err := cache.GenerateKey(ctx, Enclave, &kms.GenerateKeyRequest{Name: "my-key"}); err != nil {
+			t.Fatalf("Failed to generate key: %v", err)
+		}
+	}
+	if stats := cache.Stats(); stats.Entries != 2 || stats.Size != 64 || stats.Evictions != 1 {
+		t.Fatalf("Stats mismatch: got %+v - want 2 entries of 64 bytes and 1 eviction", stats)
+	}
+
+	cache = kms.NewKeyCache(client, &kms.KeyCacheConfig{TTL: time.Nanosecond})
+	dek, err := cache.GenerateKey(ctx, Enclave, &kms.GenerateKeyRequest{Name: "my-key"})
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	time.Sleep(time.Millisecond)
+	if _, err = cache.Decrypt(ctx, Enclave, &kms.DecryptRequest{Name: "my-key", Version: dek[0].Version, Ciphertext: dek[0].Ciphertext}); err != nil {
+		t.Fatalf("Failed to decrypt key: %v", err)
+	}
+	if stats := cache.Stats(); stats.Hits != 0 || stats.Misses != 1 {
+		t.Fatalf("Stats mismatch: got %+v - want 0 hits and 1 miss", stats)
+	}
+
+	// The fetching call counts as first use. Hence, a MaxUses
+	// of 1 disables caching.
+	cache = kms.NewKeyCache(client, &kms.KeyCacheConfig{MaxUses: 1})
+	if dek, err = cache.GenerateKey(ctx, Enclave, &kms.GenerateKeyRequest{Name: "my-key"}); err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	if stats := cache.Stats(); stats.Entries != 0 {
+		t.Fatalf("Stats mismatch: got %+v - want an empty cache", stats)
+	}
+}
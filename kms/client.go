@@ -12,6 +12,7 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"maps"
 	"net"
 	"net/http"
 	"net/url"
@@ -29,6 +30,7 @@ import (
 	"github.com/minio/kms-go/kms/internal/https"
 	"github.com/minio/kms-go/kms/internal/pool"
 	pb "github.com/minio/kms-go/kms/protobuf"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Config is a structure containing configuration
@@ -50,6 +52,147 @@ type Config struct {
 	// If no API key is set, either a TLS.Certificates
 	// or TLS.GetClientCertificate must be present.
 	TLS *tls.Config
+
+	// Interceptors is an optional list of interceptors
+	// that observe or modify all requests sent by the
+	// Client.Send method and its higher-level methods.
+	// The first interceptor is the outermost one.
+	Interceptors []Interceptor
+
+	// TracerProvider, if not nil, is used to record
+	// OpenTelemetry spans. The Client records a span
+	// for every request sent by Client.Send and its
+	// higher-level methods, and a child span for every
+	// attempt to send a request to a KMS server. It
+	// propagates the W3C trace context of each attempt
+	// to the KMS server.
+	TracerProvider trace.TracerProvider
+
+	// Metrics, if not nil, records metrics about all
+	// requests sent by the Client. The same Metrics
+	// may be shared by multiple Clients.
+	Metrics *Metrics
+
+	// LeaderRouting controls whether the Client sends
+	// requests containing only write commands, like
+	// creating a key, to the cluster leader directly.
+	// Other requests are sent to any KMS server.
+	//
+	// The Client learns the leader from the server
+	// status of its hosts and learns it again after
+	// errors and periodically to detect elections.
+	// If the leader is unknown or unreachable, write
+	// requests are sent to any KMS server, which
+	// forwards them to the leader.
+	LeaderRouting bool
+
+	// RefreshInterval, if greater than zero, controls how
+	// often the Client updates its list of KMS servers from
+	// the cluster status. Refer to Client.RefreshHosts.
+	// Such a Client should be closed once no longer used.
+	RefreshInterval time.Duration
+
+	// HealthCheckInterval, if greater than zero, controls
+	// how often the Client checks whether its KMS servers
+	// are ready to serve requests. Servers that are not
+	// ready are no longer selected for requests until they
+	// are ready again. Servers that are only ready for read
+	// requests are not selected for write requests. Refer
+	// to Client.Health.
+	// Such a Client should be closed once no longer used.
+	HealthCheckInterval time.Duration
+
+	// OnHostsChange, if not nil, is called with the Client's
+	// new list of KMS servers whenever it changes. It must
+	// not modify the Client's list of KMS servers.
+	OnHostsChange func(hosts []string)
+
+	// RetryPolicy, if not nil, controls how the Client
+	// retries requests that fail since a KMS server is
+	// unreachable or overloaded. Without a RetryPolicy,
+	// the Client retries such requests immediately on
+	// every other KMS server at most once.
+	RetryPolicy *RetryPolicy
+
+	// HostSelector, if not nil, selects the KMS server a
+	// request is sent to among all KMS servers that are not
+	// excluded. By default, the Client selects KMS servers
+	// randomly. Refer to RoundRobin, LeastLatency,
+	// LeastRequests and ZoneSelector.
+	HostSelector HostSelector
+
+	// CircuitBreaker, if not nil, controls when the Client
+	// stops sending requests to a KMS server that fails and
+	// when it starts sending requests to it again. Without
+	// a CircuitBreaker, the Client excludes a KMS server
+	// for 30 seconds once a request to it fails.
+	CircuitBreaker *CircuitBreaker
+}
+
+// RetryPolicy controls how a Client retries failed requests.
+//
+// Requests are retried with other KMS servers, if available,
+// when they fail with a network error, time out or when the
+// KMS server responds with 429 Too Many Requests or 503
+// Service Unavailable. A KMS server may ask the Client to
+// retry after some time using the Retry-After header.
+type RetryPolicy struct {
+	// MaxAttempts is the max. number of attempts to send
+	// a request, including the first one. If 0, every KMS
+	// server is tried at most once.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry. It
+	// doubles with every further retry. A random jitter
+	// of up to half the delay is subtracted. If 0, requests
+	// are retried immediately.
+	BaseDelay time.Duration
+
+	// MaxDelay limits the delay between two attempts. If
+	// a KMS server asks to retry after more than MaxDelay,
+	// the request is not retried. If 0, defaults to 10
+	// seconds.
+	MaxDelay time.Duration
+
+	// AttemptTimeout, if greater than 0, limits how long a
+	// single attempt may take until the response headers
+	// are received. Attempts that time out are retried.
+	AttemptTimeout time.Duration
+
+	// Hedge controls whether read-only requests, like
+	// decrypting a ciphertext, are sent to a second KMS
+	// server if no response has been received within the
+	// 95th percentile of recent read-only latencies. The
+	// first response is used and the other attempt is
+	// canceled.
+	Hedge bool
+}
+
+// CircuitBreaker controls when a Client excludes KMS servers.
+//
+// Each KMS server has a circuit breaker that is closed while
+// requests succeed. Once requests to a KMS server have failed
+// FailureThreshold times in a row, for example with a network
+// error, the breaker opens and the KMS server is excluded for
+// OpenTimeout. Afterwards, the breaker is half-open and the
+// KMS server is selected again. While half-open, a single
+// failed request opens the breaker again while SuccessThreshold
+// successful requests close it.
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failed
+	// requests after which the breaker opens. If 0, defaults
+	// to 1.
+	FailureThreshold int
+
+	// SuccessThreshold is the number of successful requests
+	// after which a half-open breaker closes. If 0, defaults
+	// to 1.
+	SuccessThreshold int
+
+	// OpenTimeout is the amount of time a KMS server is
+	// excluded once its breaker opens. If 0, defaults to
+	// 30 seconds.
+	OpenTimeout time.Duration
 }
 
 // NewClient returns a new Client with the given configuration.
@@ -85,19 +228,12 @@ func NewClient(conf *Config) (*Client, error) {
 		}
 	}
 
-	hosts := make([]string, 0, len(conf.Endpoints))
-	for _, endpoint := range conf.Endpoints {
-		endpoint = strings.TrimSpace(endpoint)
-		endpoint = strings.TrimPrefix(endpoint, "http://")
-		endpoint = strings.TrimPrefix(endpoint, "https://")
-		hosts = append(hosts, endpoint)
-	}
+	hosts := trimEndpoints(conf.Endpoints)
 	if len(hosts) == 0 {
 		hosts = []string{"127.0.0.1:7373"}
 	}
 
 	lb := &https.LoadBalancer{
-		Hosts: hosts,
 		RoundTripper: &http.Transport{
 			Proxy: http.ProxyFromEnvironment,
 			DialContext: (&net.Dialer{
@@ -113,11 +249,72 @@ func NewClient(conf *Config) (*Client, error) {
 			TLSClientConfig:       tlsConf,
 		},
 	}
-	return &Client{
+	lb.SetHosts(hosts)
+	lb.OnChange = conf.OnHostsChange
+	if conf.HostSelector != nil {
+		lb.Selector = conf.HostSelector
+	}
+	if b := conf.CircuitBreaker; b != nil {
+		lb.Timeout = b.OpenTimeout
+		lb.Breaker = &https.CircuitBreaker{
+			FailureThreshold: b.FailureThreshold,
+			SuccessThreshold: b.SuccessThreshold,
+		}
+	}
+	if p := conf.RetryPolicy; p != nil {
+		lb.Policy = &https.RetryPolicy{
+			MaxAttempts:    p.MaxAttempts,
+			BaseDelay:      p.BaseDelay,
+			MaxDelay:       p.MaxDelay,
+			AttemptTimeout: p.AttemptTimeout,
+			Hedge:          p.Hedge,
+		}
+	}
+
+	c := &Client{
 		direct: http.Client{Transport: lb.RoundTripper},
 		client: http.Client{Transport: lb},
 		lb:     lb,
-	}, nil
+		stop:   func() {},
+	}
+
+	if conf.LeaderRouting {
+		c.leader = &leaderRouter{client: c}
+	}
+
+	var interceptors []Interceptor
+	if conf.TracerProvider != nil {
+		tracer := conf.TracerProvider.Tracer(tracerName)
+
+		lb.Tracer = tracer
+		c.direct.Transport = &https.TracingTransport{RoundTripper: lb.RoundTripper, Tracer: tracer}
+		interceptors = append(interceptors, traceInterceptor(tracer))
+	}
+	if conf.Metrics != nil {
+		conf.Metrics.register(lb)
+		c.stats = conf.Metrics
+		interceptors = append(interceptors, conf.Metrics.interceptor())
+	}
+	interceptors = append(interceptors, conf.Interceptors...)
+	if len(interceptors) > 0 {
+		c.invoke = chainInterceptors(interceptors, c.send)
+	}
+
+	if conf.HealthCheckInterval > 0 {
+		c.health = &healthChecker{client: c}
+	}
+	if conf.RefreshInterval > 0 || conf.HealthCheckInterval > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		c.stop = cancel
+
+		if conf.RefreshInterval > 0 {
+			go c.refreshHosts(ctx, conf.RefreshInterval)
+		}
+		if conf.HealthCheckInterval > 0 {
+			go c.health.Run(ctx, conf.HealthCheckInterval)
+		}
+	}
+	return c, nil
 }
 
 // Client is a KMS client. It performs client-side load balancing
@@ -133,10 +330,111 @@ type Client struct {
 
 	client http.Client // Client that uses the LB as RoundTripper
 	lb     *https.LoadBalancer
+
+	invoke Invoker        // Interceptor chain, if any, calling send
+	leader *leaderRouter  // Routes writes to the leader, if enabled
+	health *healthChecker // Checks the readiness of all hosts, if enabled
+	stats  *Metrics       // Exports the excluded hosts, if enabled
+
+	stop context.CancelFunc // Stops the host refresher and health checks, if any
 }
 
 // Hosts returns a list of KMS servers currently used by client.
-func (c *Client) Hosts() []string { return slices.Clone(c.lb.Hosts) }
+func (c *Client) Hosts() []string { return c.lb.Hosts() }
+
+// SetHosts replaces the list of KMS servers used by the client.
+// Hosts should be of the form 'host' or 'host:port'. It returns
+// an error if hosts is empty.
+//
+// SetHosts is safe for concurrent use while the client sends
+// requests. Requests in flight are not affected.
+func (c *Client) SetHosts(hosts ...string) error {
+	hosts = trimEndpoints(hosts)
+	if len(hosts) == 0 {
+		return errors.New("kms: no hosts provided")
+	}
+	c.lb.SetHosts(hosts)
+	return nil
+}
+
+// RefreshHosts updates the list of KMS servers used by the client
+// from the cluster status. The new list contains all nodes within
+// the cluster, including nodes that are currently down, ordered by
+// their node IDs. It requires SysAdmin privileges.
+//
+// The returned error is of type *HostError.
+func (c *Client) RefreshHosts(ctx context.Context) error {
+	status, err := c.ClusterStatus(ctx, &ClusterStatusRequest{})
+	if err != nil {
+		return err
+	}
+
+	nodes := make(map[int]string, len(status.NodesUp)+len(status.NodesDown))
+	for id, s := range status.NodesUp {
+		nodes[id] = s.Host
+	}
+	for id, addr := range status.NodesDown {
+		nodes[id] = addr
+	}
+
+	hosts := make([]string, 0, len(nodes))
+	for _, id := range slices.Sorted(maps.Keys(nodes)) {
+		hosts = append(hosts, nodes[id])
+	}
+	if hosts = trimEndpoints(hosts); len(hosts) > 0 {
+		c.lb.SetHosts(hosts)
+	}
+	return nil
+}
+
+// Close stops updating the client's list of KMS servers and
+// checking their health periodically, if enabled, and closes
+// idle connections. Its Config.Metrics, if any, no longer
+// exports the client's excluded KMS servers.
+// The client remains usable.
+func (c *Client) Close() error {
+	c.stop()
+	if c.stats != nil {
+		c.stats.unregister(c.lb)
+	}
+	c.direct.CloseIdleConnections()
+	return nil
+}
+
+// Health returns the health of the client's KMS servers as
+// determined by the most recent health checks. It returns
+// nil if health checks are not enabled. Refer to
+// Config.HealthCheckInterval.
+//
+// KMS servers that have not been checked yet are omitted.
+func (c *Client) Health() []HostHealth {
+	if c.health == nil {
+		return nil
+	}
+	return c.health.Health()
+}
+
+// HostStats returns statistics about the requests sent to
+// each of the client's KMS servers and the state of their
+// circuit breakers. It can be used to find out why the
+// client avoids a KMS server. Refer to Config.CircuitBreaker.
+func (c *Client) HostStats() []HostStats { return c.lb.Stats() }
+
+// refreshHosts updates the client's list of KMS servers
+// every interval until ctx is done.
+func (c *Client) refreshHosts(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.RefreshHosts(ctx) // Keep the current hosts on error
+		}
+	}
+}
 
 // Send executes a KMS request, returning a Response for the provided
 // Request.
@@ -147,11 +445,57 @@ func (c *Client) Hosts() []string { return slices.Clone(c.lb.Hosts) }
 // communicate with one particular KMS server, req.Host should be set
 // to the server host or host:port.
 //
+// If the Client has been configured with Interceptors, Send passes
+// the request through them before sending it.
+//
 // Send is a low-level API. Most callers should use higher-level
 // functionality, like creating a key using CreateKey.
 //
 // The returned error is of type *HostError.
 func (c *Client) Send(ctx context.Context, req *Request) (*http.Response, error) {
+	call := &Call{
+		Request:  req,
+		Commands: decodeCommands(req.Body),
+	}
+	if c.invoke == nil {
+		return c.send(ctx, call)
+	}
+
+	call.Header = http.Header{}
+	return c.invoke(ctx, call)
+}
+
+// send sends the call's request to a KMS server and sets
+// the call's host, status code and latency.
+//
+// With leader routing, write requests are sent to the cluster
+// leader, if known. If the leader is not reachable, send falls
+// back to any KMS server.
+func (c *Client) send(ctx context.Context, call *Call) (*http.Response, error) {
+	if c.leader == nil || call.Request.Host != "" || !isWriteOnly(call.Commands) {
+		return c.sendTo(ctx, call, call.Request.Host)
+	}
+
+	leader := c.leader.Host(ctx)
+	if leader == "" {
+		return c.sendTo(ctx, call, "")
+	}
+	resp, err := c.sendTo(ctx, call, leader)
+	if err == nil || !isLeaderError(err) {
+		return resp, err
+	}
+
+	c.leader.Reset(leader)
+	if !https.NotSent(err) {
+		return nil, err // The leader may have received the request
+	}
+	return c.sendTo(ctx, call, "")
+}
+
+// sendTo sends the call's request to host. If host is empty,
+// the Client selects the KMS server and retries the request
+// on other KMS servers, if available.
+func (c *Client) sendTo(ctx context.Context, call *Call, host string) (*http.Response, error) {
 	const (
 		Method   = http.MethodPost
 		Path     = "/v1/kms/"
@@ -161,9 +505,10 @@ func (c *Client) Send(ctx context.Context, req *Request) (*http.Response, error)
 	var (
 		err    error
 		reqURL string
-		host   = req.Host
+		req    = call.Request
+		direct = host != ""
 	)
-	if host == "" {
+	if !direct {
 		reqURL, host, err = c.lb.URL(Path, req.Enclave)
 	} else {
 		reqURL, err = url.JoinPath(httpsURL(host), Path, req.Enclave)
@@ -171,24 +516,50 @@ func (c *Client) Send(ctx context.Context, req *Request) (*http.Response, error)
 	if err != nil {
 		return nil, hostError(host, err)
 	}
+	if req.Partial {
+		reqURL += "?" + api.QueryPartial
+	}
 
+	if isReadOnly(call.Commands) {
+		ctx = https.WithReadOnly(ctx)
+	}
 	r, err := http.NewRequestWithContext(ctx, Method, reqURL, bytes.NewReader(req.Body))
 	if err != nil {
 		return nil, hostError(host, err)
 	}
 	r.ContentLength = int64(len(req.Body))
+	for key, values := range call.Header {
+		r.Header[key] = values
+	}
 	r.Header.Add(headers.Accept, headers.ContentTypeAppAny) // accept binary and json
 	r.Header.Set(headers.ContentType, headers.ContentTypeBinary)
 
-	var resp *http.Response
-	if req.Host == "" {
-		resp, err = c.client.Do(r) // Without req.Host, use the client LB.
+	var (
+		resp  *http.Response
+		start = time.Now()
+	)
+	if !direct {
+		resp, err = c.client.Do(r) // Without a host, use the client LB.
 	} else {
-		resp, err = c.direct.Do(r) // With an explicit req.Host, don't use client LB.
-	}
-	if err != nil {
+		resp, err = c.direct.Do(r) // With an explicit host, don't use client LB.
+	}
+	call.Latency += time.Since(start)
+	call.Host = r.URL.Host // The LB may have retried the request with another host
+	if err != nil {
+		if slices.ContainsFunc(call.Commands, cmds.Command.IsWrite) && !https.NotSent(err) {
+			failed := *req
+			failed.Body = slices.Clone(req.Body) // The body may be reused once Send returns
+			err = &OutcomeUnknownError{
+				Request:  &failed,
+				Commands: call.Commands,
+				Sent:     start,
+				Err:      err,
+			}
+		}
 		return nil, hostError(host, err)
 	}
+	call.StatusCode = resp.StatusCode
+
 	if resp.StatusCode != StatusOK {
 		defer resp.Body.Close()
 
@@ -245,7 +616,7 @@ func (c *Client) Version(ctx context.Context, req *VersionRequest) ([]*VersionRe
 
 	endpoints := req.Hosts
 	if len(endpoints) == 0 {
-		endpoints = c.lb.Hosts
+		endpoints = c.lb.Hosts()
 	}
 	if len(endpoints) == 1 {
 		resp, err := version(ctx, endpoints[0])
@@ -279,6 +650,151 @@ func (c *Client) Version(ctx context.Context, req *VersionRequest) ([]*VersionRe
 	return responses[:len(responses):len(responses)], errors.Join(errs...)
 }
 
+// ServerStatus returns status information from one or multiple KMS
+// servers. If req.Hosts is empty, the Client tries to fetch status
+// information from all its hosts.
+//
+// For a single host, ServerStatus returns its status information and
+// a HostError wrapping the first error encountered, if any.
+//
+// For multiple hosts, ServerStatus returns a list of status responses.
+// If it fails to fetch status information from some hosts, it returns
+// a joined error that implements the "Unwrap() []error" interface. Each
+// of these errors are of type HostError.
+func (c *Client) ServerStatus(ctx context.Context, req *ServerStatusRequest) ([]*ServerStatusResponse, error) {
+	const (
+		Method      = http.MethodGet
+		Path        = api.PathHealthStatus
+		StatusOK    = http.StatusOK
+		ContentType = headers.ContentTypeAppAny // accept JSON or protobuf
+	)
+	status := func(ctx context.Context, endpoint string) (*ServerStatusResponse, error) {
+		url, err := url.JoinPath(httpsURL(endpoint), Path)
+		if err != nil {
+			return nil, hostError(endpoint, err)
+		}
+		r, err := http.NewRequestWithContext(ctx, Method, url, nil)
+		if err != nil {
+			return nil, hostError(endpoint, err)
+		}
+		r.Header.Set(headers.Accept, ContentType)
+
+		resp, err := c.direct.Do(r)
+		if err != nil {
+			return nil, hostError(endpoint, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != StatusOK {
+			return nil, hostError(endpoint, readError(resp))
+		}
+
+		var data ServerStatusResponse
+		if err := readResponse(resp, &data); err != nil {
+			return nil, hostError(endpoint, err)
+		}
+		return &data, nil
+	}
+
+	endpoints := req.Hosts
+	if len(endpoints) == 0 {
+		endpoints = c.lb.Hosts()
+	}
+	if len(endpoints) == 1 {
+		resp, err := status(ctx, endpoints[0])
+		if err != nil {
+			return []*ServerStatusResponse{}, err
+		}
+		return []*ServerStatusResponse{resp}, nil
+	}
+
+	resps := make([]*ServerStatusResponse, len(endpoints))
+	errs := make([]error, len(endpoints))
+
+	var wg sync.WaitGroup
+	for i := range endpoints {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+			resps[i], errs[i] = status(ctx, endpoints[i])
+		}(i)
+	}
+	wg.Wait()
+
+	// Compact responses by filtering all nil values without alloc.
+	responses := resps[:0]
+	for _, r := range resps {
+		if r != nil {
+			responses = append(responses, r)
+		}
+	}
+	return responses[:len(responses):len(responses)], errors.Join(errs...)
+}
+
+// APIs returns a list of all API endpoints supported by the KMS
+// server host, including the max. request body size and timeout
+// of each API. If host is empty, one of the client's hosts is used.
+//
+// The returned error is of type *HostError.
+func (c *Client) APIs(ctx context.Context, host string) ([]APIResponse, error) {
+	const (
+		Method   = http.MethodGet
+		Path     = api.PathHealthAPIs
+		StatusOK = http.StatusOK
+	)
+
+	var (
+		err      error
+		reqURL   string
+		endpoint = host
+	)
+	if endpoint == "" {
+		reqURL, endpoint, err = c.lb.URL(Path)
+	} else {
+		reqURL, err = url.JoinPath(httpsURL(endpoint), Path)
+	}
+	if err != nil {
+		return nil, hostError(endpoint, err)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, Method, reqURL, nil)
+	if err != nil {
+		return nil, hostError(endpoint, err)
+	}
+	r.Header.Set(headers.Accept, headers.ContentTypeAppAny) // accept JSON or protobuf
+
+	var resp *http.Response
+	if host == "" {
+		resp, err = c.client.Do(r) // Without a host, use the client LB.
+	} else {
+		resp, err = c.direct.Do(r) // With an explicit host, don't use client LB.
+	}
+	if err != nil {
+		return nil, hostError(endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != StatusOK {
+		return nil, hostError(endpoint, readError(resp))
+	}
+
+	var data pb.ListAPIsResponse
+	if err = readProtoResponse(resp, &data); err != nil {
+		return nil, hostError(endpoint, err)
+	}
+
+	apis := make([]APIResponse, 0, len(data.APIs))
+	for _, v := range data.APIs {
+		var a APIResponse
+		if err = a.UnmarshalPB(v); err != nil {
+			return nil, hostError(endpoint, err)
+		}
+		apis = append(apis, a)
+	}
+	return apis, nil
+}
+
 // Live reports whether one or multiple KMS servers are alive. If
 // req.Hosts is empty, the Client checks the liveness of all hosts.
 //
@@ -326,7 +842,7 @@ func (c *Client) Live(ctx context.Context, req *LivenessRequest) error {
 
 	endpoints := req.Hosts
 	if len(endpoints) == 0 {
-		endpoints = c.lb.Hosts
+		endpoints = c.lb.Hosts()
 	}
 	if len(endpoints) == 1 {
 		return live(ctx, endpoints[0])
@@ -401,7 +917,7 @@ func (c *Client) Ready(ctx context.Context, req *ReadinessRequest) error {
 
 	endpoints := req.Hosts
 	if len(endpoints) == 0 {
-		endpoints = c.lb.Hosts
+		endpoints = c.lb.Hosts()
 	}
 	if len(endpoints) == 1 {
 		return ready(ctx, endpoints[0])
@@ -649,7 +1165,7 @@ func (c *Client) AddNode(ctx context.Context, req *AddClusterNodeRequest) error
 	}
 	defer resp.Body.Close()
 
-	c.lb.Hosts = append(c.lb.Hosts, req.Host)
+	c.lb.AddHost(req.Host)
 	return nil
 }
 
@@ -673,13 +1189,7 @@ func (c *Client) RemoveNode(ctx context.Context, req *RemoveClusterNodeRequest)
 	}
 	defer resp.Body.Close()
 
-	hosts := make([]string, 0, len(c.lb.Hosts))
-	for _, host := range c.lb.Hosts {
-		if host != req.Host {
-			hosts = append(hosts, host)
-		}
-	}
-	c.lb.Hosts = hosts
+	c.lb.RemoveHost(req.Host)
 	return nil
 }
 
@@ -1036,6 +1546,9 @@ func (c *Client) ListEnclaves(ctx context.Context, req *ListRequest) (*Page[Encl
 // to an existing key.
 //
 // Adding new key versions is often also referred to as key rotation.
+// Before adding a key version, CreateKey fetches the latest version of
+// the key. If the outcome of the request is unknown, Reconcile uses it
+// to find out whether a new version has been added.
 //
 // It returns ErrEnclaveNotFound if no such enclave exists and ErrKeyExists
 // if such a key already exists, wrapped in a HostError.
@@ -1050,11 +1563,23 @@ func (c *Client) CreateKey(ctx context.Context, enclave string, req *CreateKeyRe
 		return err
 	}
 
+	// When adding a key version, remember the latest version, if
+	// possible. Hence, Reconcile can find out whether a new version
+	// has been added without relying on the client's clock.
+	var status []*KeyStatusResponse
+	if req.AddVersion {
+		status, _ = c.KeyStatus(ctx, enclave, &KeyStatusRequest{Name: req.Name})
+	}
+
 	resp, err := c.Send(ctx, &Request{
 		Enclave: enclave,
 		Body:    body,
 	})
 	if err != nil {
+		var e *OutcomeUnknownError
+		if len(status) == 1 && errors.As(err, &e) {
+			e.KeyVersions = map[string]int{req.Name: status[0].Version}
+		}
 		return err
 	}
 	return resp.Body.Close()
@@ -1124,6 +1649,77 @@ func (c *Client) KeyStatus(ctx context.Context, enclave string, reqs ...*KeyStat
 	return decodeResponse[pb.KeyStatusResponse, KeyStatusResponse](resp, cmds.KeyStatus)
 }
 
+// Reconcile reports, for every command of the request that failed
+// with the OutcomeUnknownError e, whether the KMS server has applied
+// it. It determines the current state of the keys using KeyStatus.
+//
+// Reconcile supports requests that create or delete keys:
+//   - Creating a key has been applied if the key exists.
+//   - Adding a key version has been applied if the latest key version
+//     is newer than the latest version before the request, as recorded
+//     in e.KeyVersions. If unknown, for example since the request has
+//     been sent with Send, it has been applied if the latest version
+//     has been created after the request has been sent. In this case,
+//     the clocks of the client and the KMS servers must be in sync.
+//   - Deleting a key version or all key versions has been applied if
+//     the key version or key no longer exists. Deleting the latest
+//     key version cannot be reconciled.
+//
+// For any other command, Reconcile returns an error.
+//
+// The returned error is of type *HostError.
+func (c *Client) Reconcile(ctx context.Context, e *OutcomeUnknownError) ([]bool, error) {
+	var (
+		body    = e.Request.Body
+		enclave = e.Request.Enclave
+		applied = make([]bool, 0, len(e.Commands))
+	)
+	for _, cmd := range e.Commands {
+		var err error
+		switch cmd {
+		case cmds.KeyCreate:
+			var req CreateKeyRequest
+			if body, err = cmds.Decode[pb.CreateKeyRequest](body, cmd, &req); err != nil {
+				return nil, hostError("", err)
+			}
+
+			resp, err := c.KeyStatus(ctx, enclave, &KeyStatusRequest{Name: req.Name})
+			if errors.Is(err, ErrKeyNotFound) {
+				applied = append(applied, false)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			switch version, ok := e.KeyVersions[req.Name]; {
+			case !req.AddVersion:
+				applied = append(applied, true)
+			case ok:
+				applied = append(applied, resp[0].Version > version)
+			default:
+				applied = append(applied, !resp[0].CreatedAt.Before(e.Sent))
+			}
+		case cmds.KeyDelete:
+			var req DeleteKeyRequest
+			if body, err = cmds.Decode[pb.DeleteKeyRequest](body, cmd, &req); err != nil {
+				return nil, hostError("", err)
+			}
+			if req.Version <= 0 && !req.AllVersions {
+				return nil, hostError("", errors.New("kms: cannot reconcile deleting the latest key version"))
+			}
+
+			_, err = c.KeyStatus(ctx, enclave, &KeyStatusRequest{Name: req.Name, Version: req.Version})
+			if err != nil && !errors.Is(err, ErrKeyNotFound) {
+				return nil, err
+			}
+			applied = append(applied, err != nil)
+		default:
+			return nil, hostError("", errors.New("kms: cannot reconcile command '"+cmd.String()+"'"))
+		}
+	}
+	return applied, nil
+}
+
 // DeleteKey deletes the key with the version req.Version from the key ring
 // with the name req.Name within the enclave. It deletes the latest key
 // version if no key version is specified and the entire key and all versions
@@ -1194,6 +1790,82 @@ func (c *Client) ListKeys(ctx context.Context, req *ListRequest) (*Page[KeyStatu
 	return ls, nil
 }
 
+// ListKeyVersions returns the next page of a paginated listing of
+// all versions of the key ring req.Name within the enclave, from the
+// oldest to the latest version. The first key version on the page is
+// req.ContinueAt, or the next newer version if req.ContinueAt has been
+// deleted. The page contains at most req.Limit key versions.
+//
+// The page's ContinueAt is the decimal representation of the next key
+// version to list, if any. For iterating over all key versions, use
+// an Iter returned by KeyVersions.
+//
+// It returns ErrEnclaveNotFound if no such enclave exists and
+// ErrKeyNotFound if no such key exists, wrapped in a HostError.
+//
+// The returned error is of type *HostError.
+func (c *Client) ListKeyVersions(ctx context.Context, enclave string, req *ListKeyVersionsRequest) (*Page[KeyStatusResponse], error) {
+	body, err := cmds.Encode(nil, cmds.KeyListVersions, req)
+	if err != nil {
+		return nil, hostError("", err)
+	}
+
+	resp, err := c.Send(ctx, &Request{
+		Enclave: enclave,
+		Body:    body,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var data pb.ListKeyVersionsResponse
+	if err := decodeResponseMessage(resp, cmds.KeyListVersions, &data); err != nil {
+		return nil, err
+	}
+
+	ls := &Page[KeyStatusResponse]{
+		Items: make([]KeyStatusResponse, 0, len(data.Versions)),
+	}
+	if data.ContinueAt > 0 {
+		ls.ContinueAt = strconv.FormatUint(uint64(data.ContinueAt), 10)
+	}
+	for _, e := range data.Versions {
+		var r KeyStatusResponse
+		if err = r.UnmarshalPB(e); err != nil {
+			return nil, hostError(resp.Request.URL.Host, err)
+		}
+		ls.Items = append(ls.Items, r)
+	}
+	return ls, nil
+}
+
+// KeyVersions returns an Iter over all versions of the key ring
+// with the given name within the enclave. It uses ListKeyVersions
+// to fetch the key versions page by page.
+//
+// The Iter's SeekTo method expects the ListRequest.ContinueAt to
+// be a decimal key version and ignores the ListRequest.Enclave
+// and Prefix.
+func (c *Client) KeyVersions(enclave, name string) *Iter[KeyStatusResponse] {
+	return &Iter[KeyStatusResponse]{
+		NextFn: func(ctx context.Context, req *ListRequest) (*Page[KeyStatusResponse], error) {
+			var continueAt int
+			if req.ContinueAt != "" {
+				var err error
+				if continueAt, err = strconv.Atoi(req.ContinueAt); err != nil {
+					return nil, hostError("", fmt.Errorf("kms: invalid key version '%s'", req.ContinueAt))
+				}
+			}
+			return c.ListKeyVersions(ctx, enclave, &ListKeyVersionsRequest{
+				Name:       name,
+				ContinueAt: continueAt,
+				Limit:      req.Limit,
+			})
+		},
+	}
+}
+
 // Encrypt encrypts the req.Plaintext with the key req.Name within
 // the req.Enclave.
 //
@@ -1357,9 +2029,126 @@ func (c *Client) MAC(ctx context.Context, enclave string, reqs ...*MACRequest) (
 	return decodeResponse[pb.MACResponse, MACResponse](resp, cmds.KeyMAC)
 }
 
+// EncryptBatch encrypts the plaintexts of all requests with the
+// respective keys within the given enclave, like Encrypt, using a
+// single request. It returns one Result per request, in the same
+// order. Each Result contains either the ciphertext or the error
+// of its request. For example, ErrKeyNotFound if no such key exists.
+//
+// Per-request results require a KMS server that supports partial
+// results. A server without such support executes the requests
+// until one fails and responds with its error. In this case,
+// EncryptBatch returns this error and no Results, even if only
+// a single plaintext could not be encrypted.
+//
+// It returns an error if the entire request fails. For example,
+// when the enclave does not exist or no KMS server is reachable.
+// The returned errors are of type *HostError.
+func (c *Client) EncryptBatch(ctx context.Context, enclave string, reqs ...*EncryptRequest) ([]Result[*EncryptResponse], error) {
+	return sendBatch[pb.EncryptRequest, pb.EncryptResponse, EncryptResponse](ctx, c, enclave, cmds.KeyEncrypt, reqs)
+}
+
+// DecryptBatch decrypts the ciphertexts of all requests with the
+// respective keys within the given enclave, like Decrypt, using a
+// single request. It returns one Result per request, in the same
+// order. Each Result contains either the plaintext or the error
+// of its request. For example, ErrDecrypt if a ciphertext is not
+// authentic.
+//
+// Per-request results require a KMS server that supports partial
+// results. A server without such support executes the requests
+// until one fails and responds with its error. In this case,
+// DecryptBatch returns this error and no Results, even if only
+// a single ciphertext could not be decrypted.
+//
+// It returns an error if the entire request fails. For example,
+// when the enclave does not exist or no KMS server is reachable.
+// The returned errors are of type *HostError.
+func (c *Client) DecryptBatch(ctx context.Context, enclave string, reqs ...*DecryptRequest) ([]Result[*DecryptResponse], error) {
+	return sendBatch[pb.DecryptRequest, pb.DecryptResponse, DecryptResponse](ctx, c, enclave, cmds.KeyDecrypt, reqs)
+}
+
+// GenerateKeyBatch generates one data encryption key for each
+// request with the respective key within the given enclave, like
+// GenerateKey, using a single request. It returns one Result per
+// request, in the same order. Each Result contains either the
+// plaintext and ciphertext of the data encryption key or the error
+// of its request. For example, ErrKeyNotFound if no such key exists.
+//
+// Per-request results require a KMS server that supports partial
+// results. A server without such support executes the requests
+// until one fails and responds with its error. In this case,
+// GenerateKeyBatch returns this error and no Results, even if only
+// a single data encryption key could not be generated.
+//
+// It returns an error if the entire request fails. For example,
+// when the enclave does not exist or no KMS server is reachable.
+// The returned errors are of type *HostError.
+func (c *Client) GenerateKeyBatch(ctx context.Context, enclave string, reqs ...*GenerateKeyRequest) ([]Result[*GenerateKeyResponse], error) {
+	return sendBatch[pb.GenerateKeyRequest, pb.GenerateKeyResponse, GenerateKeyResponse](ctx, c, enclave, cmds.KeyGenerate, reqs)
+}
+
+// MACBatch computes the message authentication codes of the messages
+// of all requests with the respective keys within the given enclave,
+// like MAC, using a single request. It returns one Result per request,
+// in the same order. Each Result contains either the MAC or the error
+// of its request. For example, ErrKeyNotFound if no such key exists.
+//
+// Per-request results require a KMS server that supports partial
+// results. A server without such support executes the requests
+// until one fails and responds with its error. In this case,
+// MACBatch returns this error and no Results, even if the MAC of
+// only a single message could not be computed.
+//
+// It returns an error if the entire request fails. For example,
+// when the enclave does not exist or no KMS server is reachable.
+// The returned errors are of type *HostError.
+func (c *Client) MACBatch(ctx context.Context, enclave string, reqs ...*MACRequest) ([]Result[*MACResponse], error) {
+	return sendBatch[pb.MACRequest, pb.MACResponse, MACResponse](ctx, c, enclave, cmds.KeyMAC, reqs)
+}
+
+// sendBatch sends all requests as a single request that
+// asks the KMS to execute all commands, even if some of
+// them fail, and returns one Result per request.
+func sendBatch[ReqM, RespM, RespS any, ReqP pb.Pointer[ReqM], Req pb.Marshaler[ReqP], RespP pb.Pointer[RespM], Resp interface {
+	pb.Unmarshaler[RespP]
+	*RespS
+}](ctx context.Context, c *Client, enclave string, cmd cmds.Command, reqs []Req) ([]Result[Resp], error) {
+	if len(reqs) == 0 {
+		return []Result[Resp]{}, nil
+	}
+
+	p := pool.Get(128 * len(reqs))
+	defer pool.Put(p)
+
+	body := (*p)[:0]
+	for _, req := range reqs {
+		var err error
+		body, err = cmds.Encode[ReqM, ReqP](body, cmd, req)
+		if err != nil {
+			return nil, hostError("", err)
+		}
+	}
+
+	resp, err := c.Send(ctx, &Request{
+		Enclave: enclave,
+		Body:    body,
+		Partial: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return decodeResults[RespM, RespS, RespP, Resp](resp, cmd, len(reqs))
+}
+
 // CreatePolicy creates a new or overwrites an exisiting policy with the
 // name req.Name within the given enclave.
 //
+// KMS servers that don't support rule conditions ignore them. Such
+// servers apply conditional allow rules unconditionally. Refer to Rule.
+//
 // It returns ErrEnclaveNotFound if no such enclave exists, wrapped in a
 // HostError. The returned error is of type *HostError.
 func (c *Client) CreatePolicy(ctx context.Context, enclave string, req *CreatePolicyRequest) error {
@@ -1682,3 +2471,18 @@ func httpsURL(endpoint string) string {
 	}
 	return endpoint
 }
+
+// trimEndpoints returns the hosts of the given endpoints
+// without any URL scheme. It skips empty endpoints.
+func trimEndpoints(endpoints []string) []string {
+	hosts := make([]string, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		endpoint = strings.TrimSpace(endpoint)
+		endpoint = strings.TrimPrefix(endpoint, "http://")
+		endpoint = strings.TrimPrefix(endpoint, "https://")
+		if endpoint != "" {
+			hosts = append(hosts, endpoint)
+		}
+	}
+	return hosts
+}
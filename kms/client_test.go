@@ -0,0 +1,443 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package kms_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/minio/kms-go/kms"
+	"github.com/minio/kms-go/kms/cmds"
+	"github.com/minio/kms-go/kms/kmstest"
+)
+
+func TestClient_DecryptBatch(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	const Enclave = kmstest.DefaultEnclave
+	ctx := context.Background()
+	client := srv.Client()
+	if err := client.CreateKey(ctx, Enclave, &kms.CreateKeyRequest{Name: "my-key"}); err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+
+	plaintext := []byte("Hello World")
+	enc, err := client.Encrypt(ctx, Enclave, &kms.EncryptRequest{Name: "my-key", Plaintext: plaintext})
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	corrupted := bytes.Clone(enc[0].Ciphertext)
+	corrupted[0] ^= 1
+
+	reqs := []*kms.DecryptRequest{
+		{Name: "my-key", Version: enc[0].Version, Ciphertext: enc[0].Ciphertext},
+		{Name: "my-key", Version: enc[0].Version, Ciphertext: corrupted},
+		{Name: "other-key", Version: 1, Ciphertext: enc[0].Ciphertext},
+		{Name: "my-key", Version: enc[0].Version, Ciphertext: enc[0].Ciphertext},
+	}
+	if _, err = client.Decrypt(ctx, Enclave, reqs...); !errors.Is(err, kms.ErrDecrypt) {
+		t.Fatalf("Decrypting a corrupted ciphertext should have failed: got '%v' - want '%v'", err, kms.ErrDecrypt)
+	}
+
+	results, err := client.DecryptBatch(ctx, Enclave, reqs...)
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	if len(results) != len(reqs) {
+		t.Fatalf("Invalid number of results: got %d - want %d", len(results), len(reqs))
+	}
+	for _, i := range []int{0, 3} {
+		if results[i].Err != nil {
+			t.Fatalf("Result %d: failed to decrypt: %v", i, results[i].Err)
+		}
+		if !bytes.Equal(results[i].Response.Plaintext, plaintext) {
+			t.Fatalf("Result %d: plaintext mismatch: got '%s' - want '%s'", i, results[i].Response.Plaintext, plaintext)
+		}
+	}
+	if err = results[1].Err; !errors.Is(err, kms.ErrDecrypt) {
+		t.Fatalf("Result 1: decrypting a corrupted ciphertext should have failed: got '%v' - want '%v'", err, kms.ErrDecrypt)
+	}
+	if err = results[2].Err; !errors.Is(err, kms.ErrKeyNotFound) {
+		t.Fatalf("Result 2: decrypting with a non-existing key should have failed: got '%v' - want '%v'", err, kms.ErrKeyNotFound)
+	}
+}
+
+func TestClient_EncryptBatch_ResultMismatch(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	// modify replaces the request body, such that the server
+	// responds with more or fewer results than requested.
+	var modify func(body []byte) []byte
+	intercept := func(ctx context.Context, call *kms.Call, next kms.Invoker) (*http.Response, error) {
+		if modify != nil {
+			call.Request.Body = modify(call.Request.Body)
+		}
+		return next(ctx, call)
+	}
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(srv.Certificate())
+	client, err := kms.NewClient(&kms.Config{
+		Endpoints:    []string{srv.Host()},
+		APIKey:       srv.APIKey,
+		TLS:          &tls.Config{RootCAs: rootCAs},
+		Interceptors: []kms.Interceptor{intercept},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	const Enclave = kmstest.DefaultEnclave
+	ctx := context.Background()
+	if err = client.CreateKey(ctx, Enclave, &kms.CreateKeyRequest{Name: "my-key"}); err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+
+	reqs := []*kms.EncryptRequest{
+		{Name: "my-key", Plaintext: []byte("Hello")},
+		{Name: "my-key", Plaintext: []byte("World")},
+	}
+	for i, fn := range []func([]byte) []byte{
+		func(b []byte) []byte { return b[:6+binary.BigEndian.Uint32(b[2:])] }, // 0: only first command
+		func(b []byte) []byte { return append(b, b...) },                      // 1: all commands twice
+	} {
+		modify = fn
+		_, err = client.EncryptBatch(ctx, Enclave, reqs...)
+		if err == nil || !strings.Contains(err.Error(), "results than requests") {
+			t.Fatalf("Test %d: mismatching number of results should have failed: got '%v'", i, err)
+		}
+	}
+}
+
+func TestClient_ListKeyVersions(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	const (
+		Enclave = kmstest.DefaultEnclave
+		N       = 5
+	)
+	ctx := context.Background()
+	client := srv.Client()
+	for i := 0; i < N; i++ {
+		if err := client.CreateKey(ctx, Enclave, &kms.CreateKeyRequest{Name: "my-key", AddVersion: i > 0}); err != nil {
+			t.Fatalf("Failed to create key version: %v", err)
+		}
+	}
+	if err := client.DeleteKey(ctx, Enclave, &kms.DeleteKeyRequest{Name: "my-key", Version: 2}); err != nil {
+		t.Fatalf("Failed to delete key version: %v", err)
+	}
+
+	page, err := client.ListKeyVersions(ctx, Enclave, &kms.ListKeyVersionsRequest{Name: "my-key", Limit: 2})
+	if err != nil {
+		t.Fatalf("Failed to list key versions: %v", err)
+	}
+	if len(page.Items) != 2 || page.Items[0].Version != 1 || page.Items[1].Version != 3 || page.ContinueAt != "4" {
+		t.Fatalf("Invalid listing: got %+v", page)
+	}
+
+	var versions []int
+	iter := client.KeyVersions(Enclave, "my-key")
+	for v, err := iter.Next(ctx); err != io.EOF; v, err = iter.Next(ctx) {
+		if err != nil {
+			t.Fatalf("Failed to list key versions: %v", err)
+		}
+		versions = append(versions, v.Version)
+	}
+	if len(versions) != N-1 || versions[0] != 1 || versions[N-2] != N {
+		t.Fatalf("Invalid key versions: got %v", versions)
+	}
+
+	items, err := client.KeyVersions(Enclave, "my-key").Collect(ctx, &kms.ListRequest{ContinueAt: "3", Limit: 1})
+	if err != nil {
+		t.Fatalf("Failed to list key versions: %v", err)
+	}
+	if len(items) != 3 || items[0].Version != 3 || items[2].Version != N {
+		t.Fatalf("Invalid key versions: got %+v", items)
+	}
+
+	if _, err = client.ListKeyVersions(ctx, Enclave, &kms.ListKeyVersionsRequest{Name: "other-key"}); !errors.Is(err, kms.ErrKeyNotFound) {
+		t.Fatalf("Listing a non-existing key should have failed: got '%v' - want '%v'", err, kms.ErrKeyNotFound)
+	}
+}
+
+func TestClient_ServerStatus(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := srv.Client()
+
+	status, err := client.ServerStatus(ctx, &kms.ServerStatusRequest{})
+	if err != nil {
+		t.Fatalf("Failed to fetch server status: %v", err)
+	}
+	if len(status) != 1 || status[0].Host != srv.Host() || status[0].Role != "Leader" {
+		t.Fatalf("Invalid server status: got %+v", status)
+	}
+
+	const Unreachable = "127.0.0.1:1"
+	status, err = client.ServerStatus(ctx, &kms.ServerStatusRequest{Hosts: []string{srv.Host(), Unreachable}})
+	if len(status) != 1 {
+		t.Fatalf("Invalid number of status responses: got %d - want %d", len(status), 1)
+	}
+	if hErr := kms.AsHostError(err); hErr == nil || hErr.Host != Unreachable {
+		t.Fatalf("Fetching status from an unreachable host should have failed: got '%v'", err)
+	}
+}
+
+func TestClient_APIs(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := srv.Client()
+	for _, host := range []string{"", srv.Host()} {
+		apis, err := client.APIs(ctx, host)
+		if err != nil {
+			t.Fatalf("Failed to fetch APIs: %v", err)
+		}
+
+		var found bool
+		for _, a := range apis {
+			if a.Path == "/v1/kms/" {
+				found = a.Method == http.MethodPost && a.MaxBody > 0 && a.Timeout > 0
+			}
+		}
+		if !found {
+			t.Fatalf("Invalid API listing: got %+v", apis)
+		}
+	}
+}
+
+func TestClient_RefreshHosts(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(srv.Certificate())
+
+	changes := make(chan []string, 4)
+	client, err := kms.NewClient(&kms.Config{
+		Endpoints:     []string{srv.Host()},
+		APIKey:        srv.APIKey,
+		TLS:           &tls.Config{RootCAs: rootCAs},
+		OnHostsChange: func(hosts []string) { changes <- hosts },
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	const Node = "127.0.0.1:2"
+	ctx := context.Background()
+	if err = srv.Client().AddNode(ctx, &kms.AddClusterNodeRequest{Host: Node}); err != nil {
+		t.Fatalf("Failed to add node: %v", err)
+	}
+	if err = client.RefreshHosts(ctx); err != nil {
+		t.Fatalf("Failed to refresh hosts: %v", err)
+	}
+	if hosts := client.Hosts(); !slices.Equal(hosts, []string{srv.Host(), Node}) {
+		t.Fatalf("Invalid hosts: got %v - want %v", hosts, []string{srv.Host(), Node})
+	}
+	if hosts := <-changes; !slices.Equal(hosts, client.Hosts()) {
+		t.Fatalf("Invalid host change: got %v - want %v", hosts, client.Hosts())
+	}
+
+	// Updating hosts while sending requests must not race.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.KeyStatus(ctx, kmstest.DefaultEnclave, &kms.KeyStatusRequest{Name: "my-key"})
+			if !errors.Is(err, kms.ErrKeyNotFound) {
+				t.Errorf("Fetching a non-existing key should have failed: got '%v' - want '%v'", err, kms.ErrKeyNotFound)
+			}
+		}()
+	}
+	if err = client.SetHosts(srv.Host()); err != nil {
+		t.Fatalf("Failed to set hosts: %v", err)
+	}
+	wg.Wait()
+
+	if hosts := <-changes; !slices.Equal(hosts, []string{srv.Host()}) {
+		t.Fatalf("Invalid host change: got %v - want %v", hosts, []string{srv.Host()})
+	}
+	if err = client.SetHosts(); err == nil {
+		t.Fatal("Setting an empty list of hosts should have failed")
+	}
+}
+
+func TestClient_HostStats(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	const Unreachable = "127.0.0.1:1"
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(srv.Certificate())
+
+	client, err := kms.NewClient(&kms.Config{
+		Endpoints:      []string{Unreachable, srv.Host()},
+		APIKey:         srv.APIKey,
+		TLS:            &tls.Config{RootCAs: rootCAs},
+		HostSelector:   kms.RoundRobin(),
+		CircuitBreaker: &kms.CircuitBreaker{FailureThreshold: 3},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	// Every other request is sent to the unreachable host first
+	// and retried. Its breaker remains closed since it has not
+	// failed often enough.
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		_, err := client.KeyStatus(ctx, kmstest.DefaultEnclave, &kms.KeyStatusRequest{Name: "my-key"})
+		if !errors.Is(err, kms.ErrKeyNotFound) {
+			t.Fatalf("Fetching a non-existing key should have failed: got '%v' - want '%v'", err, kms.ErrKeyNotFound)
+		}
+	}
+
+	stats := client.HostStats()
+	if len(stats) != 2 {
+		t.Fatalf("Invalid number of host stats: got '%d' - want '%d'", len(stats), 2)
+	}
+	if s := stats[0]; s.Host != Unreachable || s.State != kms.CircuitClosed || s.ConsecutiveFailures != 2 || s.Failures != 2 || s.LastError == nil {
+		t.Fatalf("Invalid stats of '%s': got '%+v'", Unreachable, s)
+	}
+	if s := stats[1]; s.Host != srv.Host() || s.State != kms.CircuitClosed || s.Successes != 4 || s.Failures != 0 || s.Latency <= 0 {
+		t.Fatalf("Invalid stats of '%s': got '%+v'", srv.Host(), s)
+	}
+}
+
+func TestClient_Reconcile(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(srv.Certificate())
+	cert, err := kms.GenerateCertificate(srv.APIKey, nil)
+	if err != nil {
+		t.Fatalf("Failed to generate certificate: %v", err)
+	}
+	forwarder := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: rootCAs, Certificates: []tls.Certificate{cert}},
+		},
+	}
+
+	// The proxy forwards KEY:STATUS requests to the KMS server and
+	// returns the response. It forwards any other request, if enabled,
+	// but closes the connection without sending a response.
+	var forward atomic.Bool
+	proxy := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		status := len(body) >= 2 && cmds.Command(binary.BigEndian.Uint16(body)) == cmds.KeyStatus
+
+		if status || forward.Load() {
+			req, err := http.NewRequestWithContext(r.Context(), r.Method, srv.URL+r.URL.RequestURI(), bytes.NewReader(body))
+			if err == nil {
+				req.Header = r.Header.Clone()
+				if resp, err := forwarder.Do(req); err == nil {
+					defer resp.Body.Close()
+
+					if status {
+						maps.Copy(w.Header(), resp.Header)
+						w.WriteHeader(resp.StatusCode)
+						io.Copy(w, resp.Body)
+						return
+					}
+				}
+			}
+		}
+		if conn, _, err := w.(http.Hijacker).Hijack(); err == nil {
+			conn.Close()
+		}
+	}))
+	defer proxy.Close()
+
+	rootCAs.AddCert(proxy.Certificate())
+	client, err := kms.NewClient(&kms.Config{
+		Endpoints: []string{strings.TrimPrefix(proxy.URL, "https://")},
+		APIKey:    srv.APIKey,
+		TLS:       &tls.Config{RootCAs: rootCAs},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	const Enclave = kmstest.DefaultEnclave
+	ctx := context.Background()
+	for i, test := range reconcileTests {
+		forward.Store(test.Forward)
+
+		var outcomeErr *kms.OutcomeUnknownError
+		err := client.CreateKey(ctx, Enclave, &kms.CreateKeyRequest{Name: "my-key", AddVersion: test.AddVersion})
+		if !errors.As(err, &outcomeErr) {
+			t.Fatalf("Test %d: creating a key should have failed with an unknown outcome: got '%v'", i, err)
+		}
+		outcomeErr.Sent = outcomeErr.Sent.Add(time.Hour) // Reconcile must not depend on the client's clock
+
+		applied, err := srv.Client().Reconcile(ctx, outcomeErr)
+		if err != nil {
+			t.Fatalf("Test %d: failed to reconcile: %v", i, err)
+		}
+		if len(applied) != 1 || applied[0] != test.Applied {
+			t.Fatalf("Test %d: got '%v' - want '%v'", i, applied, []bool{test.Applied})
+		}
+	}
+
+	if _, err = client.ListKeys(ctx, &kms.ListRequest{Enclave: Enclave}); kms.AsHostError(err) == nil || errors.As(err, new(*kms.OutcomeUnknownError)) {
+		t.Fatalf("Listing keys should have failed without an unknown outcome: got '%v'", err)
+	}
+}
+
+var reconcileTests = []struct {
+	Forward    bool
+	AddVersion bool
+	Applied    bool
+}{
+	{Forward: false, AddVersion: false, Applied: false}, // 0
+	{Forward: true, AddVersion: false, Applied: true},   // 1
+	{Forward: false, AddVersion: true, Applied: false},  // 2
+	{Forward: true, AddVersion: true, Applied: true},    // 3
+}
@@ -0,0 +1,197 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/minio/kms-go/kms"
+	"github.com/spf13/cobra"
+)
+
+func (c *cli) clusterCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cluster",
+		Short: "Manage the KMS cluster",
+	}
+	cmd.AddCommand(
+		c.clusterStatusCmd(),
+		c.clusterHostsCmd(),
+		c.clusterAddNodeCmd(),
+		c.clusterRemoveNodeCmd(),
+		c.clusterEditCmd(),
+		c.clusterAddHSMCmd(),
+		c.clusterRemoveHSMCmd(),
+	)
+	return cmd
+}
+
+func (c *cli) clusterStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the status of all cluster nodes",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := c.Client()
+			if err != nil {
+				return err
+			}
+			status, err := client.ClusterStatus(cmd.Context(), &kms.ClusterStatusRequest{})
+			if err != nil {
+				return err
+			}
+
+			type JSON struct {
+				NodesUp   map[int]serverStatusJSON `json:"nodes_up"`
+				NodesDown map[int]string           `json:"nodes_down,omitempty"`
+			}
+			var (
+				v = JSON{NodesUp: make(map[int]serverStatusJSON, len(status.NodesUp)), NodesDown: status.NodesDown}
+				t = &table{Header: []string{"ID", "HOST", "STATE", "ROLE", "VERSION"}}
+			)
+			for id, s := range status.NodesUp {
+				v.NodesUp[id] = serverStatusToJSON(s)
+			}
+
+			ids := slices.AppendSeq(slices.Collect(maps.Keys(status.NodesUp)), maps.Keys(status.NodesDown))
+			slices.Sort(ids)
+			for _, id := range ids {
+				if s, ok := status.NodesUp[id]; ok {
+					t.Add(strconv.Itoa(id), s.Host, "up", s.Role, s.Version)
+				} else {
+					t.Add(strconv.Itoa(id), status.NodesDown[id], "down", "-", "-")
+				}
+			}
+			return c.print(cmd, v, t)
+		},
+	}
+}
+
+func (c *cli) clusterHostsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "hosts",
+		Short: "List the hosts of all cluster nodes",
+		Long: `List the hosts of all cluster nodes as reported by the cluster status.
+The listed hosts can be used as --server list.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := c.Client()
+			if err != nil {
+				return err
+			}
+			if err = client.RefreshHosts(cmd.Context()); err != nil {
+				return err
+			}
+
+			hosts := client.Hosts()
+			t := &table{Header: []string{"HOST"}}
+			for _, host := range hosts {
+				t.Add(host)
+			}
+			return c.print(cmd, hosts, t)
+		},
+	}
+}
+
+func (c *cli) clusterAddNodeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "add-node HOST",
+		Short: "Add a KMS server to the cluster",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := c.Client()
+			if err != nil {
+				return err
+			}
+			return client.AddNode(cmd.Context(), &kms.AddClusterNodeRequest{Host: args[0]})
+		},
+	}
+}
+
+func (c *cli) clusterRemoveNodeCmd() *cobra.Command {
+	var deleteCluster bool
+	cmd := &cobra.Command{
+		Use:   "remove-node HOST",
+		Short: "Remove a KMS server from the cluster",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := c.Client()
+			if err != nil {
+				return err
+			}
+			return client.RemoveNode(cmd.Context(), &kms.RemoveClusterNodeRequest{
+				Host:                args[0],
+				DeleteClusterOnHost: deleteCluster,
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&deleteCluster, "delete-cluster", false, "Delete all cluster information on the removed KMS server")
+	return cmd
+}
+
+func (c *cli) clusterEditCmd() *cobra.Command {
+	var (
+		host   string
+		remove []int
+	)
+	cmd := &cobra.Command{
+		Use:   "edit --remove ID...",
+		Short: "Edit the cluster definition of a KMS server",
+		Long: `Edit the cluster definition of a single KMS server directly.
+
+Editing the cluster definition does not require write quorum. It should
+only be used to recover a cluster that has lost quorum.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := c.Client()
+			if err != nil {
+				return err
+			}
+			return client.EditCluster(cmd.Context(), &kms.EditClusterRequest{
+				Host:   host,
+				Remove: remove,
+			})
+		},
+	}
+	cmd.Flags().StringVar(&host, "host", "", "KMS server whose cluster definition is edited")
+	cmd.Flags().IntSliceVar(&remove, "remove", nil, "IDs of nodes to remove from the cluster definition")
+	cmd.MarkFlagRequired("remove")
+	return cmd
+}
+
+func (c *cli) clusterAddHSMCmd() *cobra.Command {
+	var overwrite bool
+	cmd := &cobra.Command{
+		Use:   "add-hsm NAME",
+		Short: "Add an HSM to the cluster",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := c.Client()
+			if err != nil {
+				return err
+			}
+			return client.AddHSM(cmd.Context(), &kms.AddHSMRequest{Name: args[0], Overwrite: overwrite})
+		},
+	}
+	cmd.Flags().BoolVar(&overwrite, "overwrite", false, "Overwrite an existing HSM with the same name")
+	return cmd
+}
+
+func (c *cli) clusterRemoveHSMCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove-hsm NAME",
+		Short: "Remove an HSM from the cluster",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := c.Client()
+			if err != nil {
+				return err
+			}
+			return client.RemoveHSM(cmd.Context(), &kms.RemoveHSMRequest{Name: args[0]})
+		},
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+
+	"github.com/minio/kms-go/kms"
+	"github.com/spf13/cobra"
+)
+
+// completeEnclaves completes enclave names.
+func (c *cli) completeEnclaves(cmd *cobra.Command, _ []string, prefix string) ([]string, cobra.ShellCompDirective) {
+	return c.complete(cmd, prefix, false, func(ctx context.Context, client *kms.Client, req *kms.ListRequest) ([]string, error) {
+		enclaves, err := (&kms.Iter[kms.EnclaveStatusResponse]{NextFn: client.ListEnclaves}).Collect(ctx, req)
+		names := make([]string, 0, len(enclaves))
+		for _, e := range enclaves {
+			names = append(names, e.Name)
+		}
+		return names, err
+	})
+}
+
+// completeKeys completes key names within the enclave.
+func (c *cli) completeKeys(cmd *cobra.Command, _ []string, prefix string) ([]string, cobra.ShellCompDirective) {
+	return c.complete(cmd, prefix, true, func(ctx context.Context, client *kms.Client, req *kms.ListRequest) ([]string, error) {
+		keys, err := (&kms.Iter[kms.KeyStatusResponse]{NextFn: client.ListKeys}).Collect(ctx, req)
+		names := make([]string, 0, len(keys))
+		for _, k := range keys {
+			names = append(names, k.Name)
+		}
+		return names, err
+	})
+}
+
+// completePolicies completes policy names within the enclave.
+func (c *cli) completePolicies(cmd *cobra.Command, _ []string, prefix string) ([]string, cobra.ShellCompDirective) {
+	return c.complete(cmd, prefix, true, func(ctx context.Context, client *kms.Client, req *kms.ListRequest) ([]string, error) {
+		policies, err := (&kms.Iter[kms.PolicyStatusResponse]{NextFn: client.ListPolicies}).Collect(ctx, req)
+		names := make([]string, 0, len(policies))
+		for _, p := range policies {
+			names = append(names, p.Name)
+		}
+		return names, err
+	})
+}
+
+// completeIdentities completes identities within the enclave.
+func (c *cli) completeIdentities(cmd *cobra.Command, _ []string, prefix string) ([]string, cobra.ShellCompDirective) {
+	return c.complete(cmd, prefix, true, func(ctx context.Context, client *kms.Client, req *kms.ListRequest) ([]string, error) {
+		identities, err := (&kms.Iter[kms.IdentityResponse]{NextFn: client.ListIdentities}).Collect(ctx, req)
+		names := make([]string, 0, len(identities))
+		for _, i := range identities {
+			names = append(names, i.Identity.String())
+		}
+		return names, err
+	})
+}
+
+// complete returns the names returned by list that start with
+// prefix. Errors are ignored since completions are best-effort.
+func (c *cli) complete(cmd *cobra.Command, prefix string, inEnclave bool, list func(context.Context, *kms.Client, *kms.ListRequest) ([]string, error)) ([]string, cobra.ShellCompDirective) {
+	const Directive = cobra.ShellCompDirectiveNoFileComp
+
+	client, err := c.Client()
+	if err != nil {
+		return nil, Directive
+	}
+
+	req := &kms.ListRequest{Prefix: prefix}
+	if inEnclave {
+		if req.Enclave, err = c.Enclave(); err != nil {
+			return nil, Directive
+		}
+	}
+	names, _ := list(cmd.Context(), client, req)
+	return names, Directive
+}
+
+// firstArg returns a completion function that completes
+// only the first argument using complete.
+func firstArg(complete func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective)) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, prefix string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return complete(cmd, args, prefix)
+	}
+}
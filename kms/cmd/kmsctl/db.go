@@ -0,0 +1,100 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io"
+	"os"
+
+	"github.com/minio/kms-go/kms"
+	"github.com/spf13/cobra"
+)
+
+func (c *cli) dbCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Read or write the database of a KMS server",
+	}
+	cmd.AddCommand(
+		c.dbReadCmd(),
+		c.dbWriteCmd(),
+	)
+	return cmd
+}
+
+func (c *cli) dbReadCmd() *cobra.Command {
+	var (
+		host     string
+		filename string
+	)
+	cmd := &cobra.Command{
+		Use:   "read",
+		Short: "Save a snapshot of a KMS server database",
+		Long: `Save a snapshot of a KMS server database to a file. If the file is '-',
+the snapshot is written to standard output.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := c.Client()
+			if err != nil {
+				return err
+			}
+			resp, err := client.ReadDB(cmd.Context(), &kms.ReadDBRequest{Host: host})
+			if err != nil {
+				return err
+			}
+			defer resp.Close()
+
+			f, err := createOutput(cmd, filename)
+			if err != nil {
+				return err
+			}
+			if _, err = io.Copy(f, resp); err != nil {
+				f.Close()
+				return err
+			}
+			return f.Close()
+		},
+	}
+	cmd.Flags().StringVar(&host, "host", "", "KMS server to read the database from")
+	cmd.Flags().StringVarP(&filename, "file", "f", "", "File to write the database snapshot to")
+	cmd.MarkFlagRequired("file")
+	return cmd
+}
+
+func (c *cli) dbWriteCmd() *cobra.Command {
+	var (
+		host     string
+		filename string
+	)
+	cmd := &cobra.Command{
+		Use:   "write --host HOST --file FILE",
+		Short: "Restore a KMS server database from a snapshot",
+		Long: `Restore a KMS server database from a snapshot file. If the file is '-',
+the snapshot is read from standard input.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var body io.Reader = cmd.InOrStdin()
+			if filename != "-" {
+				f, err := os.Open(filename)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				body = f
+			}
+
+			client, err := c.Client()
+			if err != nil {
+				return err
+			}
+			return client.WriteDB(cmd.Context(), &kms.WriteDBRequest{Host: host, Body: body})
+		},
+	}
+	cmd.Flags().StringVar(&host, "host", "", "KMS server to write the database to")
+	cmd.Flags().StringVarP(&filename, "file", "f", "", "File containing the database snapshot")
+	cmd.MarkFlagRequired("host")
+	cmd.MarkFlagRequired("file")
+	return cmd
+}
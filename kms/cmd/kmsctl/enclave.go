@@ -0,0 +1,132 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"github.com/minio/kms-go/kms"
+	"github.com/spf13/cobra"
+)
+
+func (c *cli) enclaveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "enclave",
+		Short: "Manage enclaves",
+	}
+	cmd.AddCommand(
+		c.enclaveCreateCmd(),
+		c.enclaveDeleteCmd(),
+		c.enclaveStatusCmd(),
+		c.enclaveListCmd(),
+	)
+	return cmd
+}
+
+func (c *cli) enclaveCreateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "create NAME...",
+		Short: "Create enclaves",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := c.Client()
+			if err != nil {
+				return err
+			}
+			for _, name := range args {
+				if err = client.CreateEnclave(cmd.Context(), &kms.CreateEnclaveRequest{Name: name}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func (c *cli) enclaveDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "delete NAME...",
+		Short:             "Delete enclaves and all their keys, policies and identities",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: c.completeEnclaves,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := c.Client()
+			if err != nil {
+				return err
+			}
+			for _, name := range args {
+				if err = client.DeleteEnclave(cmd.Context(), &kms.DeleteEnclaveRequest{Name: name}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func (c *cli) enclaveStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "status NAME...",
+		Short:             "Show the status of enclaves",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: c.completeEnclaves,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := c.Client()
+			if err != nil {
+				return err
+			}
+
+			reqs := make([]*kms.EnclaveStatusRequest, 0, len(args))
+			for _, name := range args {
+				reqs = append(reqs, &kms.EnclaveStatusRequest{Name: name})
+			}
+			enclaves, err := client.EnclaveStatus(cmd.Context(), reqs...)
+			if err != nil {
+				return err
+			}
+			return c.printEnclaves(cmd, enclaves)
+		},
+	}
+}
+
+func (c *cli) enclaveListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "ls [PREFIX]",
+		Aliases: []string{"list"},
+		Short:   "List enclaves",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req := &kms.ListRequest{}
+			if len(args) > 0 {
+				req.Prefix = args[0]
+			}
+
+			client, err := c.Client()
+			if err != nil {
+				return err
+			}
+			enclaves, err := (&kms.Iter[kms.EnclaveStatusResponse]{NextFn: client.ListEnclaves}).Collect(cmd.Context(), req)
+			if err != nil {
+				return err
+			}
+
+			list := make([]*kms.EnclaveStatusResponse, 0, len(enclaves))
+			for i := range enclaves {
+				list = append(list, &enclaves[i])
+			}
+			return c.printEnclaves(cmd, list)
+		},
+	}
+}
+
+func (c *cli) printEnclaves(cmd *cobra.Command, enclaves []*kms.EnclaveStatusResponse) error {
+	var (
+		v = make([]enclaveJSON, 0, len(enclaves))
+		t = &table{Header: []string{"NAME", "CREATED AT", "CREATED BY"}}
+	)
+	for _, e := range enclaves {
+		v = append(v, enclaveToJSON(e))
+		t.Add(e.Name, formatTime(e.CreatedAt), formatIdentity(e.CreatedBy))
+	}
+	return c.print(cmd, v, t)
+}
@@ -0,0 +1,180 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"aead.dev/mtls"
+	"github.com/minio/kms-go/kms"
+	"github.com/spf13/cobra"
+)
+
+func (c *cli) identityCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "identity",
+		Short: "Manage identities within an enclave",
+	}
+	cmd.AddCommand(
+		c.identityCreateCmd(),
+		c.identityGetCmd(),
+		c.identityDeleteCmd(),
+		c.identityListCmd(),
+	)
+	return cmd
+}
+
+func (c *cli) identityCreateCmd() *cobra.Command {
+	var (
+		privilege      string
+		serviceAccount bool
+		tags           map[string]string
+	)
+	cmd := &cobra.Command{
+		Use:   "create IDENTITY",
+		Short: "Create an identity",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			identity, err := mtls.ParseIdentity(args[0])
+			if err != nil {
+				return err
+			}
+			p, err := kms.ParsePrivilege(privilege)
+			if err != nil {
+				return err
+			}
+
+			enclave, client, err := c.enclaveClient()
+			if err != nil {
+				return err
+			}
+			return client.CreateIdentity(cmd.Context(), enclave, &kms.CreateIdentityRequest{
+				Identity:         identity,
+				Privilege:        p,
+				IsServiceAccount: serviceAccount,
+				Tags:             tags,
+			})
+		},
+	}
+	cmd.Flags().StringVar(&privilege, "privilege", kms.User.String(), "Privilege: 'SysAdmin', 'Admin' or 'User'")
+	cmd.Flags().BoolVar(&serviceAccount, "service-account", false, "Create the identity as service account")
+	cmd.Flags().StringToStringVar(&tags, "tag", nil, "Tags attached to the identity as key=value pairs")
+	cmd.RegisterFlagCompletionFunc("privilege", cobra.FixedCompletions(
+		[]string{kms.SysAdmin.String(), kms.Admin.String(), kms.User.String()},
+		cobra.ShellCompDirectiveNoFileComp,
+	))
+	return cmd
+}
+
+func (c *cli) identityGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "get IDENTITY...",
+		Short:             "Show identities",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: c.completeIdentities,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reqs := make([]*kms.IdentityRequest, 0, len(args))
+			for _, arg := range args {
+				identity, err := mtls.ParseIdentity(arg)
+				if err != nil {
+					return err
+				}
+				reqs = append(reqs, &kms.IdentityRequest{Identity: identity})
+			}
+
+			enclave, client, err := c.enclaveClient()
+			if err != nil {
+				return err
+			}
+			identities, err := client.GetIdentity(cmd.Context(), enclave, reqs...)
+			if err != nil {
+				return err
+			}
+			return c.printIdentities(cmd, identities)
+		},
+	}
+}
+
+func (c *cli) identityDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "delete IDENTITY...",
+		Short:             "Delete identities",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: c.completeIdentities,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enclave, client, err := c.enclaveClient()
+			if err != nil {
+				return err
+			}
+			for _, arg := range args {
+				identity, err := mtls.ParseIdentity(arg)
+				if err != nil {
+					return err
+				}
+				if err = client.DeleteIdentity(cmd.Context(), enclave, &kms.DeleteIdentityRequest{Identity: identity}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func (c *cli) identityListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "ls [PREFIX]",
+		Aliases: []string{"list"},
+		Short:   "List identities",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enclave, client, err := c.enclaveClient()
+			if err != nil {
+				return err
+			}
+
+			req := &kms.ListRequest{Enclave: enclave}
+			if len(args) > 0 {
+				req.Prefix = args[0]
+			}
+			identities, err := (&kms.Iter[kms.IdentityResponse]{NextFn: client.ListIdentities}).Collect(cmd.Context(), req)
+			if err != nil {
+				return err
+			}
+
+			list := make([]*kms.IdentityResponse, 0, len(identities))
+			for i := range identities {
+				list = append(list, &identities[i])
+			}
+			return c.printIdentities(cmd, list)
+		},
+	}
+}
+
+func (c *cli) printIdentities(cmd *cobra.Command, identities []*kms.IdentityResponse) error {
+	var (
+		v = make([]identityJSON, 0, len(identities))
+		t = &table{Header: []string{"IDENTITY", "PRIVILEGE", "POLICY", "SERVICE ACCOUNT", "TAGS", "CREATED AT"}}
+	)
+	for _, i := range identities {
+		v = append(v, identityToJSON(i))
+
+		policy := i.Policy
+		if policy == "" {
+			policy = "-"
+		}
+		tags := make([]string, 0, len(i.Tags))
+		for _, k := range slices.Sorted(maps.Keys(i.Tags)) {
+			tags = append(tags, k+"="+i.Tags[k])
+		}
+		if len(tags) == 0 {
+			tags = append(tags, "-")
+		}
+		t.Add(i.Identity.String(), i.Privilege.String(), policy, strconv.FormatBool(i.IsServiceAccount), strings.Join(tags, ","), formatTime(i.CreatedAt))
+	}
+	return c.print(cmd, v, t)
+}
@@ -0,0 +1,463 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+
+	"github.com/minio/kms-go/kms"
+	"github.com/spf13/cobra"
+)
+
+func (c *cli) keyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "key",
+		Short: "Manage and use keys within an enclave",
+		Long: `Manage and use keys within an enclave.
+
+Binary values, like ciphertexts or generated data keys, are printed and
+read as base64 strings.`,
+	}
+	cmd.AddCommand(
+		c.keyCreateCmd(),
+		c.keyImportCmd(),
+		c.keyDeleteCmd(),
+		c.keyStatusCmd(),
+		c.keyListCmd(),
+		c.keyVersionsCmd(),
+		c.keyEncryptCmd(),
+		c.keyDecryptCmd(),
+		c.keyGenerateCmd(),
+		c.keyMACCmd(),
+	)
+	return cmd
+}
+
+func (c *cli) keyCreateCmd() *cobra.Command {
+	var (
+		typ        string
+		addVersion bool
+	)
+	cmd := &cobra.Command{
+		Use:   "create NAME...",
+		Short: "Create keys",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keyType, err := parseKeyType(typ)
+			if err != nil {
+				return err
+			}
+			enclave, client, err := c.enclaveClient()
+			if err != nil {
+				return err
+			}
+
+			for _, name := range args {
+				if err = client.CreateKey(cmd.Context(), enclave, &kms.CreateKeyRequest{
+					Name:       name,
+					Type:       keyType,
+					AddVersion: addVersion,
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&typ, "type", "t", "", "Key type: 'AES256' or 'ChaCha20'. Chosen by the server by default")
+	cmd.Flags().BoolVar(&addVersion, "add-version", false, "Add a new version to existing keys")
+	cmd.RegisterFlagCompletionFunc("type", completeKeyTypes)
+	return cmd
+}
+
+func (c *cli) keyImportCmd() *cobra.Command {
+	var (
+		typ     string
+		keyFile string
+	)
+	cmd := &cobra.Command{
+		Use:   "import NAME --type TYPE --key-file FILE",
+		Short: "Import a key",
+		Long: `Import a key. The key file contains the base64-encoded key material.
+If the key file is '-', the key material is read from standard input.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keyType, err := parseKeyType(typ)
+			if err != nil {
+				return err
+			}
+			b, err := readInput(cmd, keyFile)
+			if err != nil {
+				return err
+			}
+			key, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(b)))
+			if err != nil {
+				return fmt.Errorf("invalid key material in '%s': %v", keyFile, err)
+			}
+
+			enclave, client, err := c.enclaveClient()
+			if err != nil {
+				return err
+			}
+			return client.ImportKey(cmd.Context(), enclave, &kms.ImportKeyRequest{
+				Name: args[0],
+				Type: keyType,
+				Key:  key,
+			})
+		},
+	}
+	cmd.Flags().StringVarP(&typ, "type", "t", "", "Key type: 'AES256' or 'ChaCha20'")
+	cmd.Flags().StringVar(&keyFile, "key-file", "", "File containing the base64-encoded key material")
+	cmd.MarkFlagRequired("type")
+	cmd.MarkFlagRequired("key-file")
+	cmd.RegisterFlagCompletionFunc("type", completeKeyTypes)
+	return cmd
+}
+
+func (c *cli) keyDeleteCmd() *cobra.Command {
+	var (
+		version     int
+		allVersions bool
+	)
+	cmd := &cobra.Command{
+		Use:   "delete NAME...",
+		Short: "Delete keys or key versions",
+		Long: `Delete keys or key versions. By default, the latest key version is deleted.
+
+Deleting a key version deletes its key material irrecoverably. Data encrypted
+with a deleted key version cannot be decrypted anymore.`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: c.completeKeys,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enclave, client, err := c.enclaveClient()
+			if err != nil {
+				return err
+			}
+			for _, name := range args {
+				if err = client.DeleteKey(cmd.Context(), enclave, &kms.DeleteKeyRequest{
+					Name:        name,
+					Version:     version,
+					AllVersions: allVersions,
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&version, "version", 0, "Key version to delete")
+	cmd.Flags().BoolVar(&allVersions, "all-versions", false, "Delete all key versions")
+	cmd.MarkFlagsMutuallyExclusive("version", "all-versions")
+	return cmd
+}
+
+func (c *cli) keyStatusCmd() *cobra.Command {
+	var version int
+	cmd := &cobra.Command{
+		Use:               "status NAME...",
+		Short:             "Show the status of keys",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: c.completeKeys,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enclave, client, err := c.enclaveClient()
+			if err != nil {
+				return err
+			}
+
+			reqs := make([]*kms.KeyStatusRequest, 0, len(args))
+			for _, name := range args {
+				reqs = append(reqs, &kms.KeyStatusRequest{Name: name, Version: version})
+			}
+			keys, err := client.KeyStatus(cmd.Context(), enclave, reqs...)
+			if err != nil {
+				return err
+			}
+			return c.printKeys(cmd, keys)
+		},
+	}
+	cmd.Flags().IntVar(&version, "version", 0, "Key version. Defaults to the latest version")
+	return cmd
+}
+
+func (c *cli) keyListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "ls [PREFIX]",
+		Aliases: []string{"list"},
+		Short:   "List keys",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enclave, client, err := c.enclaveClient()
+			if err != nil {
+				return err
+			}
+
+			req := &kms.ListRequest{Enclave: enclave}
+			if len(args) > 0 {
+				req.Prefix = args[0]
+			}
+			keys, err := (&kms.Iter[kms.KeyStatusResponse]{NextFn: client.ListKeys}).Collect(cmd.Context(), req)
+			if err != nil {
+				return err
+			}
+
+			list := make([]*kms.KeyStatusResponse, 0, len(keys))
+			for i := range keys {
+				list = append(list, &keys[i])
+			}
+			return c.printKeys(cmd, list)
+		},
+	}
+}
+
+func (c *cli) keyVersionsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "versions NAME",
+		Short:             "List the versions of a key",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: firstArg(c.completeKeys),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enclave, client, err := c.enclaveClient()
+			if err != nil {
+				return err
+			}
+			versions, err := client.KeyVersions(enclave, args[0]).Collect(cmd.Context(), &kms.ListRequest{})
+			if err != nil {
+				return err
+			}
+
+			list := make([]*kms.KeyStatusResponse, 0, len(versions))
+			for i := range versions {
+				list = append(list, &versions[i])
+			}
+			return c.printKeys(cmd, list)
+		},
+	}
+}
+
+func (c *cli) keyEncryptCmd() *cobra.Command {
+	var (
+		version        int
+		associatedData string
+	)
+	cmd := &cobra.Command{
+		Use:   "encrypt NAME [PLAINTEXT]",
+		Short: "Encrypt a plaintext",
+		Long: `Encrypt a plaintext with a key. If no plaintext is given, it is read from
+standard input. The ciphertext is printed as base64 string.`,
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: firstArg(c.completeKeys),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			plaintext, err := argOrInput(cmd, args, 1)
+			if err != nil {
+				return err
+			}
+
+			enclave, client, err := c.enclaveClient()
+			if err != nil {
+				return err
+			}
+			resp, err := single(client.Encrypt(cmd.Context(), enclave, &kms.EncryptRequest{
+				Name:           args[0],
+				Version:        version,
+				Plaintext:      plaintext,
+				AssociatedData: []byte(associatedData),
+			}))
+			if err != nil {
+				return err
+			}
+
+			type JSON struct {
+				Version    int    `json:"version"`
+				Ciphertext []byte `json:"ciphertext"`
+			}
+			t := &table{Header: []string{"VERSION", "CIPHERTEXT"}}
+			t.Add(strconv.Itoa(resp.Version), base64.StdEncoding.EncodeToString(resp.Ciphertext))
+			return c.print(cmd, JSON{Version: resp.Version, Ciphertext: resp.Ciphertext}, t)
+		},
+	}
+	cmd.Flags().IntVar(&version, "version", 0, "Key version. Defaults to the latest version")
+	cmd.Flags().StringVar(&associatedData, "associated-data", "", "Associated data bound to the ciphertext")
+	return cmd
+}
+
+func (c *cli) keyDecryptCmd() *cobra.Command {
+	var (
+		version        int
+		associatedData string
+	)
+	cmd := &cobra.Command{
+		Use:   "decrypt NAME [CIPHERTEXT]",
+		Short: "Decrypt a ciphertext",
+		Long: `Decrypt a base64-encoded ciphertext with a key. If no ciphertext is given,
+it is read from standard input. The plaintext is printed as base64 string.`,
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: firstArg(c.completeKeys),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			b, err := argOrInput(cmd, args, 1)
+			if err != nil {
+				return err
+			}
+			ciphertext, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(b)))
+			if err != nil {
+				return fmt.Errorf("invalid ciphertext: %v", err)
+			}
+
+			enclave, client, err := c.enclaveClient()
+			if err != nil {
+				return err
+			}
+			resp, err := single(client.Decrypt(cmd.Context(), enclave, &kms.DecryptRequest{
+				Name:           args[0],
+				Version:        version,
+				Ciphertext:     ciphertext,
+				AssociatedData: []byte(associatedData),
+			}))
+			if err != nil {
+				return err
+			}
+
+			type JSON struct {
+				Plaintext []byte `json:"plaintext"`
+			}
+			t := &table{Header: []string{"PLAINTEXT"}}
+			t.Add(base64.StdEncoding.EncodeToString(resp.Plaintext))
+			return c.print(cmd, JSON{Plaintext: resp.Plaintext}, t)
+		},
+	}
+	cmd.Flags().IntVar(&version, "version", 0, "Key version used to encrypt the plaintext. Defaults to the latest version")
+	cmd.Flags().StringVar(&associatedData, "associated-data", "", "Associated data bound to the ciphertext")
+	return cmd
+}
+
+func (c *cli) keyGenerateCmd() *cobra.Command {
+	var (
+		version        int
+		length         int
+		associatedData string
+	)
+	cmd := &cobra.Command{
+		Use:               "generate NAME",
+		Short:             "Generate a new data encryption key",
+		Long:              "Generate a new data encryption key and return its plaintext and ciphertext as base64 strings.",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: firstArg(c.completeKeys),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enclave, client, err := c.enclaveClient()
+			if err != nil {
+				return err
+			}
+			resp, err := single(client.GenerateKey(cmd.Context(), enclave, &kms.GenerateKeyRequest{
+				Name:           args[0],
+				Version:        version,
+				Length:         length,
+				AssociatedData: []byte(associatedData),
+			}))
+			if err != nil {
+				return err
+			}
+
+			type JSON struct {
+				Version    int    `json:"version"`
+				Plaintext  []byte `json:"plaintext"`
+				Ciphertext []byte `json:"ciphertext"`
+			}
+			t := &table{Header: []string{"VERSION", "PLAINTEXT", "CIPHERTEXT"}}
+			t.Add(strconv.Itoa(resp.Version), base64.StdEncoding.EncodeToString(resp.Plaintext), base64.StdEncoding.EncodeToString(resp.Ciphertext))
+			return c.print(cmd, JSON{Version: resp.Version, Plaintext: resp.Plaintext, Ciphertext: resp.Ciphertext}, t)
+		},
+	}
+	cmd.Flags().IntVar(&version, "version", 0, "Key version. Defaults to the latest version")
+	cmd.Flags().IntVar(&length, "length", 0, "Length of the data encryption key in bytes. Defaults to 32")
+	cmd.Flags().StringVar(&associatedData, "associated-data", "", "Associated data bound to the ciphertext")
+	return cmd
+}
+
+func (c *cli) keyMACCmd() *cobra.Command {
+	var version int
+	cmd := &cobra.Command{
+		Use:   "mac NAME [MESSAGE]",
+		Short: "Compute a message authentication code",
+		Long: `Compute a message authentication code (MAC) of a message with a key. If
+no message is given, it is read from standard input. The MAC is printed as
+base64 string.`,
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: firstArg(c.completeKeys),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			message, err := argOrInput(cmd, args, 1)
+			if err != nil {
+				return err
+			}
+
+			enclave, client, err := c.enclaveClient()
+			if err != nil {
+				return err
+			}
+			resp, err := single(client.MAC(cmd.Context(), enclave, &kms.MACRequest{
+				Name:    args[0],
+				Version: version,
+				Message: message,
+			}))
+			if err != nil {
+				return err
+			}
+
+			type JSON struct {
+				Version int    `json:"version"`
+				MAC     []byte `json:"mac"`
+			}
+			t := &table{Header: []string{"VERSION", "MAC"}}
+			t.Add(strconv.Itoa(resp.Version), base64.StdEncoding.EncodeToString(resp.MAC))
+			return c.print(cmd, JSON{Version: resp.Version, MAC: resp.MAC}, t)
+		},
+	}
+	cmd.Flags().IntVar(&version, "version", 0, "Key version. Defaults to the latest version")
+	return cmd
+}
+
+func (c *cli) printKeys(cmd *cobra.Command, keys []*kms.KeyStatusResponse) error {
+	v := make([]keyJSON, 0, len(keys))
+	for _, k := range keys {
+		v = append(v, keyToJSON(k))
+	}
+	return c.print(cmd, v, keyTable(keys))
+}
+
+// enclaveClient returns the enclave and the KMS client.
+func (c *cli) enclaveClient() (string, *kms.Client, error) {
+	enclave, err := c.Enclave()
+	if err != nil {
+		return "", nil, err
+	}
+	client, err := c.Client()
+	if err != nil {
+		return "", nil, err
+	}
+	return enclave, client, nil
+}
+
+// argOrInput returns args[i], if present, or reads
+// the command's input.
+func argOrInput(cmd *cobra.Command, args []string, i int) ([]byte, error) {
+	if i < len(args) {
+		return []byte(args[i]), nil
+	}
+	return readInput(cmd, "-")
+}
+
+// parseKeyType parses s as key type. An empty s
+// is the zero key type.
+func parseKeyType(s string) (kms.SecretKeyType, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return kms.ParseSecretKeyType(s)
+}
+
+func completeKeyTypes(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return []string{kms.AES256.String(), kms.ChaCha20.String()}, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,109 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/minio/kms-go/kms"
+	"github.com/spf13/cobra"
+)
+
+func (c *cli) logCmd() *cobra.Command {
+	var (
+		host       string
+		level      string
+		message    string
+		since      time.Duration
+		traceLevel string
+	)
+	cmd := &cobra.Command{
+		Use:   "log",
+		Short: "Print the log records of a KMS server",
+		Long: `Print the log records of a KMS server until the server closes the
+connection or the command is interrupted.
+
+With JSON output, every log record is printed as JSON object on a separate
+line.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			req := &kms.LogRequest{
+				Host:       host,
+				Message:    message,
+				Level:      slog.LevelInfo,
+				TraceLevel: slog.LevelError + 1,
+			}
+			if level != "" {
+				if err := req.Level.UnmarshalText([]byte(level)); err != nil {
+					return err
+				}
+			}
+			if traceLevel != "" {
+				if err := req.TraceLevel.UnmarshalText([]byte(traceLevel)); err != nil {
+					return err
+				}
+			}
+			if since > 0 {
+				req.Since = time.Now().Add(-since)
+			}
+
+			client, err := c.Client()
+			if err != nil {
+				return err
+			}
+			logs, err := client.Logs(cmd.Context(), req)
+			if err != nil {
+				return err
+			}
+			defer logs.Close()
+
+			type JSON struct {
+				Time    time.Time        `json:"time"`
+				Level   slog.Level       `json:"level"`
+				Message string           `json:"message"`
+				Trace   []kms.StackFrame `json:"trace,omitempty"`
+			}
+			var (
+				w   = cmd.OutOrStdout()
+				enc = json.NewEncoder(w)
+			)
+			for r, ok := logs.Next(); ok; r, ok = logs.Next() {
+				if c.output == "json" {
+					err = enc.Encode(JSON{Time: r.Time, Level: r.Level, Message: r.Message, Trace: r.Trace})
+				} else {
+					_, err = fmt.Fprintf(w, "%s %-5s %s\n", r.Time.Local().Format(time.RFC3339), r.Level, r.Message)
+					for _, frame := range r.Trace {
+						if err != nil {
+							break
+						}
+						_, err = fmt.Fprintf(w, "\t%s\n\t\t%s:%d\n", frame.Function, frame.File, frame.Line)
+					}
+				}
+				if err != nil {
+					return err
+				}
+			}
+			if err = logs.Close(); errors.Is(err, io.EOF) || cmd.Context().Err() != nil {
+				return nil
+			}
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&host, "host", "", "KMS server to fetch the log records from")
+	cmd.Flags().StringVar(&level, "level", "", "Print only log records with this or a higher level. Defaults to INFO")
+	cmd.Flags().StringVar(&message, "message", "", "Print only log records containing this message")
+	cmd.Flags().DurationVar(&since, "since", 0, "Print log records created within this period of time")
+	cmd.Flags().StringVar(&traceLevel, "trace-level", "", "Include stack traces for log records with this or a higher level")
+
+	levels := cobra.FixedCompletions([]string{"DEBUG", "INFO", "WARN", "ERROR"}, cobra.ShellCompDirectiveNoFileComp)
+	cmd.RegisterFlagCompletionFunc("level", levels)
+	cmd.RegisterFlagCompletionFunc("trace-level", levels)
+	return cmd
+}
@@ -0,0 +1,232 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+// Command kmsctl is a command-line client for MinIO KMS clusters.
+//
+// It provides subcommands for cluster, server, enclave, key, policy,
+// identity, log, profile and database operations. For example:
+//
+//	kmsctl key create my-key --enclave tenant-1
+//	kmsctl cluster status -o json
+//
+// The KMS servers and the API key are read from the environment
+// or from command-line flags. Refer to 'kmsctl --help'.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+
+	"aead.dev/mtls"
+	"github.com/minio/kms-go/kms"
+	"github.com/spf13/cobra"
+)
+
+// Environment variables that configure kmsctl if
+// the corresponding flags are not set.
+const (
+	EnvServer     = "MINIO_KMS_SERVER"       // Comma-separated list of KMS servers
+	EnvAPIKey     = "MINIO_KMS_API_KEY"      // API key used to authenticate
+	EnvAPIKeyFile = "MINIO_KMS_API_KEY_FILE" // File containing the API key
+	EnvEnclave    = "MINIO_KMS_ENCLAVE"      // Enclave used by enclave-scoped commands
+	EnvCAFile     = "MINIO_KMS_CA_FILE"      // PEM file containing trusted CA certificates
+)
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if err := newRootCmd().ExecuteContext(ctx); err != nil {
+		printError(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// cli holds the global flags and the KMS client
+// shared by all subcommands.
+type cli struct {
+	endpoints  []string
+	apiKeyFile string
+	caFile     string
+	insecure   bool
+	enclave    string
+	output     string
+
+	client *kms.Client
+}
+
+// newRootCmd returns the kmsctl root command with
+// all its subcommands.
+func newRootCmd() *cobra.Command {
+	c := &cli{}
+	root := &cobra.Command{
+		Use:   "kmsctl",
+		Short: "Command-line client for MinIO KMS",
+		Long: `Command-line client for MinIO KMS.
+
+The KMS servers are specified with --server or the ` + EnvServer + `
+environment variable as comma-separated list. The API key is read from the
+file specified with --api-key-file or ` + EnvAPIKeyFile + `, or from
+the ` + EnvAPIKey + ` environment variable.`,
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if c.output != "table" && c.output != "json" {
+				return fmt.Errorf("invalid output format '%s': must be 'table' or 'json'", c.output)
+			}
+			return nil
+		},
+		PersistentPostRunE: func(*cobra.Command, []string) error {
+			if c.client != nil {
+				return c.client.Close()
+			}
+			return nil
+		},
+	}
+
+	flags := root.PersistentFlags()
+	flags.StringSliceVarP(&c.endpoints, "server", "s", nil, "KMS server endpoints (env: "+EnvServer+")")
+	flags.StringVar(&c.apiKeyFile, "api-key-file", "", "File containing the API key (env: "+EnvAPIKeyFile+")")
+	flags.StringVar(&c.caFile, "ca-file", "", "PEM file containing trusted CA certificates (env: "+EnvCAFile+")")
+	flags.BoolVarP(&c.insecure, "insecure", "k", false, "Skip verification of the KMS server certificates")
+	flags.StringVarP(&c.enclave, "enclave", "e", "", "Enclave to operate in (env: "+EnvEnclave+")")
+	flags.StringVarP(&c.output, "output", "o", "table", "Output format: 'table' or 'json'")
+	root.RegisterFlagCompletionFunc("enclave", c.completeEnclaves)
+	root.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"table", "json"}, cobra.ShellCompDirectiveNoFileComp))
+
+	root.AddCommand(
+		c.serverCmd(),
+		c.clusterCmd(),
+		c.enclaveCmd(),
+		c.keyCmd(),
+		c.policyCmd(),
+		c.identityCmd(),
+		c.logCmd(),
+		c.profileCmd(),
+		c.dbCmd(),
+	)
+	return root
+}
+
+// Client returns the KMS client configured by the
+// global flags or environment variables.
+func (c *cli) Client() (*kms.Client, error) {
+	if c.client != nil {
+		return c.client, nil
+	}
+
+	endpoints := c.endpoints
+	if len(endpoints) == 0 {
+		if s, ok := os.LookupEnv(EnvServer); ok {
+			endpoints = strings.Split(s, ",")
+		}
+	}
+
+	key, err := c.apiKey()
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConf := &tls.Config{
+		InsecureSkipVerify: c.insecure,
+	}
+	caFile := c.caFile
+	if caFile == "" {
+		caFile = os.Getenv(EnvCAFile)
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConf.RootCAs = x509.NewCertPool()
+		if !tlsConf.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no CA certificates found in '%s'", caFile)
+		}
+	}
+
+	if c.client, err = kms.NewClient(&kms.Config{
+		Endpoints: endpoints,
+		APIKey:    key,
+		TLS:       tlsConf,
+	}); err != nil {
+		return nil, err
+	}
+	return c.client, nil
+}
+
+// Enclave returns the enclave specified by the --enclave
+// flag or the environment. It returns an error if neither
+// is set.
+func (c *cli) Enclave() (string, error) {
+	if c.enclave != "" {
+		return c.enclave, nil
+	}
+	if enclave := os.Getenv(EnvEnclave); enclave != "" {
+		return enclave, nil
+	}
+	return "", errors.New("no enclave specified: use --enclave or " + EnvEnclave)
+}
+
+// apiKey reads the API key from the --api-key-file flag
+// or from the environment.
+func (c *cli) apiKey() (mtls.PrivateKey, error) {
+	filename := c.apiKeyFile
+	if filename == "" {
+		filename = os.Getenv(EnvAPIKeyFile)
+	}
+	if filename != "" {
+		b, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		key, err := mtls.ParsePrivateKey(strings.TrimSpace(string(b)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid API key in '%s': %v", filename, err)
+		}
+		return key, nil
+	}
+
+	s, ok := os.LookupEnv(EnvAPIKey)
+	if !ok {
+		return nil, errors.New("no API key specified: use --api-key-file, " + EnvAPIKeyFile + " or " + EnvAPIKey)
+	}
+	key, err := mtls.ParsePrivateKey(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid API key in %s: %v", EnvAPIKey, err)
+	}
+	return key, nil
+}
+
+// printError writes err to w. Errors returned by KMS servers
+// are written once per failing host.
+func printError(w io.Writer, err error) {
+	hostErrs := kms.UnwrapHostErrors(err)
+	if len(hostErrs) == 0 {
+		fmt.Fprintf(w, "Error: %v\n", err)
+		return
+	}
+	for _, e := range hostErrs {
+		fmt.Fprintf(w, "Error: %s: %s\n", strings.TrimPrefix(e.Host, "https://"), errorMessage(e.Err))
+	}
+}
+
+// errorMessage returns the error message of err and,
+// for KMS API errors, its HTTP status.
+func errorMessage(err error) string {
+	var apiErr kms.Error
+	if errors.As(err, &apiErr) && apiErr.Code != 0 {
+		return fmt.Sprintf("%s (%d %s)", apiErr.Err, apiErr.Code, http.StatusText(apiErr.Code))
+	}
+	return err.Error()
+}
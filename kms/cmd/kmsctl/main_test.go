@@ -0,0 +1,143 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minio/kms-go/kms"
+	"github.com/minio/kms-go/kms/kmstest"
+)
+
+func TestCommands(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	id := srv.APIKey.Identity().String()
+
+	run := newRunner(t, srv)
+	for i, test := range []struct {
+		Args   []string
+		Input  string
+		Output []string // Substrings of the output
+	}{
+		{Args: []string{"server", "version"}, Output: []string{srv.Host()}},                         // 0
+		{Args: []string{"cluster", "status"}, Output: []string{"ID", srv.Host(), "up"}},             // 1
+		{Args: []string{"enclave", "create", "tenant-1"}},                                           // 2
+		{Args: []string{"enclave", "ls"}, Output: []string{kmstest.DefaultEnclave, "tenant-1"}},     // 3
+		{Args: []string{"-e", "tenant-1", "key", "create", "my-key", "--type", "ChaCha20"}},         // 4
+		{Args: []string{"-e", "tenant-1", "key", "ls"}, Output: []string{"my-key", "ChaCha20", id}}, // 5
+		{Args: []string{"-e", "tenant-1", "key", "status", "my-key"}, Output: []string{"my-key"}},   // 6
+		{ // 7
+			Args:  []string{"-e", "tenant-1", "policy", "create", "my-app", "-"},
+			Input: `{"allow": {"KEY:ENCRYPT": "my-key", "KEY:DECRYPT": {"my-key": {"max_batch_size": 1}}}}`,
+		},
+		{Args: []string{"-e", "tenant-1", "policy", "get", "my-app"}, Output: []string{"allow", "KEY:DECRYPT", `{"max_batch_size":1}`}}, // 8
+		{Args: []string{"-e", "tenant-1", "identity", "create", id, "--privilege", "Admin", "--tag", "team=storage"}},                   // 9
+		{Args: []string{"-e", "tenant-1", "identity", "ls"}, Output: []string{id, "Admin", "team=storage"}},                             // 10
+		{Args: []string{"__complete", "-e", "tenant-1", "key", "status", "my"}, Output: []string{"my-key"}},                             // 11
+	} {
+		out, err := run(test.Input, test.Args...)
+		if err != nil {
+			t.Fatalf("Test %d: failed to run '%s': %v", i, strings.Join(test.Args, " "), err)
+		}
+		for _, s := range test.Output {
+			if !strings.Contains(out, s) {
+				t.Fatalf("Test %d: output of '%s' does not contain '%s':\n%s", i, strings.Join(test.Args, " "), s, out)
+			}
+		}
+	}
+
+	// Encrypt and decrypt a message using JSON output
+	out, err := run("", "-e", "tenant-1", "-o", "json", "key", "encrypt", "my-key", "Hello World")
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	var ciphertext struct {
+		Ciphertext []byte `json:"ciphertext"`
+	}
+	if err = json.Unmarshal([]byte(out), &ciphertext); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	out, err = run(base64.StdEncoding.EncodeToString(ciphertext.Ciphertext), "-e", "tenant-1", "-o", "json", "key", "decrypt", "my-key")
+	if err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	var plaintext struct {
+		Plaintext []byte `json:"plaintext"`
+	}
+	if err = json.Unmarshal([]byte(out), &plaintext); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	if string(plaintext.Plaintext) != "Hello World" {
+		t.Fatalf("Invalid plaintext: got '%s' - want '%s'", plaintext.Plaintext, "Hello World")
+	}
+}
+
+func TestCommands_Error(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	run := newRunner(t, srv)
+	_, err := run("", "-e", kmstest.DefaultEnclave, "key", "status", "my-key")
+	if !errors.Is(err, kms.ErrKeyNotFound) {
+		t.Fatalf("Invalid error: got '%v' - want '%v'", err, kms.ErrKeyNotFound)
+	}
+
+	var buf bytes.Buffer
+	printError(&buf, err)
+	if want := "Error: " + srv.Host() + ": key does not exist (404 Not Found)\n"; buf.String() != want {
+		t.Fatalf("Invalid error output: got '%s' - want '%s'", buf.String(), want)
+	}
+
+	if _, err = run("", "key", "ls"); err == nil {
+		t.Fatal("Listing keys without an enclave should have failed")
+	}
+	if _, err = run("", "-o", "yaml", "enclave", "ls"); err == nil {
+		t.Fatal("Using an invalid output format should have failed")
+	}
+	if _, err = run(`{"allow": {"CLUSTER:STATUS": "*"}}`, "-e", kmstest.DefaultEnclave, "policy", "create", "my-policy", "-"); err == nil {
+		t.Fatal("Creating a policy with linter errors should have failed")
+	}
+}
+
+// newRunner returns a function that runs kmsctl with the given
+// input and arguments against srv and returns its output.
+func newRunner(t *testing.T, srv *kmstest.Server) func(input string, args ...string) (string, error) {
+	t.Helper()
+
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "api.key")
+	if err := os.WriteFile(keyFile, []byte(srv.APIKey.String()), 0o600); err != nil {
+		t.Fatalf("Failed to write API key: %v", err)
+	}
+	caFile := filepath.Join(dir, "ca.crt")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600); err != nil {
+		t.Fatalf("Failed to write CA certificate: %v", err)
+	}
+
+	return func(input string, args ...string) (string, error) {
+		var out bytes.Buffer
+		cmd := newRootCmd()
+		cmd.SetArgs(append([]string{"--server", srv.Host(), "--api-key-file", keyFile, "--ca-file", caFile}, args...))
+		cmd.SetIn(strings.NewReader(input))
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"aead.dev/mtls"
+	"github.com/minio/kms-go/kms"
+	"github.com/minio/kms-go/kms/cmds"
+	"github.com/spf13/cobra"
+)
+
+// table is a list of rows printed as aligned columns.
+type table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Add appends a row to the table.
+func (t *table) Add(columns ...string) { t.Rows = append(t.Rows, columns) }
+
+// Write writes the table to w.
+func (t *table) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if len(t.Header) > 0 {
+		fmt.Fprintln(tw, strings.Join(t.Header, "\t"))
+	}
+	for _, row := range t.Rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// print writes v as JSON or t as table to the command's
+// output, depending on the --output flag.
+func (c *cli) print(cmd *cobra.Command, v any, t *table) error {
+	w := cmd.OutOrStdout()
+	if c.output == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	return t.Write(w)
+}
+
+// readInput returns the content of the file with the given
+// name or of the command's input if the name is "-".
+func readInput(cmd *cobra.Command, name string) ([]byte, error) {
+	if name == "-" {
+		return io.ReadAll(cmd.InOrStdin())
+	}
+	return os.ReadFile(name)
+}
+
+// createOutput returns the file with the given name or the
+// command's output if the name is "-".
+func createOutput(cmd *cobra.Command, name string) (io.WriteCloser, error) {
+	if name == "-" {
+		return nopCloser{cmd.OutOrStdout()}, nil
+	}
+	return os.Create(name)
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// single returns the only response of a request containing
+// a single command. It returns an error if there is not
+// exactly one response.
+func single[T any](resp []T, err error) (T, error) {
+	var v T
+	if err != nil {
+		return v, err
+	}
+	if len(resp) != 1 {
+		return v, fmt.Errorf("invalid response: got %d results - want 1", len(resp))
+	}
+	return resp[0], nil
+}
+
+// formatTime returns t in RFC 3339 format, or "-" if t is zero.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(time.RFC3339)
+}
+
+// formatIdentity returns the identity's string representation,
+// or "-" if id is zero.
+func formatIdentity(id mtls.Identity) string {
+	if id.IsZero() {
+		return "-"
+	}
+	return id.String()
+}
+
+// JSON representations of the KMS responses.
+type (
+	enclaveJSON struct {
+		Name      string        `json:"name"`
+		CreatedAt time.Time     `json:"created_at,omitzero"`
+		CreatedBy mtls.Identity `json:"created_by,omitzero"`
+	}
+
+	keyJSON struct {
+		Name      string        `json:"name"`
+		Version   int           `json:"version,omitempty"`
+		Type      string        `json:"type,omitempty"`
+		CreatedAt time.Time     `json:"created_at,omitzero"`
+		CreatedBy mtls.Identity `json:"created_by,omitzero"`
+	}
+
+	policyJSON struct {
+		Name      string                       `json:"name"`
+		Allow     map[cmds.Command]kms.RuleSet `json:"allow,omitempty"`
+		Deny      map[cmds.Command]kms.RuleSet `json:"deny,omitempty"`
+		CreatedAt time.Time                    `json:"created_at,omitzero"`
+		CreatedBy mtls.Identity                `json:"created_by,omitzero"`
+	}
+
+	identityJSON struct {
+		Identity         mtls.Identity     `json:"identity"`
+		Privilege        string            `json:"privilege"`
+		Policy           string            `json:"policy,omitempty"`
+		IsServiceAccount bool              `json:"service_account,omitempty"`
+		ServiceAccounts  []mtls.Identity   `json:"service_accounts,omitempty"`
+		Tags             map[string]string `json:"tags,omitempty"`
+		CreatedAt        time.Time         `json:"created_at,omitzero"`
+		CreatedBy        mtls.Identity     `json:"created_by,omitzero"`
+	}
+)
+
+func enclaveToJSON(r *kms.EnclaveStatusResponse) enclaveJSON {
+	return enclaveJSON{Name: r.Name, CreatedAt: r.CreatedAt, CreatedBy: r.CreatedBy}
+}
+
+func keyToJSON(r *kms.KeyStatusResponse) keyJSON {
+	v := keyJSON{Name: r.Name, Version: r.Version, CreatedAt: r.CreatedAt, CreatedBy: r.CreatedBy}
+	if r.Type != 0 {
+		v.Type = r.Type.String()
+	}
+	return v
+}
+
+func identityToJSON(r *kms.IdentityResponse) identityJSON {
+	return identityJSON{
+		Identity:         r.Identity,
+		Privilege:        r.Privilege.String(),
+		Policy:           r.Policy,
+		IsServiceAccount: r.IsServiceAccount,
+		ServiceAccounts:  r.ServiceAccounts,
+		Tags:             r.Tags,
+		CreatedAt:        r.CreatedAt,
+		CreatedBy:        r.CreatedBy,
+	}
+}
+
+// keyTable returns a table listing the given keys.
+func keyTable(keys []*kms.KeyStatusResponse) *table {
+	t := &table{Header: []string{"NAME", "VERSION", "TYPE", "CREATED AT", "CREATED BY"}}
+	for _, k := range keys {
+		t.Add(k.Name, strconv.Itoa(k.Version), k.Type.String(), formatTime(k.CreatedAt), formatIdentity(k.CreatedBy))
+	}
+	return t
+}
@@ -0,0 +1,324 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"aead.dev/mtls"
+	"github.com/minio/kms-go/kms"
+	"github.com/minio/kms-go/kms/cmds"
+	"github.com/minio/kms-go/kms/policylint"
+	"github.com/spf13/cobra"
+)
+
+func (c *cli) policyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Manage policies within an enclave",
+		Long: `Manage policies within an enclave.
+
+Policies are read from JSON files of the form:
+
+  {
+    "allow": {
+      "KEY:ENCRYPT": ["my-key"],
+      "KEY:DECRYPT": { "my-key": { "source_ips": ["10.0.0.0/8"] } }
+    },
+    "deny": {
+      "KEY:DELETE": "*"
+    }
+  }`,
+	}
+	cmd.AddCommand(
+		c.policyCreateCmd(),
+		c.policyAssignCmd(),
+		c.policyGetCmd(),
+		c.policyStatusCmd(),
+		c.policyDeleteCmd(),
+		c.policyListCmd(),
+		c.policyLintCmd(),
+	)
+	return cmd
+}
+
+func (c *cli) policyCreateCmd() *cobra.Command {
+	var force bool
+	cmd := &cobra.Command{
+		Use:   "create NAME FILE",
+		Short: "Create or replace a policy",
+		Long: `Create or replace a policy with the content of a JSON file. If the file
+is '-', the policy is read from standard input.
+
+The policy is checked with the policy linter before it is created. Warnings
+are printed but only errors prevent the policy from being created.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			b, err := readInput(cmd, args[1])
+			if err != nil {
+				return err
+			}
+
+			findings, err := policylint.CheckJSON(b)
+			if err != nil {
+				return fmt.Errorf("invalid policy: %v", err)
+			}
+			for _, f := range findings {
+				fmt.Fprintln(cmd.ErrOrStderr(), f)
+			}
+			if !force && policylint.MaxSeverity(findings) >= policylint.Error {
+				return errors.New("policy contains errors: use --force to create it anyway")
+			}
+
+			var policy struct {
+				Allow map[cmds.Command]kms.RuleSet `json:"allow"`
+				Deny  map[cmds.Command]kms.RuleSet `json:"deny"`
+			}
+			if err = json.Unmarshal(b, &policy); err != nil {
+				return fmt.Errorf("invalid policy: %v", err)
+			}
+
+			enclave, client, err := c.enclaveClient()
+			if err != nil {
+				return err
+			}
+			return client.CreatePolicy(cmd.Context(), enclave, &kms.CreatePolicyRequest{
+				Name:  args[0],
+				Allow: policy.Allow,
+				Deny:  policy.Deny,
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&force, "force", false, "Create the policy even if the policy linter reports errors")
+	return cmd
+}
+
+func (c *cli) policyAssignCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "assign NAME IDENTITY",
+		Short: "Assign a policy to an identity",
+		Args:  cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, prefix string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return c.completePolicies(cmd, args, prefix)
+			}
+			if len(args) == 1 {
+				return c.completeIdentities(cmd, args, prefix)
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			identity, err := mtls.ParseIdentity(args[1])
+			if err != nil {
+				return err
+			}
+
+			enclave, client, err := c.enclaveClient()
+			if err != nil {
+				return err
+			}
+			return client.AssignPolicy(cmd.Context(), enclave, &kms.AssignPolicyRequest{
+				Policy:   args[0],
+				Identity: identity,
+			})
+		},
+	}
+}
+
+func (c *cli) policyGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "get NAME...",
+		Short:             "Show the allow and deny rules of policies",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: c.completePolicies,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enclave, client, err := c.enclaveClient()
+			if err != nil {
+				return err
+			}
+
+			reqs := make([]*kms.PolicyRequest, 0, len(args))
+			for _, name := range args {
+				reqs = append(reqs, &kms.PolicyRequest{Name: name})
+			}
+			policies, err := client.GetPolicy(cmd.Context(), enclave, reqs...)
+			if err != nil {
+				return err
+			}
+
+			var (
+				v = make([]policyJSON, 0, len(policies))
+				t = &table{Header: []string{"POLICY", "EFFECT", "COMMAND", "PATTERN", "CONDITIONS"}}
+			)
+			for _, p := range policies {
+				v = append(v, policyJSON{
+					Name:      p.Name,
+					Allow:     p.Allow,
+					Deny:      p.Deny,
+					CreatedAt: p.CreatedAt,
+					CreatedBy: p.CreatedBy,
+				})
+				if err = addRules(t, p.Name, "deny", p.Deny); err != nil {
+					return err
+				}
+				if err = addRules(t, p.Name, "allow", p.Allow); err != nil {
+					return err
+				}
+			}
+			return c.print(cmd, v, t)
+		},
+	}
+}
+
+func (c *cli) policyStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "status NAME...",
+		Short:             "Show the status of policies",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: c.completePolicies,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enclave, client, err := c.enclaveClient()
+			if err != nil {
+				return err
+			}
+
+			reqs := make([]*kms.PolicyRequest, 0, len(args))
+			for _, name := range args {
+				reqs = append(reqs, &kms.PolicyRequest{Name: name})
+			}
+			policies, err := client.PolicyStatus(cmd.Context(), enclave, reqs...)
+			if err != nil {
+				return err
+			}
+			return c.printPolicies(cmd, policies)
+		},
+	}
+}
+
+func (c *cli) policyDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "delete NAME...",
+		Short:             "Delete policies",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: c.completePolicies,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enclave, client, err := c.enclaveClient()
+			if err != nil {
+				return err
+			}
+			for _, name := range args {
+				if err = client.DeletePolicy(cmd.Context(), enclave, &kms.DeletePolicyRequest{Name: name}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func (c *cli) policyListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "ls [PREFIX]",
+		Aliases: []string{"list"},
+		Short:   "List policies",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enclave, client, err := c.enclaveClient()
+			if err != nil {
+				return err
+			}
+
+			req := &kms.ListRequest{Enclave: enclave}
+			if len(args) > 0 {
+				req.Prefix = args[0]
+			}
+			policies, err := (&kms.Iter[kms.PolicyStatusResponse]{NextFn: client.ListPolicies}).Collect(cmd.Context(), req)
+			if err != nil {
+				return err
+			}
+
+			list := make([]*kms.PolicyStatusResponse, 0, len(policies))
+			for i := range policies {
+				list = append(list, &policies[i])
+			}
+			return c.printPolicies(cmd, list)
+		},
+	}
+}
+
+func (c *cli) policyLintCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "lint FILE",
+		Short: "Check a policy for mistakes",
+		Long: `Check the policy within a JSON file for mistakes, like allow rules that
+are shadowed by deny rules. If the file is '-', the policy is read from
+standard input. The command fails if any error is found.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			b, err := readInput(cmd, args[0])
+			if err != nil {
+				return err
+			}
+			findings, err := policylint.CheckJSON(b)
+			if err != nil {
+				return fmt.Errorf("invalid policy: %v", err)
+			}
+
+			t := &table{Header: []string{"SEVERITY", "CHECK", "COMMAND", "PATTERN", "MESSAGE"}}
+			for _, f := range findings {
+				pattern := f.Pattern
+				if f.Deny {
+					pattern = "deny " + pattern
+				}
+				t.Add(f.Severity.String(), f.Check, f.Command, pattern, f.Message)
+			}
+			if findings == nil {
+				findings = []policylint.Finding{}
+			}
+			if err = c.print(cmd, findings, t); err != nil {
+				return err
+			}
+			if policylint.MaxSeverity(findings) >= policylint.Error {
+				return errors.New("policy contains errors")
+			}
+			return nil
+		},
+	}
+}
+
+func (c *cli) printPolicies(cmd *cobra.Command, policies []*kms.PolicyStatusResponse) error {
+	var (
+		v = make([]policyJSON, 0, len(policies))
+		t = &table{Header: []string{"NAME", "CREATED AT", "CREATED BY"}}
+	)
+	for _, p := range policies {
+		v = append(v, policyJSON{Name: p.Name, CreatedAt: p.CreatedAt, CreatedBy: p.CreatedBy})
+		t.Add(p.Name, formatTime(p.CreatedAt), formatIdentity(p.CreatedBy))
+	}
+	return c.print(cmd, v, t)
+}
+
+// addRules adds a row for every pattern in rules to t.
+func addRules(t *table, policy, effect string, rules map[cmds.Command]kms.RuleSet) error {
+	for _, cmd := range slices.Sorted(maps.Keys(rules)) {
+		set := rules[cmd]
+		for _, pattern := range slices.Sorted(maps.Keys(set)) {
+			conditions := "-"
+			if rule := set[pattern]; !rule.IsEmpty() {
+				b, err := json.Marshal(rule)
+				if err != nil {
+					return err
+				}
+				conditions = string(b)
+			}
+			t.Add(policy, effect, cmd.String(), pattern, conditions)
+		}
+	}
+	return nil
+}
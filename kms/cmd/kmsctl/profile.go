@@ -0,0 +1,139 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/minio/kms-go/kms"
+	"github.com/spf13/cobra"
+)
+
+func (c *cli) profileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Profile the performance of a KMS server",
+	}
+	cmd.AddCommand(
+		c.profileStartCmd(),
+		c.profileStatusCmd(),
+		c.profileStopCmd(),
+	)
+	return cmd
+}
+
+func (c *cli) profileStartCmd() *cobra.Command {
+	req := &kms.ProfileRequest{}
+	cmd := &cobra.Command{
+		Use:   "start",
+		Short: "Start profiling a KMS server",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := c.Client()
+			if err != nil {
+				return err
+			}
+			return client.StartProfiling(cmd.Context(), req)
+		},
+	}
+	cmd.Flags().StringVar(&req.Host, "host", "", "KMS server to profile")
+	cmd.Flags().BoolVar(&req.CPU, "cpu", false, "Enable CPU profiling")
+	cmd.Flags().BoolVar(&req.Heap, "heap", false, "Enable heap memory profiling")
+	cmd.Flags().BoolVar(&req.Goroutine, "goroutine", false, "Enable goroutine profiling")
+	cmd.Flags().BoolVar(&req.Thread, "thread", false, "Enable OS thread profiling")
+	cmd.Flags().IntVar(&req.BlockRate, "block-rate", 0, "Sample one blocking event per this many nanoseconds spent blocked")
+	cmd.Flags().IntVar(&req.MutexFraction, "mutex-fraction", 0, "Report 1/n of all mutex contention events")
+	return cmd
+}
+
+func (c *cli) profileStatusCmd() *cobra.Command {
+	var host string
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the status of an ongoing profiling",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := c.Client()
+			if err != nil {
+				return err
+			}
+			status, err := client.ProfilingStatus(cmd.Context(), &kms.ProfileRequest{Host: host})
+			if err != nil {
+				return err
+			}
+
+			type JSON struct {
+				Started       time.Time `json:"started"`
+				CPU           bool      `json:"cpu"`
+				Heap          bool      `json:"heap"`
+				Goroutine     bool      `json:"goroutine"`
+				Thread        bool      `json:"thread"`
+				BlockRate     int       `json:"block_rate"`
+				MutexFraction int       `json:"mutex_fraction"`
+			}
+			t := &table{Header: []string{"STARTED", "CPU", "HEAP", "GOROUTINE", "THREAD", "BLOCK RATE", "MUTEX FRACTION"}}
+			t.Add(
+				formatTime(status.Started),
+				strconv.FormatBool(status.CPU),
+				strconv.FormatBool(status.Heap),
+				strconv.FormatBool(status.Goroutine),
+				strconv.FormatBool(status.Thread),
+				strconv.Itoa(status.BlockRate),
+				strconv.Itoa(status.MutexFraction),
+			)
+			return c.print(cmd, JSON{
+				Started:       status.Started,
+				CPU:           status.CPU,
+				Heap:          status.Heap,
+				Goroutine:     status.Goroutine,
+				Thread:        status.Thread,
+				BlockRate:     status.BlockRate,
+				MutexFraction: status.MutexFraction,
+			}, t)
+		},
+	}
+	cmd.Flags().StringVar(&host, "host", "", "KMS server being profiled")
+	return cmd
+}
+
+func (c *cli) profileStopCmd() *cobra.Command {
+	var (
+		host     string
+		filename string
+	)
+	cmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop profiling and save the results",
+		Long: `Stop an ongoing profiling and write the results to a file. If the file
+is '-', the results are written to standard output.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := c.Client()
+			if err != nil {
+				return err
+			}
+			resp, err := client.StopProfiling(cmd.Context(), &kms.ProfileRequest{Host: host})
+			if err != nil {
+				return err
+			}
+			defer resp.Close()
+
+			f, err := createOutput(cmd, filename)
+			if err != nil {
+				return err
+			}
+			if _, err = io.Copy(f, resp); err != nil {
+				f.Close()
+				return err
+			}
+			return f.Close()
+		},
+	}
+	cmd.Flags().StringVar(&host, "host", "", "KMS server being profiled")
+	cmd.Flags().StringVarP(&filename, "file", "f", "profile.zip", "File to write the profiling results to")
+	return cmd
+}
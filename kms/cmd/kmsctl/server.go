@@ -0,0 +1,217 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/minio/kms-go/kms"
+	"github.com/spf13/cobra"
+)
+
+func (c *cli) serverCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "server",
+		Short: "Inspect individual KMS servers",
+	}
+	cmd.AddCommand(
+		c.serverVersionCmd(),
+		c.serverStatusCmd(),
+		c.serverAPIsCmd(),
+		c.serverLiveCmd(),
+		c.serverReadyCmd(),
+	)
+	return cmd
+}
+
+func (c *cli) serverVersionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version [HOST...]",
+		Short: "Show the version of KMS servers",
+		Long:  "Show the version of the given KMS servers or, if none is given, of all KMS servers.",
+		RunE: func(cmd *cobra.Command, hosts []string) error {
+			client, err := c.Client()
+			if err != nil {
+				return err
+			}
+			versions, err := client.Version(cmd.Context(), &kms.VersionRequest{Hosts: hosts})
+
+			type JSON struct {
+				Host       string `json:"host"`
+				Version    string `json:"version"`
+				Commit     string `json:"commit"`
+				APIVersion string `json:"api_version"`
+				FIPS140    bool   `json:"fips140"`
+			}
+			var (
+				v = make([]JSON, 0, len(versions))
+				t = &table{Header: []string{"HOST", "VERSION", "API", "COMMIT", "FIPS 140"}}
+			)
+			for _, r := range versions {
+				v = append(v, JSON{Host: r.Host, Version: r.Version, Commit: r.Commit, APIVersion: r.APIVersion, FIPS140: r.FIPS140})
+				t.Add(r.Host, r.Version, r.APIVersion, r.Commit, strconv.FormatBool(r.FIPS140))
+			}
+			if len(versions) > 0 {
+				if perr := c.print(cmd, v, t); err == nil {
+					err = perr
+				}
+			}
+			return err
+		},
+	}
+}
+
+func (c *cli) serverStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status [HOST...]",
+		Short: "Show the status of KMS servers",
+		Long:  "Show the status of the given KMS servers or, if none is given, of all KMS servers.",
+		RunE: func(cmd *cobra.Command, hosts []string) error {
+			client, err := c.Client()
+			if err != nil {
+				return err
+			}
+			status, err := client.ServerStatus(cmd.Context(), &kms.ServerStatusRequest{Hosts: hosts})
+
+			var (
+				v = make([]serverStatusJSON, 0, len(status))
+				t = &table{Header: []string{"HOST", "ID", "ROLE", "LEADER", "VERSION", "UPTIME", "HSMS"}}
+			)
+			for _, s := range status {
+				v = append(v, serverStatusToJSON(s))
+				t.Add(s.Host, strconv.Itoa(s.ID), s.Role, strconv.Itoa(s.LeaderID), s.Version, s.UpTime.Truncate(time.Second).String(), strconv.Itoa(len(s.HSMs)))
+			}
+			if len(status) > 0 {
+				if perr := c.print(cmd, v, t); err == nil {
+					err = perr
+				}
+			}
+			return err
+		},
+	}
+}
+
+func (c *cli) serverAPIsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "apis [HOST]",
+		Short: "List the APIs exposed by a KMS server",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var host string
+			if len(args) > 0 {
+				host = args[0]
+			}
+
+			client, err := c.Client()
+			if err != nil {
+				return err
+			}
+			apis, err := client.APIs(cmd.Context(), host)
+			if err != nil {
+				return err
+			}
+
+			type JSON struct {
+				Method  string        `json:"method"`
+				Path    string        `json:"path"`
+				MaxBody int64         `json:"max_body"`
+				Timeout time.Duration `json:"timeout"`
+			}
+			var (
+				v = make([]JSON, 0, len(apis))
+				t = &table{Header: []string{"METHOD", "PATH", "MAX BODY", "TIMEOUT"}}
+			)
+			for _, a := range apis {
+				v = append(v, JSON{Method: a.Method, Path: a.Path, MaxBody: int64(a.MaxBody), Timeout: a.Timeout})
+				t.Add(a.Method, a.Path, a.MaxBody.String(), a.Timeout.String())
+			}
+			return c.print(cmd, v, t)
+		},
+	}
+}
+
+func (c *cli) serverLiveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "live [HOST...]",
+		Short: "Check whether KMS servers are alive",
+		Long: `Check whether the given KMS servers or, if none is given, all KMS servers
+are alive. The command fails if any KMS server is not alive.`,
+		RunE: func(cmd *cobra.Command, hosts []string) error {
+			client, err := c.Client()
+			if err != nil {
+				return err
+			}
+			return client.Live(cmd.Context(), &kms.LivenessRequest{Hosts: hosts})
+		},
+	}
+}
+
+func (c *cli) serverReadyCmd() *cobra.Command {
+	var write bool
+	cmd := &cobra.Command{
+		Use:   "ready [HOST...]",
+		Short: "Check whether KMS servers are ready",
+		Long: `Check whether the given KMS servers or, if none is given, all KMS servers
+are ready to serve requests. The command fails if any KMS server is not ready.`,
+		RunE: func(cmd *cobra.Command, hosts []string) error {
+			client, err := c.Client()
+			if err != nil {
+				return err
+			}
+			return client.Ready(cmd.Context(), &kms.ReadinessRequest{Hosts: hosts, Write: write})
+		},
+	}
+	cmd.Flags().BoolVar(&write, "write", false, "Check whether the KMS servers are ready to serve write requests")
+	return cmd
+}
+
+type serverStatusJSON struct {
+	Host              string         `json:"host"`
+	ID                int            `json:"id"`
+	Role              string         `json:"role"`
+	LeaderID          int            `json:"leader_id"`
+	Commit            uint64         `json:"commit"`
+	Nodes             map[int]string `json:"nodes,omitempty"`
+	Version           string         `json:"version"`
+	APIVersion        string         `json:"api_version"`
+	UpTime            time.Duration  `json:"uptime"`
+	LastHeartbeat     time.Duration  `json:"last_heartbeat"`
+	HeartbeatInterval time.Duration  `json:"heartbeat_interval"`
+	ElectionTimeout   time.Duration  `json:"election_timeout"`
+	OS                string         `json:"os"`
+	CPUArch           string         `json:"cpu_arch"`
+	CPUs              uint           `json:"cpus"`
+	UsableCPUs        uint           `json:"usable_cpus"`
+	HeapMemInUse      uint64         `json:"heap_mem_in_use"`
+	StackMemInUse     uint64         `json:"stack_mem_in_use"`
+	HSMs              []string       `json:"hsms,omitempty"`
+	ConfiguredHSMs    []string       `json:"configured_hsms,omitempty"`
+}
+
+func serverStatusToJSON(s *kms.ServerStatusResponse) serverStatusJSON {
+	return serverStatusJSON{
+		Host:              s.Host,
+		ID:                s.ID,
+		Role:              s.Role,
+		LeaderID:          s.LeaderID,
+		Commit:            s.Commit,
+		Nodes:             s.Nodes,
+		Version:           s.Version,
+		APIVersion:        s.APIVersion,
+		UpTime:            s.UpTime,
+		LastHeartbeat:     s.LastHeartbeat,
+		HeartbeatInterval: s.HeartbeatInterval,
+		ElectionTimeout:   s.ElectionTimeout,
+		OS:                s.OS,
+		CPUArch:           s.CPUArch,
+		CPUs:              s.CPUs,
+		UsableCPUs:        s.UsableCPUs,
+		HeapMemInUse:      s.HeapMemInUse,
+		StackMemInUse:     s.StackMemInUse,
+		HSMs:              s.HSMs,
+		ConfiguredHSMs:    s.ConfiguredHSMs,
+	}
+}
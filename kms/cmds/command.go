@@ -47,6 +47,12 @@ const (
 	IdentityList   Command = 404
 )
 
+// Error is not a KMS command. Within a multi-command response,
+// it replaces the response of a command that failed and its
+// arguments are a pb.ErrResponse. KMS servers only send it when
+// a client asks for partial results.
+const Error Command = 0xFFFF
+
 // Parse parses s as a string representation of a Command.
 func Parse(s string) (Command, error) {
 	c, ok := textCmds[strings.ToUpper(s)]
@@ -0,0 +1,263 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package declarative_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"aead.dev/mtls"
+	"github.com/minio/kms-go/kms"
+	"github.com/minio/kms-go/kms/cmds"
+	"github.com/minio/kms-go/kms/declarative"
+	"github.com/minio/kms-go/kms/kmstest"
+)
+
+func TestParseSpec(t *testing.T) {
+	t.Parallel()
+
+	const (
+		YAML = `
+enclaves:
+  tenant-1:
+    keys:
+      my-key:
+        type: ChaCha20
+      other-key:
+    policies:
+      my-app:
+        allow:
+          KEY:ENCRYPT: my-key
+          KEY:DECRYPT:
+            my-key:
+              source_ips: ["10.0.0.0/8"]
+    identities:
+      h1:7ZD2uZl9wWLaCzG7Kb1pbx4hT4ZBMHxEv2mfU7Sdc3A:
+        policy: my-app
+        tags:
+          team: storage
+`
+		JSON = `{
+  "enclaves": {
+    "tenant-1": {
+      "keys": { "my-key": { "type": "ChaCha20" }, "other-key": null },
+      "policies": {
+        "my-app": {
+          "allow": {
+            "KEY:ENCRYPT": ["my-key"],
+            "KEY:DECRYPT": { "my-key": { "source_ips": ["10.0.0.0/8"] } }
+          }
+        }
+      },
+      "identities": {
+        "h1:7ZD2uZl9wWLaCzG7Kb1pbx4hT4ZBMHxEv2mfU7Sdc3A": {
+          "policy": "my-app",
+          "tags": { "team": "storage" }
+        }
+      }
+    }
+  }
+}`
+	)
+
+	a, err := declarative.ParseSpec([]byte(YAML))
+	if err != nil {
+		t.Fatalf("Failed to parse YAML spec: %v", err)
+	}
+	b, err := declarative.ParseSpec([]byte(JSON))
+	if err != nil {
+		t.Fatalf("Failed to parse JSON spec: %v", err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("Spec mismatch: got '%+v' - want '%+v'", a, b)
+	}
+	if typ := a.Enclaves["tenant-1"].Keys["my-key"].Type; typ != kms.ChaCha20 {
+		t.Fatalf("Invalid key type: got '%v' - want '%v'", typ, kms.ChaCha20)
+	}
+
+	for i, spec := range invalidSpecs {
+		if _, err := declarative.ParseSpec([]byte(spec)); err == nil {
+			t.Fatalf("Test %d: parsing invalid spec should have failed", i)
+		}
+	}
+}
+
+var invalidSpecs = []string{
+	`{"enclaves": {"tenant-1": {"keys": {"my-key": {"type": "AES128"}}}}}`,
+	`{"enclaves": {"tenant-1": {"identities": {"h1:7ZD2uZl9wWLaCzG7Kb1pbx4hT4ZBMHxEv2mfU7Sdc3A": {"policy": "x"}}}}}`,
+	`{"enclaves": {"tenant-1": {"identities": {"h1:7ZD2uZl9wWLaCzG7Kb1pbx4hT4ZBMHxEv2mfU7Sdc3A": {"privilege": "Root"}}}}}`,
+	`{"enclaves": {"tenant-1": {"policies": {"x": {"allow": {"KEY:RENAME": "*"}}}}}}`,
+	"enclaves:\n  tenant-1:\n    keys: [my-key]\n",
+}
+
+func TestPlan(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	const Enclave = "tenant-1"
+	var (
+		ctx    = context.Background()
+		client = srv.Client()
+		id1    = newIdentity(t)
+		id2    = newIdentity(t)
+		conf   = &declarative.Config{Client: client}
+	)
+	spec := &declarative.Spec{
+		Enclaves: map[string]*declarative.EnclaveSpec{
+			Enclave: {
+				Keys: map[string]*declarative.KeySpec{
+					"my-key":    {Type: kms.AES256},
+					"other-key": nil,
+				},
+				Policies: map[string]*declarative.PolicySpec{
+					"my-app": {
+						Allow: map[cmds.Command]kms.RuleSet{cmds.KeyEncrypt: {"my-key": {}}},
+					},
+				},
+				Identities: map[mtls.Identity]*declarative.IdentitySpec{
+					id1: {Policy: "my-app", Tags: map[string]string{"team": "storage"}},
+					id2: {Privilege: kms.Admin},
+				},
+			},
+		},
+	}
+
+	// Create all resources
+	plan, err := declarative.NewPlan(ctx, conf, spec)
+	if err != nil {
+		t.Fatalf("Failed to create plan: %v", err)
+	}
+	wantActions(t, plan, map[declarative.Action]int{declarative.Create: 6})
+	if !slices.Contains(plan.Unmanaged, declarative.Resource{Kind: declarative.KindEnclave, Enclave: kmstest.DefaultEnclave}) {
+		t.Fatalf("Invalid unmanaged resources: got '%v'", plan.Unmanaged)
+	}
+
+	dryRun := &declarative.Config{Client: client, DryRun: true}
+	if err = declarative.Apply(ctx, dryRun, plan); err != nil {
+		t.Fatalf("Failed to apply plan: %v", err)
+	}
+	if p, _ := declarative.NewPlan(ctx, conf, spec); len(p.Changes) != len(plan.Changes) {
+		t.Fatalf("Dry run changed state: got '%s'", p)
+	}
+
+	var applied int
+	conf.OnApply = func(c declarative.Change, err error) {
+		if err != nil {
+			t.Fatalf("Failed to apply '%v': %v", c, err)
+		}
+		applied++
+	}
+	if err = declarative.Apply(ctx, conf, plan); err != nil {
+		t.Fatalf("Failed to apply plan: %v", err)
+	}
+	if applied != len(plan.Changes) {
+		t.Fatalf("Invalid number of applied changes: got '%d' - want '%d'", applied, len(plan.Changes))
+	}
+	conf.OnApply = nil
+
+	if plan, err = declarative.NewPlan(ctx, conf, spec); err != nil {
+		t.Fatalf("Failed to create plan: %v", err)
+	}
+	wantActions(t, plan, nil)
+
+	resp, err := client.GetIdentity(ctx, Enclave, &kms.IdentityRequest{Identity: id1})
+	if err != nil {
+		t.Fatalf("Failed to get identity: %v", err)
+	}
+	if resp[0].Policy != "my-app" || resp[0].Privilege != kms.User || resp[0].Tags["team"] != "storage" {
+		t.Fatalf("Invalid identity: got '%+v'", resp[0])
+	}
+
+	// Detect drift
+	if err = client.CreatePolicy(ctx, Enclave, &kms.CreatePolicyRequest{
+		Name:  "my-app",
+		Allow: map[cmds.Command]kms.RuleSet{cmds.KeyEncrypt: {"*": {}}},
+	}); err != nil {
+		t.Fatalf("Failed to create policy: %v", err)
+	}
+	if err = client.CreatePolicy(ctx, Enclave, &kms.CreatePolicyRequest{Name: "unmanaged"}); err != nil {
+		t.Fatalf("Failed to create policy: %v", err)
+	}
+	if err = client.CreateIdentity(ctx, Enclave, &kms.CreateIdentityRequest{Identity: id2}); err != nil {
+		t.Fatalf("Failed to create identity: %v", err)
+	}
+	spec.Enclaves[Enclave].Keys["other-key"] = &declarative.KeySpec{Type: kms.ChaCha20}
+
+	conf.Prune = true
+	if plan, err = declarative.NewPlan(ctx, conf, spec); err != nil {
+		t.Fatalf("Failed to create plan: %v", err)
+	}
+	wantActions(t, plan, map[declarative.Action]int{
+		declarative.Update:   1,
+		declarative.Replace:  1,
+		declarative.Delete:   1,
+		declarative.Conflict: 1,
+	})
+	if s := plan.String(); !strings.Contains(s, `allow KEY:ENCRYPT: "*" => "my-key"`) || !strings.Contains(s, "privilege: User => Admin") {
+		t.Fatalf("Invalid plan: got '%s'", s)
+	}
+
+	// Replacing an identity must not delete it. Otherwise,
+	// its service accounts would be deleted as well.
+	var deleted bool
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(srv.Certificate())
+	conf.Client, err = kms.NewClient(&kms.Config{
+		Endpoints: []string{srv.Host()},
+		APIKey:    srv.APIKey,
+		TLS:       &tls.Config{RootCAs: rootCAs},
+		Interceptors: []kms.Interceptor{func(ctx context.Context, call *kms.Call, next kms.Invoker) (*http.Response, error) {
+			deleted = deleted || slices.Contains(call.Commands, cmds.IdentityDelete)
+			return next(ctx, call)
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if err = declarative.Apply(ctx, conf, plan); err != nil {
+		t.Fatalf("Failed to apply plan: %v", err)
+	}
+	if deleted {
+		t.Fatal("Applying the plan deleted an identity")
+	}
+	if plan, err = declarative.NewPlan(ctx, conf, spec); err != nil {
+		t.Fatalf("Failed to create plan: %v", err)
+	}
+	wantActions(t, plan, map[declarative.Action]int{declarative.Conflict: 1})
+}
+
+func wantActions(t *testing.T, plan *declarative.Plan, want map[declarative.Action]int) {
+	t.Helper()
+
+	got := map[declarative.Action]int{}
+	for _, c := range plan.Changes {
+		got[c.Action]++
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Invalid plan: got '%v' - want '%v':\n%s", got, want, plan)
+	}
+	for a, n := range want {
+		if got[a] != n {
+			t.Fatalf("Invalid plan: got '%v' - want '%v':\n%s", got, want, plan)
+		}
+	}
+}
+
+func newIdentity(t *testing.T) mtls.Identity {
+	key, err := mtls.GenerateKeyEdDSA(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate API key: %v", err)
+	}
+	return key.Identity()
+}
@@ -0,0 +1,612 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+// Package declarative reconciles the enclaves, keys, policies and
+// identities of a KMS cluster with a declarative Spec.
+//
+// NewPlan compares a Spec, usually parsed from a YAML or JSON file,
+// with the live state of a KMS cluster and returns a Plan. A Plan
+// contains the changes required to reach the state described by the
+// Spec and can be printed for review. Apply applies the changes of a
+// Plan.
+//
+// By default, resources that exist on the KMS cluster but are not
+// part of the Spec are reported as unmanaged but not deleted. Refer
+// to Config.Prune and Config.PruneKeys.
+package declarative
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"aead.dev/mtls"
+	"github.com/minio/kms-go/kms"
+	"github.com/minio/kms-go/kms/cmds"
+)
+
+// Config is a structure containing options for
+// planning and applying changes.
+type Config struct {
+	// Client is the KMS client used to fetch the live
+	// state and apply changes.
+	Client *kms.Client
+
+	// Prune controls whether policies and identities that
+	// are not part of the Spec get deleted. Policies and
+	// identities of enclaves that are not part of the Spec
+	// are not deleted.
+	Prune bool
+
+	// PruneKeys controls whether keys and enclaves that are
+	// not part of the Spec get deleted. Deleting a key or an
+	// enclave deletes key material irrecoverably. Hence, it
+	// is controlled separately from Prune.
+	PruneKeys bool
+
+	// Keep, if not nil, reports whether a resource that is not
+	// part of the Spec should be kept even if it would be pruned.
+	// For example, the identity used by the Client.
+	Keep func(Resource) bool
+
+	// DryRun controls whether Apply only reports the changes
+	// it would apply without changing any state.
+	DryRun bool
+
+	// OnApply, if not nil, is called by Apply after applying
+	// a change with the error, if any. If DryRun is true, it
+	// is called for every change with a nil error.
+	OnApply func(Change, error)
+}
+
+// Kind is the kind of a resource, like a key or a policy.
+type Kind int
+
+// All resource kinds.
+const (
+	KindEnclave Kind = iota + 1
+	KindKey
+	KindPolicy
+	KindIdentity
+)
+
+// String returns the string representation of the Kind.
+func (k Kind) String() string {
+	switch k {
+	case KindEnclave:
+		return "enclave"
+	case KindKey:
+		return "key"
+	case KindPolicy:
+		return "policy"
+	case KindIdentity:
+		return "identity"
+	default:
+		return "Kind(" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+// Resource identifies an enclave or a key, policy or
+// identity within an enclave.
+type Resource struct {
+	Kind    Kind   // The kind of resource
+	Enclave string // The enclave, or the enclave's name for enclaves
+	Name    string // The name of the key or policy, or the identity. Empty for enclaves
+}
+
+// String returns a string representation of the Resource.
+// For example: key "tenant-1/my-key".
+func (r Resource) String() string {
+	if r.Kind == KindEnclave {
+		return r.Kind.String() + " " + strconv.Quote(r.Enclave)
+	}
+	return r.Kind.String() + " " + strconv.Quote(r.Enclave+"/"+r.Name)
+}
+
+// Action is the action of a Change.
+type Action int
+
+// All actions.
+const (
+	// Create creates a resource that does not exist.
+	Create Action = iota + 1
+
+	// Update changes an existing resource. For example,
+	// by assigning another policy to an identity.
+	Update
+
+	// Replace overwrites an existing resource by creating
+	// it again since it cannot be changed otherwise. Only
+	// identities are replaced. Replacing an identity does
+	// not delete it and, therefore, not its service accounts.
+	Replace
+
+	// Delete deletes a resource that is not part of the
+	// Spec. Refer to Config.Prune.
+	Delete
+
+	// Conflict indicates that a resource differs from the
+	// Spec but cannot be changed without losing data. For
+	// example, a key with another type than specified.
+	// Apply skips conflicts.
+	Conflict
+)
+
+// String returns the string representation of the Action.
+func (a Action) String() string {
+	switch a {
+	case Create:
+		return "create"
+	case Update:
+		return "update"
+	case Replace:
+		return "replace"
+	case Delete:
+		return "delete"
+	case Conflict:
+		return "conflict"
+	default:
+		return "Action(" + strconv.Itoa(int(a)) + ")"
+	}
+}
+
+// symbol returns a short symbol for the action.
+func (a Action) symbol() string {
+	switch a {
+	case Create:
+		return "+"
+	case Update:
+		return "~"
+	case Replace:
+		return "-/+"
+	case Delete:
+		return "-"
+	default:
+		return "!"
+	}
+}
+
+// Change is a change of a single resource.
+type Change struct {
+	Action   Action
+	Resource Resource
+
+	// Details describes how the live state of the resource
+	// differs from the Spec. For example: "privilege: User => Admin"
+	Details []string
+
+	apply func(context.Context, *kms.Client) error
+}
+
+// String returns a human-readable representation of the Change.
+func (c Change) String() string {
+	var b strings.Builder
+	b.WriteString(c.Action.symbol())
+	b.WriteByte(' ')
+	b.WriteString(c.Action.String())
+	b.WriteByte(' ')
+	b.WriteString(c.Resource.String())
+	for _, d := range c.Details {
+		b.WriteString("\n    ")
+		b.WriteString(d)
+	}
+	return b.String()
+}
+
+// Plan is a list of changes that, once applied, change the live
+// state of a KMS cluster to the state described by a Spec.
+type Plan struct {
+	// Changes is the list of changes in the order in which
+	// they are applied.
+	Changes []Change
+
+	// Unmanaged is the list of resources that are not part
+	// of the Spec but are not deleted by the Plan.
+	Unmanaged []Resource
+}
+
+// Drifted reports whether the live state differs from the Spec,
+// either since resources of the Spec are missing or differ or
+// since resources not part of the Spec exist.
+func (p *Plan) Drifted() bool { return len(p.Changes) > 0 || len(p.Unmanaged) > 0 }
+
+// String returns a human-readable representation of the Plan.
+func (p *Plan) String() string {
+	if !p.Drifted() {
+		return "No changes. The live state matches the spec."
+	}
+
+	var (
+		b     strings.Builder
+		count = map[Action]int{}
+	)
+	for _, c := range p.Changes {
+		b.WriteString(c.String())
+		b.WriteByte('\n')
+		count[c.Action]++
+	}
+	for _, r := range p.Unmanaged {
+		b.WriteString("? unmanaged ")
+		b.WriteString(r.String())
+		b.WriteByte('\n')
+	}
+	fmt.Fprintf(&b, "\nPlan: %d to create, %d to update, %d to replace, %d to delete, %d conflicts, %d unmanaged.",
+		count[Create], count[Update], count[Replace], count[Delete], count[Conflict], len(p.Unmanaged))
+	return b.String()
+}
+
+// NewPlan fetches the live state using conf.Client and returns
+// a Plan containing the changes required to reach the state
+// described by the spec.
+func NewPlan(ctx context.Context, conf *Config, spec *Spec) (*Plan, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	enclaves, err := (&kms.Iter[kms.EnclaveStatusResponse]{NextFn: conf.Client.ListEnclaves}).Collect(ctx, &kms.ListRequest{})
+	if err != nil {
+		return nil, err
+	}
+	live := make(map[string]bool, len(enclaves))
+	for _, e := range enclaves {
+		live[e.Name] = true
+	}
+
+	p := &planner{conf: conf}
+	for _, name := range slices.Sorted(maps.Keys(spec.Enclaves)) {
+		e := spec.Enclaves[name]
+		if e == nil {
+			e = &EnclaveSpec{}
+		}
+
+		if !live[name] {
+			p.createEnclave(name, e)
+			continue
+		}
+		if err = p.diffEnclave(ctx, name, e); err != nil {
+			return nil, err
+		}
+	}
+	for _, e := range enclaves {
+		if _, ok := spec.Enclaves[e.Name]; !ok {
+			p.unmanaged(Resource{Kind: KindEnclave, Enclave: e.Name}, conf.PruneKeys, func(ctx context.Context, c *kms.Client) error {
+				return c.DeleteEnclave(ctx, &kms.DeleteEnclaveRequest{Name: e.Name})
+			})
+		}
+	}
+
+	slices.SortStableFunc(p.plan.Changes, func(a, b Change) int { return phase(a) - phase(b) })
+	return &p.plan, nil
+}
+
+// Apply applies the changes of the plan in order. It stops
+// and returns an error once a change cannot be applied.
+// If conf.DryRun is true, Apply does not change any state.
+//
+// Changes with the Conflict action are skipped.
+func Apply(ctx context.Context, conf *Config, plan *Plan) error {
+	for _, c := range plan.Changes {
+		if c.Action == Conflict {
+			continue
+		}
+
+		var err error
+		if !conf.DryRun {
+			err = c.apply(ctx, conf.Client)
+		}
+		if conf.OnApply != nil {
+			conf.OnApply(c, err)
+		}
+		if err != nil {
+			return fmt.Errorf("declarative: failed to %s %s: %w", c.Action, c.Resource, err)
+		}
+	}
+	return nil
+}
+
+// phase returns the phase in which a change is applied.
+// Resources are created before resources depending on
+// them and deleted in reverse order.
+func phase(c Change) int {
+	switch c.Action {
+	case Delete:
+		return 10 - int(c.Resource.Kind)
+	case Conflict:
+		return 20
+	default:
+		return int(c.Resource.Kind)
+	}
+}
+
+// planner builds a Plan.
+type planner struct {
+	conf *Config
+	plan Plan
+}
+
+func (p *planner) add(action Action, r Resource, details []string, apply func(context.Context, *kms.Client) error) {
+	p.plan.Changes = append(p.plan.Changes, Change{
+		Action:   action,
+		Resource: r,
+		Details:  details,
+		apply:    apply,
+	})
+}
+
+// unmanaged adds a Delete change for r if prune is true and
+// r should not be kept. Otherwise, it marks r as unmanaged.
+func (p *planner) unmanaged(r Resource, prune bool, apply func(context.Context, *kms.Client) error) {
+	if prune && (p.conf.Keep == nil || !p.conf.Keep(r)) {
+		p.add(Delete, r, nil, apply)
+		return
+	}
+	p.plan.Unmanaged = append(p.plan.Unmanaged, r)
+}
+
+// createEnclave adds changes creating the enclave
+// and all its resources.
+func (p *planner) createEnclave(name string, e *EnclaveSpec) {
+	p.add(Create, Resource{Kind: KindEnclave, Enclave: name}, nil, func(ctx context.Context, c *kms.Client) error {
+		return c.CreateEnclave(ctx, &kms.CreateEnclaveRequest{Name: name})
+	})
+	for _, key := range slices.Sorted(maps.Keys(e.Keys)) {
+		p.createKey(name, key, e.Keys[key])
+	}
+	for _, policy := range slices.Sorted(maps.Keys(e.Policies)) {
+		p.createPolicy(name, policy, e.Policies[policy], Create, nil)
+	}
+	for _, id := range sortedIdentities(e.Identities) {
+		p.createIdentity(name, id, e.Identities[id], Create, nil)
+	}
+}
+
+// diffEnclave adds changes for all resources of an
+// existing enclave that differ from the EnclaveSpec.
+func (p *planner) diffEnclave(ctx context.Context, enclave string, e *EnclaveSpec) error {
+	client := p.conf.Client
+
+	keys, err := (&kms.Iter[kms.KeyStatusResponse]{NextFn: client.ListKeys}).Collect(ctx, &kms.ListRequest{Enclave: enclave})
+	if err != nil {
+		return err
+	}
+	liveKeys := make(map[string]kms.SecretKeyType, len(keys))
+	for _, k := range keys {
+		liveKeys[k.Name] = k.Type
+	}
+	for _, name := range slices.Sorted(maps.Keys(e.Keys)) {
+		spec := e.Keys[name]
+		t, ok := liveKeys[name]
+		switch {
+		case !ok:
+			p.createKey(enclave, name, spec)
+		case spec != nil && spec.Type != 0 && spec.Type != t:
+			p.add(Conflict, Resource{Kind: KindKey, Enclave: enclave, Name: name}, []string{
+				"type: " + t.String() + " => " + spec.Type.String() + " (the type of a key cannot be changed)",
+			}, nil)
+		}
+	}
+	for _, k := range keys {
+		if _, ok := e.Keys[k.Name]; !ok {
+			p.unmanaged(Resource{Kind: KindKey, Enclave: enclave, Name: k.Name}, p.conf.PruneKeys, func(ctx context.Context, c *kms.Client) error {
+				return c.DeleteKey(ctx, enclave, &kms.DeleteKeyRequest{Name: k.Name, AllVersions: true})
+			})
+		}
+	}
+
+	policies, err := (&kms.Iter[kms.PolicyStatusResponse]{NextFn: client.ListPolicies}).Collect(ctx, &kms.ListRequest{Enclave: enclave})
+	if err != nil {
+		return err
+	}
+	var reqs []*kms.PolicyRequest
+	for _, policy := range policies {
+		if _, ok := e.Policies[policy.Name]; ok {
+			reqs = append(reqs, &kms.PolicyRequest{Name: policy.Name})
+			continue
+		}
+		p.unmanaged(Resource{Kind: KindPolicy, Enclave: enclave, Name: policy.Name}, p.conf.Prune, func(ctx context.Context, c *kms.Client) error {
+			return c.DeletePolicy(ctx, enclave, &kms.DeletePolicyRequest{Name: policy.Name})
+		})
+	}
+	livePolicies := make(map[string]*kms.PolicyResponse, len(reqs))
+	if len(reqs) > 0 {
+		resps, err := client.GetPolicy(ctx, enclave, reqs...)
+		if err != nil {
+			return err
+		}
+		for _, resp := range resps {
+			livePolicies[resp.Name] = resp
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(e.Policies)) {
+		spec := e.Policies[name]
+		live, ok := livePolicies[name]
+		if !ok {
+			p.createPolicy(enclave, name, spec, Create, nil)
+			continue
+		}
+		if details := diffPolicy(live, spec); len(details) > 0 {
+			p.createPolicy(enclave, name, spec, Update, details)
+		}
+	}
+
+	identities, err := (&kms.Iter[kms.IdentityResponse]{NextFn: client.ListIdentities}).Collect(ctx, &kms.ListRequest{Enclave: enclave})
+	if err != nil {
+		return err
+	}
+	var idReqs []*kms.IdentityRequest
+	for _, id := range identities {
+		if _, ok := e.Identities[id.Identity]; ok {
+			idReqs = append(idReqs, &kms.IdentityRequest{Identity: id.Identity})
+			continue
+		}
+		p.unmanaged(Resource{Kind: KindIdentity, Enclave: enclave, Name: id.Identity.String()}, p.conf.Prune, func(ctx context.Context, c *kms.Client) error {
+			return c.DeleteIdentity(ctx, enclave, &kms.DeleteIdentityRequest{Identity: id.Identity})
+		})
+	}
+	liveIdentities := make(map[mtls.Identity]*kms.IdentityResponse, len(idReqs))
+	if len(idReqs) > 0 {
+		resps, err := client.GetIdentity(ctx, enclave, idReqs...)
+		if err != nil {
+			return err
+		}
+		for _, resp := range resps {
+			liveIdentities[resp.Identity] = resp
+		}
+	}
+	for _, id := range sortedIdentities(e.Identities) {
+		spec := e.Identities[id]
+		if spec == nil {
+			spec = &IdentitySpec{}
+		}
+
+		live, ok := liveIdentities[id]
+		if !ok {
+			p.createIdentity(enclave, id, spec, Create, nil)
+			continue
+		}
+
+		details := diffIdentity(live, spec)
+		switch {
+		case len(details) == 0:
+		case len(details) == 1 && live.Policy != spec.Policy && spec.Policy != "":
+			p.add(Update, Resource{Kind: KindIdentity, Enclave: enclave, Name: id.String()}, details, func(ctx context.Context, c *kms.Client) error {
+				return c.AssignPolicy(ctx, enclave, &kms.AssignPolicyRequest{Policy: spec.Policy, Identity: id})
+			})
+		default:
+			p.createIdentity(enclave, id, spec, Replace, details)
+		}
+	}
+	return nil
+}
+
+func (p *planner) createKey(enclave, name string, spec *KeySpec) {
+	var t kms.SecretKeyType
+	if spec != nil {
+		t = spec.Type
+	}
+
+	var details []string
+	if t != 0 {
+		details = []string{"type: " + t.String()}
+	}
+	p.add(Create, Resource{Kind: KindKey, Enclave: enclave, Name: name}, details, func(ctx context.Context, c *kms.Client) error {
+		return c.CreateKey(ctx, enclave, &kms.CreateKeyRequest{Name: name, Type: t})
+	})
+}
+
+func (p *planner) createPolicy(enclave, name string, spec *PolicySpec, action Action, details []string) {
+	if spec == nil {
+		spec = &PolicySpec{}
+	}
+	p.add(action, Resource{Kind: KindPolicy, Enclave: enclave, Name: name}, details, func(ctx context.Context, c *kms.Client) error {
+		return c.CreatePolicy(ctx, enclave, &kms.CreatePolicyRequest{
+			Name:  name,
+			Allow: spec.Allow,
+			Deny:  spec.Deny,
+		})
+	})
+}
+
+func (p *planner) createIdentity(enclave string, id mtls.Identity, spec *IdentitySpec, action Action, details []string) {
+	if spec == nil {
+		spec = &IdentitySpec{}
+	}
+	if action == Create {
+		details = []string{"privilege: " + spec.privilege().String()}
+		if spec.Policy != "" {
+			details = append(details, "policy: "+spec.Policy)
+		}
+	}
+
+	p.add(action, Resource{Kind: KindIdentity, Enclave: enclave, Name: id.String()}, details, func(ctx context.Context, c *kms.Client) error {
+		err := c.CreateIdentity(ctx, enclave, &kms.CreateIdentityRequest{
+			Identity:         id,
+			Privilege:        spec.Privilege,
+			IsServiceAccount: spec.IsServiceAccount,
+			Tags:             spec.Tags,
+		})
+		if err != nil {
+			return err
+		}
+		if spec.Policy != "" {
+			return c.AssignPolicy(ctx, enclave, &kms.AssignPolicyRequest{Policy: spec.Policy, Identity: id})
+		}
+		return nil
+	})
+}
+
+// diffPolicy returns a description of how the live
+// policy differs from the spec, if at all.
+func diffPolicy(live *kms.PolicyResponse, spec *PolicySpec) []string {
+	if spec == nil {
+		spec = &PolicySpec{}
+	}
+
+	a := &kms.Policy{Allow: nonEmpty(live.Allow), Deny: nonEmpty(live.Deny)}
+	b := &kms.Policy{Allow: nonEmpty(spec.Allow), Deny: nonEmpty(spec.Deny)}
+	if reflect.DeepEqual(a, b) || a.IsEquivalent(b) {
+		return nil
+	}
+
+	var details []string
+	for _, d := range []struct {
+		Name       string
+		Live, Spec map[cmds.Command]kms.RuleSet
+	}{{"allow", a.Allow, b.Allow}, {"deny", a.Deny, b.Deny}} {
+		commands := slices.Concat(slices.Collect(maps.Keys(d.Live)), slices.Collect(maps.Keys(d.Spec)))
+		slices.SortFunc(commands, func(x, y cmds.Command) int { return strings.Compare(x.String(), y.String()) })
+		for _, cmd := range slices.Compact(commands) {
+			from, _ := json.Marshal(d.Live[cmd])
+			to, _ := json.Marshal(d.Spec[cmd])
+			if string(from) != string(to) {
+				details = append(details, d.Name+" "+cmd.String()+": "+string(from)+" => "+string(to))
+			}
+		}
+	}
+	return details
+}
+
+// diffIdentity returns a description of how the live
+// identity differs from the spec, if at all.
+func diffIdentity(live *kms.IdentityResponse, spec *IdentitySpec) []string {
+	var details []string
+	if p := spec.privilege(); live.Privilege != p {
+		details = append(details, "privilege: "+live.Privilege.String()+" => "+p.String())
+	}
+	if live.IsServiceAccount != spec.IsServiceAccount {
+		details = append(details, "service_account: "+strconv.FormatBool(live.IsServiceAccount)+" => "+strconv.FormatBool(spec.IsServiceAccount))
+	}
+	if !maps.Equal(live.Tags, spec.Tags) {
+		from, _ := json.Marshal(live.Tags)
+		to, _ := json.Marshal(spec.Tags)
+		details = append(details, "tags: "+string(from)+" => "+string(to))
+	}
+	if live.Policy != spec.Policy {
+		details = append(details, "policy: "+strconv.Quote(live.Policy)+" => "+strconv.Quote(spec.Policy))
+	}
+	return details
+}
+
+// nonEmpty returns a copy of the RuleSets without empty RuleSets.
+func nonEmpty(sets map[cmds.Command]kms.RuleSet) map[cmds.Command]kms.RuleSet {
+	m := make(map[cmds.Command]kms.RuleSet, len(sets))
+	for cmd, set := range sets {
+		if len(set) > 0 {
+			m[cmd] = set
+		}
+	}
+	return m
+}
+
+// sortedIdentities returns the identities in ascending order.
+func sortedIdentities(ids map[mtls.Identity]*IdentitySpec) []mtls.Identity {
+	s := slices.Collect(maps.Keys(ids))
+	slices.SortFunc(s, func(a, b mtls.Identity) int { return strings.Compare(a.String(), b.String()) })
+	return s
+}
@@ -0,0 +1,277 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package declarative
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"aead.dev/mtls"
+	"github.com/minio/kms-go/kms"
+	"github.com/minio/kms-go/kms/cmds"
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is the desired state of enclaves and their keys,
+// policies and identities.
+//
+// A Spec is usually parsed from its YAML or JSON representation.
+// For example:
+//
+//	enclaves:
+//	  tenant-1:
+//	    keys:
+//	      my-key:
+//	        type: AES256
+//	    policies:
+//	      my-app:
+//	        allow:
+//	          KEY:ENCRYPT: my-key
+//	          KEY:DECRYPT: my-key
+//	    identities:
+//	      h1:7ZD2uZl9wWLaCzG7Kb1pbx4hT4ZBMHxEv2mfU7Sdc3A:
+//	        privilege: User
+//	        policy: my-app
+//	        tags:
+//	          team: storage
+//
+// Enclaves, keys and policies are identified by their name and
+// identities by their identity.
+type Spec struct {
+	Enclaves map[string]*EnclaveSpec `json:"enclaves,omitempty"`
+}
+
+// EnclaveSpec is the desired state of an enclave.
+type EnclaveSpec struct {
+	Keys       map[string]*KeySpec             `json:"keys,omitempty"`
+	Policies   map[string]*PolicySpec          `json:"policies,omitempty"`
+	Identities map[mtls.Identity]*IdentitySpec `json:"identities,omitempty"`
+}
+
+// KeySpec is the desired state of a key.
+type KeySpec struct {
+	// Type is the key's type. If zero, the KMS server
+	// chooses the type when creating the key and any
+	// type is accepted for existing keys.
+	Type kms.SecretKeyType
+}
+
+// MarshalJSON returns the KeySpec's JSON representation.
+func (s *KeySpec) MarshalJSON() ([]byte, error) {
+	type JSON struct {
+		Type string `json:"type,omitempty"`
+	}
+	var v JSON
+	if s.Type != 0 {
+		v.Type = s.Type.String()
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON initializes the KeySpec from its JSON representation.
+func (s *KeySpec) UnmarshalJSON(b []byte) error {
+	type JSON struct {
+		Type string `json:"type"`
+	}
+	var v JSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	var t kms.SecretKeyType
+	if v.Type != "" {
+		var err error
+		if t, err = kms.ParseSecretKeyType(v.Type); err != nil {
+			return err
+		}
+	}
+	s.Type = t
+	return nil
+}
+
+// PolicySpec is the desired state of a policy.
+type PolicySpec struct {
+	Allow map[cmds.Command]kms.RuleSet `json:"allow,omitempty"`
+	Deny  map[cmds.Command]kms.RuleSet `json:"deny,omitempty"`
+}
+
+// IdentitySpec is the desired state of an identity.
+type IdentitySpec struct {
+	// Privilege is the identity's privilege. If zero,
+	// defaults to kms.User.
+	Privilege kms.Privilege
+
+	// Policy is the name of the policy assigned to the
+	// identity, if any. It must refer to a policy of the
+	// same EnclaveSpec.
+	Policy string
+
+	// IsServiceAccount indicates whether the identity
+	// is a service account.
+	IsServiceAccount bool
+
+	// Tags are optional metadata labels attached to
+	// the identity.
+	Tags map[string]string
+}
+
+// MarshalJSON returns the IdentitySpec's JSON representation.
+func (s *IdentitySpec) MarshalJSON() ([]byte, error) {
+	type JSON struct {
+		Privilege        string            `json:"privilege,omitempty"`
+		Policy           string            `json:"policy,omitempty"`
+		IsServiceAccount bool              `json:"service_account,omitempty"`
+		Tags             map[string]string `json:"tags,omitempty"`
+	}
+	v := JSON{
+		Policy:           s.Policy,
+		IsServiceAccount: s.IsServiceAccount,
+		Tags:             s.Tags,
+	}
+	if s.Privilege != 0 {
+		v.Privilege = s.Privilege.String()
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON initializes the IdentitySpec from its JSON representation.
+func (s *IdentitySpec) UnmarshalJSON(b []byte) error {
+	type JSON struct {
+		Privilege        string            `json:"privilege"`
+		Policy           string            `json:"policy"`
+		IsServiceAccount bool              `json:"service_account"`
+		Tags             map[string]string `json:"tags"`
+	}
+	var v JSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	var privilege kms.Privilege
+	if v.Privilege != "" {
+		var err error
+		if privilege, err = kms.ParsePrivilege(v.Privilege); err != nil {
+			return err
+		}
+	}
+	s.Privilege = privilege
+	s.Policy = v.Policy
+	s.IsServiceAccount = v.IsServiceAccount
+	s.Tags = v.Tags
+	return nil
+}
+
+// privilege returns the identity's privilege or kms.User
+// if no privilege is specified.
+func (s *IdentitySpec) privilege() kms.Privilege {
+	if s.Privilege == 0 {
+		return kms.User
+	}
+	return s.Privilege
+}
+
+// ParseSpec parses b as YAML or JSON representation
+// of a Spec and validates it.
+func ParseSpec(b []byte) (*Spec, error) {
+	if !json.Valid(b) {
+		// Convert YAML to JSON such that YAML and JSON specs
+		// are decoded the same way. For example, RuleSets and
+		// kms.Rule conditions implement custom JSON decoding.
+		var v any
+		if err := yaml.Unmarshal(b, &v); err != nil {
+			return nil, err
+		}
+
+		var err error
+		if b, err = json.Marshal(jsonValue(v)); err != nil {
+			return nil, err
+		}
+	}
+
+	var spec Spec
+	if err := json.Unmarshal(b, &spec); err != nil {
+		return nil, err
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// Validate returns an error if the Spec is invalid. For
+// example, if an identity refers to a policy that is not
+// part of the Spec.
+func (s *Spec) Validate() error {
+	for _, name := range slices.Sorted(maps.Keys(s.Enclaves)) {
+		if name == "" {
+			return errors.New("declarative: invalid spec: empty enclave name")
+		}
+		e := s.Enclaves[name]
+		if e == nil {
+			continue
+		}
+
+		for key := range e.Keys {
+			if key == "" {
+				return errors.New("declarative: invalid spec: empty key name in enclave '" + name + "'")
+			}
+		}
+		for policy, p := range e.Policies {
+			if policy == "" {
+				return errors.New("declarative: invalid spec: empty policy name in enclave '" + name + "'")
+			}
+			if p == nil {
+				continue
+			}
+			for _, set := range slices.Concat(slices.Collect(maps.Values(p.Allow)), slices.Collect(maps.Values(p.Deny))) {
+				for _, rule := range set {
+					if err := rule.Validate(); err != nil {
+						return errors.New("declarative: invalid spec: policy '" + policy + "' in enclave '" + name + "': " + err.Error())
+					}
+				}
+			}
+		}
+		for id, i := range e.Identities {
+			if id.IsZero() {
+				return errors.New("declarative: invalid spec: empty identity in enclave '" + name + "'")
+			}
+			if i == nil || i.Policy == "" {
+				continue
+			}
+			if _, ok := e.Policies[i.Policy]; !ok {
+				return errors.New("declarative: invalid spec: identity '" + id.String() + "' in enclave '" + name + "' refers to unknown policy '" + i.Policy + "'")
+			}
+		}
+	}
+	return nil
+}
+
+// jsonValue converts YAML mappings with non-string keys,
+// like numbers, within v to JSON objects.
+func jsonValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			v[k] = jsonValue(e)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = jsonValue(e)
+		}
+		return m
+	case []any:
+		for i, e := range v {
+			v[i] = jsonValue(e)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+// Package envelope implements streaming envelope encryption
+// on top of a KMS server.
+//
+// An EncryptWriter generates a unique data encryption key (DEK)
+// using a KMS master key and encrypts everything written to it
+// with this DEK. The DEK ciphertext, the name and version of the
+// master key and its enclave are stored in a header in front of
+// the encrypted data. Hence, a DecryptReader only needs the
+// encrypted stream and the associated data to decrypt it again.
+//
+// The plaintext is split into chunks of 64 KiB. Each chunk is
+// encrypted and authenticated separately using either AES-256-GCM
+// or ChaCha20-Poly1305, depending on the type of the master key.
+// Chunks cannot be reordered, removed or truncated without being
+// detected.
+//
+// An encrypted stream has the following format:
+//
+//	Header:
+//	  version      uint8    (1)
+//	  cipher       uint8    (kms.SecretKeyType)
+//	  key version  uint32
+//	  enclave      uint16 length + bytes
+//	  key name     uint16 length + bytes
+//	  wrapped DEK  uint16 length + bytes
+//	Chunks:
+//	  ciphertext || tag   (64 KiB + 16 bytes, last chunk shorter)
+//
+// All integers are encoded in big endian.
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/minio/kms-go/kms"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+var (
+	// ErrInvalidHeader is returned by NewDecryptReader when the
+	// stream does not start with a valid envelope header.
+	ErrInvalidHeader = errors.New("envelope: invalid header")
+
+	// ErrDecrypt is returned by a DecryptReader when the encrypted
+	// stream has been modified or truncated.
+	ErrDecrypt = errors.New("envelope: ciphertext is not authentic")
+)
+
+const (
+	version1  = 1       // The current header version
+	chunkSize = 1 << 16 // The size of a plaintext chunk
+	tagSize   = 16      // The size of an AEAD authentication tag
+	nonceSize = 12      // The size of an AEAD nonce
+	dekSize   = 256 / 8 // The size of a data encryption key
+)
+
+// header is the header of an encrypted stream.
+type header struct {
+	Cipher     kms.SecretKeyType
+	KeyVersion uint32
+	Enclave    string
+	KeyName    string
+	DEK        []byte // The encrypted data encryption key
+}
+
+// MarshalBinary returns the binary representation of the header.
+func (h *header) MarshalBinary() ([]byte, error) {
+	if len(h.Enclave) > math.MaxUint16 || len(h.KeyName) > math.MaxUint16 || len(h.DEK) > math.MaxUint16 {
+		return nil, errors.New("envelope: header field is too large")
+	}
+
+	b := make([]byte, 0, 6+2+len(h.Enclave)+2+len(h.KeyName)+2+len(h.DEK))
+	b = append(b, version1, byte(h.Cipher))
+	b = binary.BigEndian.AppendUint32(b, h.KeyVersion)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(h.Enclave)))
+	b = append(b, h.Enclave...)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(h.KeyName)))
+	b = append(b, h.KeyName...)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(h.DEK)))
+	b = append(b, h.DEK...)
+	return b, nil
+}
+
+// readHeader reads and parses a header from r. It returns
+// the parsed header and its binary representation.
+func readHeader(r io.Reader) (*header, []byte, error) {
+	b := make([]byte, 6, 64)
+	if _, err := io.ReadFull(r, b); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, nil, ErrInvalidHeader
+		}
+		return nil, nil, err
+	}
+	if b[0] != version1 {
+		return nil, nil, ErrInvalidHeader
+	}
+
+	h := &header{
+		Cipher:     kms.SecretKeyType(b[1]),
+		KeyVersion: binary.BigEndian.Uint32(b[2:]),
+	}
+	if h.Cipher != kms.AES256 && h.Cipher != kms.ChaCha20 {
+		return nil, nil, ErrInvalidHeader
+	}
+
+	var fields [3][]byte
+	for i := range fields {
+		n := len(b)
+		b = append(b, 0, 0)
+		if _, err := io.ReadFull(r, b[n:]); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil, nil, ErrInvalidHeader
+			}
+			return nil, nil, err
+		}
+		size := int(binary.BigEndian.Uint16(b[n:]))
+
+		n = len(b)
+		b = append(b, make([]byte, size)...)
+		if _, err := io.ReadFull(r, b[n:]); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil, nil, ErrInvalidHeader
+			}
+			return nil, nil, err
+		}
+		fields[i] = b[n:]
+	}
+	h.Enclave = string(fields[0])
+	h.KeyName = string(fields[1])
+	h.DEK = fields[2]
+	return h, b, nil
+}
+
+// newAEAD returns a new AEAD for the given cipher and key.
+func newAEAD(c kms.SecretKeyType, key []byte) (cipher.AEAD, error) {
+	switch c {
+	case kms.AES256:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case kms.ChaCha20:
+		return chacha20poly1305.New(key)
+	default:
+		return nil, ErrInvalidHeader
+	}
+}
+
+// headerHash returns the SHA-256 hash of the binary header.
+// It is used as associated data for every chunk such that
+// the header cannot be modified without being detected.
+func headerHash(b []byte) []byte {
+	h := sha256.Sum256(b)
+	return h[:]
+}
+
+// chunkNonce returns the nonce for the chunk with the given
+// sequence number. The last byte marks the final chunk. Each
+// stream uses a unique DEK. Hence, a counter is a safe nonce.
+func chunkNonce(nonce *[nonceSize]byte, seqNum uint64, final bool) []byte {
+	binary.BigEndian.PutUint64(nonce[:], seqNum)
+	nonce[nonceSize-1] = 0
+	if final {
+		nonce[nonceSize-1] = 1
+	}
+	return nonce[:]
+}
@@ -0,0 +1,134 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package envelope_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/minio/kms-go/kms"
+	"github.com/minio/kms-go/kms/envelope"
+	"github.com/minio/kms-go/kms/kmstest"
+)
+
+var roundtripTests = []struct {
+	Type kms.SecretKeyType
+	Size int
+}{
+	{Type: kms.AES256, Size: 0},
+	{Type: kms.AES256, Size: 1},
+	{Type: kms.AES256, Size: 1 << 16},
+	{Type: kms.AES256, Size: 3*(1<<16) + 17},
+	{Type: kms.ChaCha20, Size: 0},
+	{Type: kms.ChaCha20, Size: 1<<16 - 1},
+	{Type: kms.ChaCha20, Size: 2 * (1 << 16)},
+}
+
+func TestRoundtrip(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := srv.Client()
+	associatedData := []byte("my-object")
+	for i, test := range roundtripTests {
+		keyName := "key-" + test.Type.String()
+		err := client.CreateKey(ctx, kmstest.DefaultEnclave, &kms.CreateKeyRequest{Name: keyName, Type: test.Type})
+		if err != nil && !errors.Is(err, kms.ErrKeyExists) {
+			t.Fatalf("Test %d: failed to create key: %v", i, err)
+		}
+
+		plaintext := make([]byte, test.Size)
+		for j := range plaintext {
+			plaintext[j] = byte(j)
+		}
+
+		var ciphertext bytes.Buffer
+		w, err := envelope.NewEncryptWriter(ctx, client, kmstest.DefaultEnclave, keyName, associatedData, &ciphertext)
+		if err != nil {
+			t.Fatalf("Test %d: failed to create encrypt writer: %v", i, err)
+		}
+		if _, err = io.Copy(w, bytes.NewReader(plaintext)); err != nil {
+			t.Fatalf("Test %d: failed to encrypt: %v", i, err)
+		}
+		if err = w.Close(); err != nil {
+			t.Fatalf("Test %d: failed to close encrypt writer: %v", i, err)
+		}
+
+		r, err := envelope.NewDecryptReader(ctx, client, associatedData, bytes.NewReader(ciphertext.Bytes()))
+		if err != nil {
+			t.Fatalf("Test %d: failed to create decrypt reader: %v", i, err)
+		}
+		decrypted, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("Test %d: failed to decrypt: %v", i, err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Fatalf("Test %d: plaintext mismatch", i)
+		}
+
+		if _, err = envelope.NewDecryptReader(ctx, client, []byte("invalid"), bytes.NewReader(ciphertext.Bytes())); !errors.Is(err, kms.ErrDecrypt) {
+			t.Fatalf("Test %d: decrypting with invalid associated data should have failed: got '%v' - want '%v'", i, err, kms.ErrDecrypt)
+		}
+	}
+}
+
+func TestDecryptReader_Tampered(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := srv.Client()
+	if err := client.CreateKey(ctx, kmstest.DefaultEnclave, &kms.CreateKeyRequest{Name: "my-key"}); err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+
+	var buf bytes.Buffer
+	w, err := envelope.NewEncryptWriter(ctx, client, kmstest.DefaultEnclave, "my-key", nil, &buf)
+	if err != nil {
+		t.Fatalf("Failed to create encrypt writer: %v", err)
+	}
+	if _, err = w.Write(make([]byte, 3*(1<<16))); err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatalf("Failed to close encrypt writer: %v", err)
+	}
+	ciphertext := buf.Bytes()
+
+	decrypt := func(ciphertext []byte) error {
+		r, err := envelope.NewDecryptReader(ctx, client, nil, bytes.NewReader(ciphertext))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(io.Discard, r)
+		return err
+	}
+	if err = decrypt(ciphertext); err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+
+	modified := bytes.Clone(ciphertext)
+	modified[len(modified)-1] ^= 1
+	if err = decrypt(modified); !errors.Is(err, envelope.ErrDecrypt) {
+		t.Fatalf("Decrypting a modified stream should have failed: got '%v' - want '%v'", err, envelope.ErrDecrypt)
+	}
+
+	truncated := ciphertext[:len(ciphertext)-16]
+	if err = decrypt(truncated); !errors.Is(err, envelope.ErrDecrypt) {
+		t.Fatalf("Decrypting a truncated stream should have failed: got '%v' - want '%v'", err, envelope.ErrDecrypt)
+	}
+
+	if err = decrypt(ciphertext[:3]); !errors.Is(err, envelope.ErrInvalidHeader) {
+		t.Fatalf("Decrypting an invalid header should have failed: got '%v' - want '%v'", err, envelope.ErrInvalidHeader)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package envelope
+
+import (
+	"context"
+	"crypto/cipher"
+	"errors"
+	"io"
+
+	"github.com/minio/kms-go/kms"
+)
+
+// DecryptReader is an io.Reader that decrypts a stream
+// produced by an EncryptWriter.
+//
+// A DecryptReader only returns plaintext that has been
+// verified to be authentic. It returns ErrDecrypt if the
+// stream has been modified or truncated.
+type DecryptReader struct {
+	r      io.Reader
+	aead   cipher.AEAD
+	ad     []byte // SHA-256 hash of the header
+	seqNum uint64
+	nonce  [nonceSize]byte
+
+	buf       []byte // Ciphertext chunk
+	plaintext []byte // Decrypted but not yet read plaintext
+	final     bool   // Whether the final chunk has been decrypted
+	err       error
+}
+
+// NewDecryptReader returns a new DecryptReader that decrypts
+// the encrypted stream r.
+//
+// It reads the stream header from r and decrypts the data
+// encryption key with the master key, version and enclave
+// recorded in the header. The associatedData must be equal
+// to the one provided to NewEncryptWriter.
+func NewDecryptReader(ctx context.Context, client *kms.Client, associatedData []byte, r io.Reader) (*DecryptReader, error) {
+	h, hdr, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := client.Decrypt(ctx, h.Enclave, &kms.DecryptRequest{
+		Name:           h.KeyName,
+		Version:        int(h.KeyVersion),
+		Ciphertext:     h.DEK,
+		AssociatedData: associatedData,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != 1 {
+		return nil, errors.New("envelope: invalid decrypt response")
+	}
+	defer clear(key[0].Plaintext)
+
+	aead, err := newAEAD(h.Cipher, key[0].Plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return &DecryptReader{
+		r:    r,
+		aead: aead,
+		ad:   headerHash(hdr),
+		buf:  make([]byte, chunkSize+tagSize),
+	}, nil
+}
+
+// Read reads and decrypts data from the underlying
+// io.Reader into p. It returns io.EOF once the final
+// chunk has been decrypted and read.
+func (r *DecryptReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for len(r.plaintext) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.final {
+			return 0, io.EOF
+		}
+		if err := r.readChunk(); err != nil {
+			r.err = err
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.plaintext)
+	r.plaintext = r.plaintext[n:]
+	return n, nil
+}
+
+// readChunk reads and decrypts the next chunk. Every chunk
+// but the final one has the same size. Hence, a short read
+// indicates the final chunk.
+func (r *DecryptReader) readChunk() error {
+	n, err := io.ReadFull(r.r, r.buf)
+	switch {
+	case err == nil:
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		r.final = true
+	case errors.Is(err, io.EOF):
+		return ErrDecrypt // Stream ends before the final chunk
+	default:
+		return err
+	}
+
+	nonce := chunkNonce(&r.nonce, r.seqNum, r.final)
+	plaintext, err := r.aead.Open(r.buf[:0], nonce, r.buf[:n], r.ad)
+	if err != nil {
+		return ErrDecrypt
+	}
+	r.plaintext = plaintext
+	r.seqNum++
+	return nil
+}
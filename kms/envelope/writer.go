@@ -0,0 +1,157 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package envelope
+
+import (
+	"context"
+	"crypto/cipher"
+	"errors"
+	"io"
+
+	"github.com/minio/kms-go/kms"
+)
+
+// EncryptWriter is an io.WriteCloser that encrypts everything
+// written to it and writes the resulting ciphertext to an
+// underlying io.Writer.
+//
+// The encrypted stream is not complete until Close has been
+// called. Close does not close the underlying io.Writer.
+type EncryptWriter struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	ad     []byte // SHA-256 hash of the header
+	seqNum uint64
+	nonce  [nonceSize]byte
+
+	buf []byte // Plaintext chunk, len(buf) < chunkSize
+	out []byte // Ciphertext chunk
+	err error
+}
+
+// NewEncryptWriter returns a new EncryptWriter that encrypts
+// everything written to it and writes the ciphertext to w.
+//
+// It generates a new data encryption key (DEK) with the latest
+// version of the master key keyName within the enclave. The DEK
+// is bound to the associatedData. The same associated data has to
+// be provided to NewDecryptReader when decrypting the stream.
+//
+// NewEncryptWriter fetches the status of the master key to select
+// the cipher. Hence, the client's identity must be allowed to
+// request the key status and to generate new keys.
+//
+// NewEncryptWriter writes the stream header to w before returning.
+func NewEncryptWriter(ctx context.Context, client *kms.Client, enclave, keyName string, associatedData []byte, w io.Writer) (*EncryptWriter, error) {
+	stat, err := client.KeyStatus(ctx, enclave, &kms.KeyStatusRequest{Name: keyName})
+	if err != nil {
+		return nil, err
+	}
+	if len(stat) != 1 {
+		return nil, errors.New("envelope: invalid key status response")
+	}
+
+	key, err := client.GenerateKey(ctx, enclave, &kms.GenerateKeyRequest{
+		Name:           keyName,
+		Version:        stat[0].Version,
+		AssociatedData: associatedData,
+		Length:         dekSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != 1 {
+		return nil, errors.New("envelope: invalid generate key response")
+	}
+	defer clear(key[0].Plaintext)
+
+	aead, err := newAEAD(stat[0].Type, key[0].Plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	h := header{
+		Cipher:     stat[0].Type,
+		KeyVersion: uint32(key[0].Version),
+		Enclave:    enclave,
+		KeyName:    keyName,
+		DEK:        key[0].Ciphertext,
+	}
+	hdr, err := h.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(hdr); err != nil {
+		return nil, err
+	}
+
+	return &EncryptWriter{
+		w:    w,
+		aead: aead,
+		ad:   headerHash(hdr),
+		buf:  make([]byte, 0, chunkSize),
+		out:  make([]byte, 0, chunkSize+tagSize),
+	}, nil
+}
+
+// Write encrypts p and writes the ciphertext to the
+// underlying io.Writer. Data is encrypted in chunks.
+// Hence, Write may buffer some data until the next
+// Write or Close call.
+func (w *EncryptWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	var n int
+	for len(p) > 0 {
+		m := copy(w.buf[len(w.buf):cap(w.buf)], p)
+		w.buf = w.buf[:len(w.buf)+m]
+		p = p[m:]
+		n += m
+
+		if len(w.buf) == chunkSize {
+			if err := w.writeChunk(false); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Close encrypts any remaining data and writes the final
+// chunk to the underlying io.Writer. It does not close the
+// underlying io.Writer.
+func (w *EncryptWriter) Close() error {
+	if w.err != nil {
+		if w.err == errClosed {
+			return nil
+		}
+		return w.err
+	}
+	if err := w.writeChunk(true); err != nil {
+		return err
+	}
+	w.err = errClosed
+	return nil
+}
+
+var errClosed = errors.New("envelope: write to closed EncryptWriter")
+
+// writeChunk encrypts the buffered plaintext and writes
+// the ciphertext to the underlying io.Writer.
+func (w *EncryptWriter) writeChunk(final bool) error {
+	nonce := chunkNonce(&w.nonce, w.seqNum, final)
+	w.out = w.aead.Seal(w.out[:0], nonce, w.buf, w.ad)
+	clear(w.buf)
+	w.buf = w.buf[:0]
+	w.seqNum++
+
+	if _, err := w.w.Write(w.out); err != nil {
+		w.err = err
+		return err
+	}
+	return nil
+}
@@ -6,14 +6,17 @@ package kms
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"strings"
+	"time"
 
 	"aead.dev/mem"
+	"github.com/minio/kms-go/kms/cmds"
 	"github.com/minio/kms-go/kms/internal/headers"
 	pb "github.com/minio/kms-go/kms/protobuf"
 	"google.golang.org/protobuf/encoding/protojson"
@@ -106,6 +109,27 @@ func readError(resp *http.Response) Error {
 	return Error{Code: resp.StatusCode, Err: sb.String()}
 }
 
+// decodeError decodes the cmds.Error at the beginning of b, if
+// any, and returns the remaining bytes. It reports whether b
+// starts with a cmds.Error.
+func decodeError(b []byte) ([]byte, Error, bool, error) {
+	if len(b) < 2 || cmds.Command(binary.BigEndian.Uint16(b)) != cmds.Error {
+		return b, Error{}, false, nil
+	}
+
+	var response pb.ErrResponse
+	b, err := cmds.DecodePB(b, cmds.Error, &response)
+	if err != nil {
+		return nil, Error{}, false, err
+	}
+
+	code := int(response.Code)
+	if code == 0 {
+		code = http.StatusInternalServerError
+	}
+	return b, Error{Code: code, Err: response.Message}, true, nil
+}
+
 // AsHostError returns the first error in err's tree, using errors.As,
 // that is of type *HostError. Otherwise, it returns nil.
 func AsHostError(err error) *HostError {
@@ -139,9 +163,6 @@ func UnwrapHostErrors(err error) []*HostError {
 		case interface{ Unwrap() []error }:
 			var hostErrors []*HostError
 			for _, err := range x.Unwrap() {
-				if h, ok := err.(*HostError); ok {
-					hostErrors = append(hostErrors, h)
-				}
 				if h := UnwrapHostErrors(err); h != nil {
 					hostErrors = append(hostErrors, h...)
 				}
@@ -163,6 +184,41 @@ func hostError(host string, err error) error {
 	}
 }
 
+// OutcomeUnknownError is returned when a request that changes
+// state on a KMS server, like creating a key, fails after it may
+// have been sent. For example, when the connection breaks before
+// the response has been received. The KMS server may or may not
+// have applied the request.
+//
+// Such requests are not retried automatically since applying them
+// twice may have unintended effects. For example, adding two key
+// versions. Instead, Client.Reconcile can be used to find out
+// whether the request has been applied.
+type OutcomeUnknownError struct {
+	Request  *Request       // The request that failed
+	Commands []cmds.Command // The commands within the request body
+	Sent     time.Time      // Point in time the request has been sent
+	Err      error          // The underlying error
+
+	// KeyVersions contains the latest key version, per key name,
+	// before the request has been sent, if known. Client.CreateKey
+	// sets it when adding a key version.
+	KeyVersions map[string]int
+}
+
+// Error returns the underlying error message prefixed by the
+// request's commands.
+func (e *OutcomeUnknownError) Error() string {
+	names := make([]string, 0, len(e.Commands))
+	for _, cmd := range e.Commands {
+		names = append(names, cmd.String())
+	}
+	return "kms: outcome of " + strings.Join(names, ",") + " unknown: " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *OutcomeUnknownError) Unwrap() error { return e.Err }
+
 // HostError captures an error returned by a host.
 // It implements the net.Error interface.
 type HostError struct {
@@ -0,0 +1,44 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package kms_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/minio/kms-go/kms"
+)
+
+func TestUnwrapHostErrors(t *testing.T) {
+	t.Parallel()
+
+	var (
+		e1 = &kms.HostError{Host: "127.0.0.1:7373", Err: kms.ErrPermission}
+		e2 = &kms.HostError{Host: "127.0.0.2:7373", Err: kms.ErrKeyNotFound}
+		e3 = &kms.HostError{Host: "127.0.0.3:7373", Err: kms.ErrDecrypt}
+	)
+	for i, test := range []struct {
+		Err  error
+		Want []*kms.HostError
+	}{
+		{Err: nil, Want: nil},                                                                                  // 0
+		{Err: errors.New("kms: error"), Want: nil},                                                             // 1
+		{Err: e1, Want: []*kms.HostError{e1}},                                                                  // 2
+		{Err: fmt.Errorf("kms: %w", e1), Want: []*kms.HostError{e1}},                                           // 3
+		{Err: errors.Join(e1, e2), Want: []*kms.HostError{e1, e2}},                                             // 4
+		{Err: errors.Join(e1, errors.Join(e2, fmt.Errorf("kms: %w", e3))), Want: []*kms.HostError{e1, e2, e3}}, // 5
+	} {
+		got := kms.UnwrapHostErrors(test.Err)
+		if len(got) != len(test.Want) {
+			t.Fatalf("Test %d: got %d host errors - want %d: %v", i, len(got), len(test.Want), got)
+		}
+		for j := range got {
+			if got[j] != test.Want[j] {
+				t.Fatalf("Test %d: host error %d: got '%v' - want '%v'", i, j, got[j], test.Want[j])
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package kms
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// HostHealth is the health of a KMS server as determined by
+// the most recent health check of a Client.
+type HostHealth struct {
+	// Host is the KMS server's host or host:port.
+	Host string
+
+	// Ready indicates whether the KMS server is ready to
+	// serve requests. Servers that are not ready are not
+	// selected for requests until they are ready again.
+	Ready bool
+
+	// WriteReady indicates whether the KMS server is ready
+	// to serve write requests, like creating a key. Servers
+	// that are not ready for writes are only selected for
+	// read-only requests.
+	WriteReady bool
+
+	// LastCheck is the point in time when the KMS server
+	// has been checked.
+	LastCheck time.Time
+
+	// Err is the error returned by the most recent health
+	// check, if it has failed.
+	Err error
+}
+
+// healthChecker periodically checks the readiness of all
+// hosts of a Client such that requests are not sent to
+// KMS servers that are not ready.
+type healthChecker struct {
+	client *Client
+
+	mu     sync.Mutex
+	health map[string]HostHealth
+}
+
+// Health returns the health of all hosts that have been checked
+// in the order of the client's hosts.
+func (h *healthChecker) Health() []HostHealth {
+	hosts := h.client.Hosts()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	health := make([]HostHealth, 0, len(hosts))
+	for _, host := range hosts {
+		if s, ok := h.health[host]; ok {
+			health = append(health, s)
+		}
+	}
+	return health
+}
+
+// Run checks the readiness of all hosts every interval
+// until ctx is done.
+func (h *healthChecker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		h.Check(ctx, interval)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check checks the readiness of all hosts concurrently and
+// excludes hosts that are not ready from load balancing. A
+// single check times out after timeout.
+func (h *healthChecker) Check(ctx context.Context, timeout time.Duration) {
+	hosts := h.client.Hosts()
+
+	var wg sync.WaitGroup
+	for _, host := range hosts {
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+			h.check(ctx, host, timeout)
+		}(host)
+	}
+	wg.Wait()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for host := range h.health {
+		if !slices.Contains(hosts, host) {
+			delete(h.health, host)
+		}
+	}
+}
+
+// check checks whether host is ready to serve requests and, if
+// so, whether it is ready to serve write requests as well.
+func (h *healthChecker) check(ctx context.Context, host string, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	health := HostHealth{Host: host, LastCheck: time.Now()}
+	health.Err = h.client.Ready(ctx, &ReadinessRequest{Hosts: []string{host}})
+	if health.Ready = isReady(health.Err); health.Ready {
+		if err := h.client.Ready(ctx, &ReadinessRequest{Hosts: []string{host}, Write: true}); err != nil {
+			health.Err = err
+		}
+		health.WriteReady = isReady(health.Err)
+	}
+	if errors.Is(health.Err, context.Canceled) { // The client has been closed
+		return
+	}
+
+	h.client.lb.SetReady(host, health.Ready, health.WriteReady)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.health == nil {
+		h.health = map[string]HostHealth{}
+	}
+	h.health[host] = health
+}
+
+// isReady reports whether err, returned by a readiness check,
+// indicates that the KMS server is ready. Errors caused by the
+// request itself, like ErrPermission, do not indicate that a
+// server is not ready.
+func isReady(err error) bool {
+	if err == nil {
+		return true
+	}
+	if e := (Error{}); errors.As(err, &e) {
+		return e.Code < 500
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package kms_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/kms-go/kms"
+	"github.com/minio/kms-go/kms/kmstest"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestClient_Health(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	// readOnly is ready for read requests but not for writes.
+	readOnly := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/health/ready" && !r.URL.Query().Has("write") {
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer readOnly.Close()
+
+	const (
+		Enclave     = kmstest.DefaultEnclave
+		Unreachable = "127.0.0.1:1"
+	)
+	var (
+		ctx          = context.Background()
+		metrics      = kms.NewMetrics()
+		rootCAs      = x509.NewCertPool()
+		readOnlyHost = strings.TrimPrefix(readOnly.URL, "https://")
+	)
+	rootCAs.AddCert(srv.Certificate())
+	rootCAs.AddCert(readOnly.Certificate())
+
+	client, err := kms.NewClient(&kms.Config{
+		Endpoints:           []string{Unreachable, readOnlyHost, srv.Host()},
+		APIKey:              srv.APIKey,
+		TLS:                 &tls.Config{RootCAs: rootCAs},
+		Metrics:             metrics,
+		HealthCheckInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	health := client.Health()
+	for start := time.Now(); len(health) < 3; health = client.Health() {
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("Health checks have not completed: got '%v'", health)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	for i, want := range []kms.HostHealth{
+		{Host: Unreachable, Ready: false, WriteReady: false},
+		{Host: readOnlyHost, Ready: true, WriteReady: false},
+		{Host: srv.Host(), Ready: true, WriteReady: true},
+	} {
+		got := health[i]
+		if got.Host != want.Host || got.Ready != want.Ready || got.WriteReady != want.WriteReady {
+			t.Fatalf("Invalid health: got '%+v' - want '%+v'", got, want)
+		}
+		if (got.Err == nil) != want.WriteReady {
+			t.Fatalf("Invalid health of '%s': got error '%v'", got.Host, got.Err)
+		}
+		if got.LastCheck.IsZero() {
+			t.Fatalf("Invalid health of '%s': last check time is zero", got.Host)
+		}
+	}
+
+	// Write requests must only be sent to the host that is
+	// ready for writes. Hence, they are never retried.
+	for i := 0; i < 16; i++ {
+		name := "my-key-" + strconv.Itoa(i)
+		if err = client.CreateKey(ctx, Enclave, &kms.CreateKeyRequest{Name: name}); err != nil {
+			t.Fatalf("Failed to create key: %v", err)
+		}
+	}
+	if n := testutil.CollectAndCount(metrics, "kms_client_retries_total"); n != 0 {
+		t.Fatalf("Write requests have been retried: got %d - want %d", n, 0)
+	}
+	if n := testutil.CollectAndCount(metrics, "kms_client_requests_total"); n != 1 {
+		t.Fatalf("Write requests have been sent to multiple hosts: got %d - want %d", n, 1)
+	}
+	if n := testutil.CollectAndCount(metrics, "kms_client_excluded_hosts"); n != 1 {
+		t.Fatalf("Invalid number of excluded hosts: got %d - want %d", n, 1)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package kms
+
+import (
+	"context"
+	"encoding/binary"
+	"net/http"
+	"time"
+
+	"github.com/minio/kms-go/kms/cmds"
+)
+
+// Call describes a single Client.Send call passed through
+// the Interceptors of a Client.
+type Call struct {
+	// Request is the KMS request. Interceptors may modify
+	// the request before invoking the next Invoker.
+	Request *Request
+
+	// Commands is the list of commands within the request
+	// body, in the same order.
+	Commands []cmds.Command
+
+	// Header contains additional HTTP headers sent with the
+	// request. Interceptors may use it to annotate requests.
+	// For example, to add a request ID.
+	Header http.Header
+
+	// Host is the KMS server that handled the request. If the
+	// Client retries the request with different servers, it's
+	// the last server. Set once the request has been sent.
+	Host string
+
+	// StatusCode is the HTTP response status code. Set once
+	// the request has been sent. It is zero if no response
+	// has been received.
+	StatusCode int
+
+	// Latency is the amount of time it took to send the request
+	// and receive the response headers, including retries. Set
+	// once the request has been sent.
+	Latency time.Duration
+}
+
+// Invoker sends the request of a Call to a KMS server.
+//
+// The returned error is of type *HostError.
+type Invoker func(ctx context.Context, call *Call) (*http.Response, error)
+
+// Interceptor intercepts calls to Client.Send. It is called
+// with the next Invoker of the chain and may observe or modify
+// the call before and after invoking it.
+//
+// An Interceptor may short-circuit a call by returning without
+// invoking next. For example, to inject faults or to serve a
+// response from a cache. An error returned by an Interceptor
+// should be of type *HostError.
+type Interceptor func(ctx context.Context, call *Call, next Invoker) (*http.Response, error)
+
+// chainInterceptors returns an Invoker that passes calls
+// through all interceptors, from first to last, before
+// calling invoke.
+func chainInterceptors(interceptors []Interceptor, invoke Invoker) Invoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoke
+		invoke = func(ctx context.Context, call *Call) (*http.Response, error) {
+			return interceptor(ctx, call, next)
+		}
+	}
+	return invoke
+}
+
+// decodeCommands returns the commands within the request body
+// b. It stops at the first malformed command.
+func decodeCommands(b []byte) []cmds.Command {
+	var commands []cmds.Command
+	for len(b) >= 6 {
+		commands = append(commands, cmds.Command(binary.BigEndian.Uint16(b)))
+
+		n := binary.BigEndian.Uint32(b[2:])
+		if uint64(len(b)-6) < uint64(n) {
+			break
+		}
+		b = b[6+n:]
+	}
+	return commands
+}
+
+// isWriteOnly reports whether commands is a non-empty list
+// of commands that change state on a KMS server.
+func isWriteOnly(commands []cmds.Command) bool {
+	for _, cmd := range commands {
+		if !cmd.IsWrite() {
+			return false
+		}
+	}
+	return len(commands) > 0
+}
+
+// isReadOnly reports whether commands is a non-empty list
+// of commands that don't change state on a KMS server.
+func isReadOnly(commands []cmds.Command) bool {
+	for _, cmd := range commands {
+		if cmd.IsWrite() {
+			return false
+		}
+	}
+	return len(commands) > 0
+}
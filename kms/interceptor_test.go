@@ -0,0 +1,81 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package kms_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/minio/kms-go/kms"
+	"github.com/minio/kms-go/kms/cmds"
+	"github.com/minio/kms-go/kms/kmstest"
+)
+
+func TestInterceptor(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	var (
+		calls   []*kms.Call
+		errDeny = errors.New("denied by interceptor")
+	)
+	record := func(ctx context.Context, call *kms.Call, next kms.Invoker) (*http.Response, error) {
+		call.Header.Set("X-Request-Id", "42")
+		resp, err := next(ctx, call)
+		calls = append(calls, call)
+		return resp, err
+	}
+	deny := func(ctx context.Context, call *kms.Call, next kms.Invoker) (*http.Response, error) {
+		if slices.Contains(call.Commands, cmds.KeyDelete) {
+			return nil, &kms.HostError{Err: errDeny}
+		}
+		return next(ctx, call)
+	}
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(srv.Certificate())
+	client, err := kms.NewClient(&kms.Config{
+		Endpoints:    []string{srv.Host()},
+		APIKey:       srv.APIKey,
+		TLS:          &tls.Config{RootCAs: rootCAs},
+		Interceptors: []kms.Interceptor{record, deny},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	const Enclave = kmstest.DefaultEnclave
+	ctx := context.Background()
+	if err = client.CreateKey(ctx, Enclave, &kms.CreateKeyRequest{Name: "my-key"}); err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	_, err = client.KeyStatus(ctx, Enclave, &kms.KeyStatusRequest{Name: "my-key"}, &kms.KeyStatusRequest{Name: "other-key"})
+	if !errors.Is(err, kms.ErrKeyNotFound) {
+		t.Fatalf("Fetching a non-existing key should have failed: got '%v' - want '%v'", err, kms.ErrKeyNotFound)
+	}
+	if err = client.DeleteKey(ctx, Enclave, &kms.DeleteKeyRequest{Name: "my-key"}); !errors.Is(err, errDeny) {
+		t.Fatalf("Deleting a key should have been denied: got '%v' - want '%v'", err, errDeny)
+	}
+
+	if len(calls) != 3 {
+		t.Fatalf("Invalid number of intercepted calls: got %d - want %d", len(calls), 3)
+	}
+	if c := calls[0]; !slices.Equal(c.Commands, []cmds.Command{cmds.KeyCreate}) || c.StatusCode != http.StatusOK || c.Host != srv.Host() || c.Request.Enclave != Enclave {
+		t.Fatalf("Call 0: invalid call: got %+v", c)
+	}
+	if c := calls[1]; !slices.Equal(c.Commands, []cmds.Command{cmds.KeyStatus, cmds.KeyStatus}) || c.StatusCode != http.StatusNotFound {
+		t.Fatalf("Call 1: invalid call: got %+v", c)
+	}
+	if c := calls[2]; c.StatusCode != 0 || c.Latency != 0 {
+		t.Fatalf("Call 2: call should have been short-circuited: got %+v", c)
+	}
+}
@@ -32,4 +32,5 @@ const (
 // API query parameters supported by KMS servers.
 const (
 	QueryReadyWrite = "write"
+	QueryPartial    = "partial"
 )
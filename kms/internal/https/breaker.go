@@ -0,0 +1,254 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package https
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// CircuitBreaker controls when a LoadBalancer excludes a host.
+//
+// A host is closed, i.e. not excluded, until requests to it have
+// failed FailureThreshold times in a row. Then it is open, i.e.
+// excluded, for the LoadBalancer's Timeout. Afterwards, it is
+// half-open and gets selected again. While half-open, a single
+// failed request opens it again and SuccessThreshold successful
+// requests close it.
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failed
+	// requests after which a host is excluded. If 0, defaults
+	// to 1.
+	FailureThreshold int
+
+	// SuccessThreshold is the number of successful requests
+	// after which a half-open host is closed again. If 0,
+	// defaults to 1.
+	SuccessThreshold int
+}
+
+// CircuitState is the state of a host's circuit breaker.
+type CircuitState int
+
+// All circuit breaker states.
+const (
+	// CircuitClosed indicates that requests
+	// are sent to the host.
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen indicates that the host is
+	// excluded since requests to it have failed.
+	CircuitOpen
+
+	// CircuitHalfOpen indicates that the host is no
+	// longer excluded but a single failed request
+	// excludes it again.
+	CircuitHalfOpen
+)
+
+// String returns the string representation of the state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "CircuitState(" + strconv.Itoa(int(s)) + ")"
+	}
+}
+
+// HostStats contains statistics about the requests sent to a
+// host and the state of its circuit breaker.
+type HostStats struct {
+	// Host is the host or host:port.
+	Host string
+
+	// State is the state of the host's circuit breaker.
+	// The host is not selected for requests while its
+	// breaker is open.
+	State CircuitState
+
+	// Excluded is the point in time when the host got
+	// excluded most recently. It is zero if the breaker
+	// is closed.
+	Excluded time.Time
+
+	// Ready and WriteReady indicate whether the host is
+	// ready to serve requests resp. write requests, as
+	// determined by health checks. Both are true if health
+	// checks are not enabled.
+	Ready, WriteReady bool
+
+	// ConsecutiveFailures is the number of requests that have
+	// failed since the most recent successful request.
+	ConsecutiveFailures int
+
+	// Successes and Failures are the total number of successful
+	// and failed requests. Responses with a 502, 503 or 504 status
+	// code count as failures. Other error responses don't.
+	Successes, Failures uint64
+
+	// LastError is the error of the most recent failed request,
+	// if any, and LastErrorTime the point in time it failed.
+	LastError     error
+	LastErrorTime time.Time
+
+	// Latency is the exponentially weighted moving average of
+	// the latencies of recent successful requests.
+	Latency time.Duration
+}
+
+// Stats returns the statistics of all hosts.
+func (lb *LoadBalancer) Stats() []HostStats {
+	t := timeout(lb.Timeout)
+
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	now := time.Now()
+	stats := make([]HostStats, 0, len(lb.hosts))
+	for _, host := range lb.hosts {
+		s := HostStats{
+			Host:       host,
+			State:      CircuitClosed,
+			Ready:      true,
+			WriteReady: true,
+		}
+		if excluded, ok := lb.timeout[host]; ok {
+			s.Excluded, s.State = excluded, CircuitHalfOpen
+			if now.Sub(excluded) < t {
+				s.State = CircuitOpen
+			}
+		}
+		if r, ok := lb.ready[host]; ok {
+			s.Ready, s.WriteReady = r.read, r.write
+		}
+		if h, ok := lb.stats[host]; ok {
+			s.ConsecutiveFailures = h.consecutive
+			s.Successes, s.Failures = h.successes, h.failures
+			s.LastError, s.LastErrorTime = h.lastErr, h.lastErrTime
+			s.Latency = h.latency
+		}
+		stats = append(stats, s)
+	}
+	return stats
+}
+
+// hostStats contains the request statistics of a host.
+type hostStats struct {
+	consecutive int // Consecutive failures
+	probes      int // Successful requests while half-open
+	successes   uint64
+	failures    uint64
+	lastErr     error
+	lastErrTime time.Time
+	latency     time.Duration // EWMA of recent latencies
+}
+
+// stat returns the statistics of host. The
+// caller must hold lb.mu for writing.
+func (lb *LoadBalancer) stat(host string) *hostStats {
+	if lb.stats == nil {
+		lb.stats = map[string]*hostStats{}
+	}
+	s, ok := lb.stats[host]
+	if !ok {
+		s = &hostStats{}
+		lb.stats[host] = s
+	}
+	return s
+}
+
+// fail records that a request to host has failed with err. It
+// excludes host temporarily if its circuit breaker opens and
+// calls the OnExclude hook, if any.
+func (lb *LoadBalancer) fail(host string, err error) {
+	threshold := 1
+	if lb.Breaker != nil && lb.Breaker.FailureThreshold > 0 {
+		threshold = lb.Breaker.FailureThreshold
+	}
+
+	lb.mu.Lock()
+	if !slices.Contains(lb.hosts, host) {
+		lb.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	s := lb.stat(host)
+	s.failures++
+	s.consecutive++
+	s.probes = 0
+	s.lastErr, s.lastErrTime = err, now
+
+	_, halfOpen := lb.timeout[host]
+	open := halfOpen || s.consecutive >= threshold
+	if open {
+		if lb.timeout == nil {
+			lb.timeout = map[string]time.Time{}
+		}
+		lb.timeout[host] = now
+	}
+	lb.mu.Unlock()
+
+	if open && lb.OnExclude != nil {
+		lb.OnExclude(host)
+	}
+}
+
+// succeed records that a request to host has succeeded with
+// the given latency. It closes the circuit breaker of host
+// if it is half-open and enough requests have succeeded.
+func (lb *LoadBalancer) succeed(host string, latency time.Duration) {
+	threshold := 1
+	if lb.Breaker != nil && lb.Breaker.SuccessThreshold > 0 {
+		threshold = lb.Breaker.SuccessThreshold
+	}
+
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	if !slices.Contains(lb.hosts, host) {
+		return
+	}
+
+	s := lb.stat(host)
+	s.successes++
+	s.consecutive = 0
+	if s.latency == 0 {
+		s.latency = latency
+	} else {
+		s.latency = MovingAverage(s.latency, latency)
+	}
+
+	excluded, ok := lb.timeout[host]
+	if !ok || time.Since(excluded) < timeout(lb.Timeout) {
+		return // Closed or open
+	}
+	if s.probes++; s.probes >= threshold {
+		delete(lb.timeout, host)
+		s.probes = 0
+	}
+}
+
+// MovingAverage returns the exponentially weighted moving
+// average of a host's latency after adding the latency of
+// a new request to the average avg.
+func MovingAverage(avg, latency time.Duration) time.Duration {
+	const Weight = 0.3 // Weight of a new latency within the average
+	return avg + time.Duration(Weight*float64(latency-avg))
+}
+
+// isUnavailable reports whether the response status code
+// indicates that the host is not able to serve requests.
+// Such responses count as failed requests.
+func isUnavailable(code int) bool {
+	return code == http.StatusBadGateway || code == http.StatusServiceUnavailable || code == http.StatusGatewayTimeout
+}
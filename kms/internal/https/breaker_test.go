@@ -0,0 +1,113 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package https
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadBalancer_Breaker(t *testing.T) {
+	t.Parallel()
+
+	var broken atomic.Bool
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if broken.Load() {
+			conn, _, err := w.(http.Hijacker).Hijack() // Close the connection without response
+			if err == nil {
+				conn.Close()
+			}
+		}
+	}))
+	defer flaky.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	const Timeout = 50 * time.Millisecond
+	lb := &LoadBalancer{
+		RoundTripper: &http.Transport{DisableKeepAlives: true},
+		Timeout:      Timeout,
+		Breaker: &CircuitBreaker{
+			FailureThreshold: 2,
+			SuccessThreshold: 2,
+		},
+	}
+	lb.SetHosts([]string{host(flaky), host(srv)})
+
+	send := func() {
+		resp, err := lb.RoundTrip(newRequest(t, http.MethodGet, host(flaky), ""))
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		resp.Body.Close()
+	}
+	state := func(want CircuitState, failures int) {
+		s := lb.Stats()[0]
+		if s.State != want {
+			t.Fatalf("Invalid circuit state: got '%v' - want '%v'", s.State, want)
+		}
+		if s.ConsecutiveFailures != failures {
+			t.Fatalf("Invalid number of consecutive failures: got '%d' - want '%d'", s.ConsecutiveFailures, failures)
+		}
+	}
+
+	broken.Store(true)
+	send()
+	state(CircuitClosed, 1)
+	send()
+	state(CircuitOpen, 2)
+	if excluded := lb.Excluded(); len(excluded) != 1 || excluded[0] != host(flaky) {
+		t.Fatalf("Invalid excluded hosts: got '%v' - want '%v'", excluded, []string{host(flaky)})
+	}
+
+	time.Sleep(Timeout)
+	state(CircuitHalfOpen, 2)
+	send() // A single failure opens the breaker again
+	state(CircuitOpen, 3)
+
+	time.Sleep(Timeout)
+	broken.Store(false)
+	send()
+	state(CircuitHalfOpen, 0)
+	send()
+	state(CircuitClosed, 0)
+
+	stats := lb.Stats()
+	if s := stats[0]; s.Successes != 2 || s.Failures != 3 || s.LastError == nil || s.Latency <= 0 {
+		t.Fatalf("Invalid stats of '%s': got '%+v'", s.Host, s)
+	}
+	if s := stats[1]; s.Successes != 3 || s.Failures != 0 || s.LastError != nil {
+		t.Fatalf("Invalid stats of '%s': got '%+v'", s.Host, s)
+	}
+}
+
+func TestLoadBalancer_Breaker_Unavailable(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	lb := &LoadBalancer{
+		RoundTripper: &http.Transport{DisableKeepAlives: true},
+		Breaker:      &CircuitBreaker{FailureThreshold: 2},
+	}
+	lb.SetHosts([]string{host(srv)})
+
+	for i := 0; i < 2; i++ {
+		resp, err := lb.RoundTrip(newRequest(t, http.MethodGet, host(srv), ""))
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		resp.Body.Close()
+	}
+	if s := lb.Stats()[0]; s.State != CircuitOpen || s.Successes != 0 || s.Failures != 2 || s.LastError == nil {
+		t.Fatalf("Unavailable host should have been excluded: got '%+v'", s)
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package https
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy controls how a LoadBalancer retries requests.
+type RetryPolicy struct {
+	// MaxAttempts is the max. number of attempts to send
+	// a request, including the first one. If 0, each host
+	// is tried at most once.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry. It
+	// doubles with every further retry. A random jitter
+	// of up to half the delay is subtracted. If 0, requests
+	// are retried immediately.
+	BaseDelay time.Duration
+
+	// MaxDelay limits the delay between two attempts. If
+	// a server asks to retry after more than MaxDelay, the
+	// request is not retried. If 0, defaults to 10 seconds.
+	MaxDelay time.Duration
+
+	// AttemptTimeout, if greater than 0, limits how long a
+	// single attempt may take until a response is received.
+	// Attempts that time out are retried.
+	AttemptTimeout time.Duration
+
+	// Hedge controls whether read-only requests are sent to
+	// a second host if no response has been received after
+	// the 95th percentile of recent read-only latencies. The
+	// first response is used.
+	Hedge bool
+}
+
+// errAttemptTimeout is returned when an attempt
+// exceeds the RetryPolicy's AttemptTimeout.
+var errAttemptTimeout = errors.New("https: attempt timed out")
+
+type readOnlyKey struct{}
+
+// WithReadOnly returns a copy of ctx that marks requests sent
+// with it as read-only. Read-only requests, and GET and HEAD
+// requests, don't change state on the server and may be hedged.
+func WithReadOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readOnlyKey{}, true)
+}
+
+// isReadOnly reports whether req does not change state on
+// the server.
+func isReadOnly(req *http.Request) bool {
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return true
+	}
+	readOnly, _ := req.Context().Value(readOnlyKey{}).(bool)
+	return readOnly
+}
+
+// isReplayable reports whether req can be sent more than once.
+func isReplayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// hedge sends req to host. If no response has been received after
+// the hedging delay, it sends req to another host as well and returns
+// the first successful attempt, if any, and the number of attempts.
+func (lb *LoadBalancer) hedge(req *http.Request, hosts []string, host string) (attempt, int) {
+	delay, ok := lb.latency.P95()
+	if !ok {
+		return lb.send(req.Context(), req, host, 0), 1
+	}
+
+	ch := make(chan hedged, 2)
+	start := func(host string, retry int) context.CancelFunc {
+		ctx, cancel := context.WithCancel(req.Context())
+		go func() { ch <- hedged{attempt: lb.send(ctx, req, host, retry), cancel: cancel} }()
+		return cancel
+	}
+	cancelHost := start(host, 0)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var first hedged
+	select {
+	case first = <-ch:
+		return first.done(), 1
+	case <-timer.C:
+	}
+
+	other := lb.next(hosts, host, false)
+	if other == "" || other == host {
+		first = <-ch
+		return first.done(), 1
+	}
+	cancelOther := start(other, 1)
+
+	first = <-ch
+	if _, retry := lb.isRetryable(req, first.attempt); !retry {
+		if first.host == host { // Cancel the slower attempt
+			cancelOther()
+		} else {
+			cancelHost()
+		}
+		go func() {
+			second := <-ch
+			second.cancel()
+			closeResponseBody(second.resp)
+		}()
+		return first.done(), 2
+	}
+
+	second := <-ch
+	if first.err != nil {
+		lb.fail(first.host, first.err)
+	}
+	first.cancel()
+	closeResponseBody(first.resp)
+	return second.done(), 2
+}
+
+// hedged is an attempt of a hedged request.
+type hedged struct {
+	attempt
+	cancel context.CancelFunc // Cancels the attempt's context
+}
+
+// done returns the attempt. It ties the attempt's context to the
+// response body such that the context is canceled once the body
+// is closed.
+func (h hedged) done() attempt {
+	if h.err != nil {
+		h.cancel()
+		return h.attempt
+	}
+	h.resp.Body = &cancelBody{ReadCloser: h.resp.Body, cancel: h.cancel}
+	return h.attempt
+}
+
+// backoff returns the delay before the given retry. If the server
+// asked to retry after some time, it returns at least retryAfter.
+// It reports whether the request should be retried at all.
+func (lb *LoadBalancer) backoff(retry int, retryAfter time.Duration) (time.Duration, bool) {
+	if lb.Policy == nil {
+		return 0, true
+	}
+
+	maxDelay := lb.Policy.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = 10 * time.Second
+	}
+	if retryAfter > maxDelay {
+		return 0, false
+	}
+
+	delay := lb.Policy.BaseDelay
+	for i := 1; i < retry && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxDelay)
+	if delay > 0 {
+		delay -= time.Duration(rand.Int63n(int64(delay/2) + 1))
+	}
+	return max(delay, retryAfter), true
+}
+
+// parseRetryAfter returns the duration of the given Retry-After
+// header value, either in seconds or as HTTP date. It returns 0
+// if the value is empty or invalid.
+func parseRetryAfter(s string) time.Duration {
+	if s == "" {
+		return 0
+	}
+	if sec, err := strconv.ParseUint(s, 10, 32); err == nil {
+		return time.Duration(sec) * time.Second
+	}
+	if t, err := http.ParseTime(s); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
+}
+
+// sleep waits for d or until ctx is done, in
+// which case it returns ctx.Err().
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// cancelBody is a response body that cancels the
+// request context once closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// latencies keeps the most recent latencies of successful
+// requests to compute the hedging delay.
+type latencies struct {
+	mu      sync.Mutex
+	samples [128]time.Duration
+	n       int // Total number of samples added
+}
+
+// Add adds the latency d.
+func (l *latencies) Add(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.samples[l.n%len(l.samples)] = d
+	l.n++
+}
+
+// P95 returns the 95th percentile of the recent latencies.
+// It reports whether there are enough samples.
+func (l *latencies) P95() (time.Duration, bool) {
+	const MinSamples = 20
+
+	l.mu.Lock()
+	samples := slices.Clone(l.samples[:min(l.n, len(l.samples))])
+	l.mu.Unlock()
+
+	if len(samples) < MinSamples {
+		return 0, false
+	}
+	slices.Sort(samples)
+	return samples[len(samples)*95/100], true
+}
@@ -6,15 +6,20 @@ package https
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"io"
 	"math/rand"
+	"net"
 	"net/http"
 	"net/url"
 	"slices"
 	"strings"
 	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // LoadBalancer is an http.RoundTripper that implements client-side
@@ -29,28 +34,133 @@ import (
 // or there are no hosts remaining resp. the retry limit is reached.
 // Hosts for which requests fail are temporarily excluded and no longer
 // selected for subsequent requests.
+//
+// Hosts may also be excluded ahead of traffic based on health checks.
+// Refer to SetReady.
 type LoadBalancer struct {
 	// Underlying RoundTripper used to send requests.
 	http.RoundTripper
 
-	// List of hosts the requests are distributed over.
-	//
-	// If a request fails and its URL host is not part of
-	// this list then the LoadBalancer will not retry the
-	// request.
-	Hosts []string
-
 	// Timeout controls how long a host is excluded if a
 	// request to this host fails. If 0, defaults to 30
 	// seconds.
 	Timeout time.Duration
 
-	// Retry specifies how often the LoadBalancer retries
-	// a request with different hosts bef
-	Retry int
+	// Breaker, if not nil, controls after how many failed
+	// requests a host is excluded and after how many
+	// successful requests it is no longer excluded. If
+	// nil, a host is excluded after one failed request.
+	Breaker *CircuitBreaker
+
+	// Policy, if not nil, controls how the LoadBalancer
+	// retries requests. Refer to RoundTrip.
+	Policy *RetryPolicy
+
+	// Selector, if not nil, selects the host returned by
+	// Host among all hosts that are not excluded. If nil,
+	// Host selects hosts randomly.
+	Selector Selector
+
+	// Tracer, if not nil, records every attempt to send a
+	// request as span and propagates its W3C trace context
+	// to the host.
+	Tracer trace.Tracer
+
+	// OnRetry, if not nil, is called whenever a request
+	// is retried with the given host.
+	OnRetry func(host string)
+
+	// OnExclude, if not nil, is called whenever the given
+	// host is excluded since a request to it failed.
+	OnExclude func(host string)
+
+	// OnChange, if not nil, is called with the new list of
+	// hosts whenever the list of hosts changes. It must not
+	// modify the list of hosts.
+	OnChange func(hosts []string)
+
+	update sync.Mutex // Serializes host list updates and OnChange calls
 
 	mu      sync.RWMutex
-	timeout map[string]time.Time
+	hosts   []string              // Hosts the requests are distributed over
+	timeout map[string]time.Time  // Point in time when a host got excluded
+	stats   map[string]*hostStats // Request statistics of each host
+	ready   map[string]readiness  // Health check results. Unknown hosts are ready
+	latency latencies             // Latencies of read-only requests used for hedging
+}
+
+// Hosts returns a copy of the list of hosts the requests
+// are distributed over.
+func (lb *LoadBalancer) Hosts() []string {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	return slices.Clone(lb.hosts)
+}
+
+// SetHosts replaces the list of hosts the requests are
+// distributed over. It reports whether the list has changed.
+//
+// If a request fails and its URL host is not part of this
+// list then the LoadBalancer will not retry the request.
+func (lb *LoadBalancer) SetHosts(hosts []string) bool {
+	return lb.updateHosts(func([]string) []string { return slices.Clone(hosts) })
+}
+
+// AddHost adds host to the list of hosts, if not present
+// already. It reports whether the list has changed.
+func (lb *LoadBalancer) AddHost(host string) bool {
+	return lb.updateHosts(func(hosts []string) []string {
+		if slices.Contains(hosts, host) {
+			return hosts
+		}
+		return append(slices.Clip(hosts), host)
+	})
+}
+
+// RemoveHost removes host from the list of hosts. It
+// reports whether the list has changed.
+func (lb *LoadBalancer) RemoveHost(host string) bool {
+	return lb.updateHosts(func(hosts []string) []string {
+		return slices.DeleteFunc(slices.Clone(hosts), func(h string) bool { return h == host })
+	})
+}
+
+// updateHosts replaces the list of hosts with the list returned
+// by fn and calls OnChange if the list has changed. fn must not
+// modify the list of hosts passed to it.
+func (lb *LoadBalancer) updateHosts(fn func([]string) []string) bool {
+	lb.update.Lock()
+	defer lb.update.Unlock()
+
+	lb.mu.Lock()
+	hosts := fn(lb.hosts)
+	if slices.Equal(hosts, lb.hosts) {
+		lb.mu.Unlock()
+		return false
+	}
+	lb.hosts = hosts
+	for host := range lb.timeout {
+		if !slices.Contains(hosts, host) {
+			delete(lb.timeout, host)
+		}
+	}
+	for host := range lb.ready {
+		if !slices.Contains(hosts, host) {
+			delete(lb.ready, host)
+		}
+	}
+	for host := range lb.stats {
+		if !slices.Contains(hosts, host) {
+			delete(lb.stats, host)
+		}
+	}
+	lb.mu.Unlock()
+
+	if lb.OnChange != nil {
+		lb.OnChange(slices.Clone(hosts))
+	}
+	return true
 }
 
 // URL returns an URL string with the next host and the provided
@@ -79,28 +189,65 @@ func (lb *LoadBalancer) URL(elems ...string) (string, string, error) {
 // It returns an error if the list of hosts is empty
 // but not if there are no non-suspended hosts.
 func (lb *LoadBalancer) Host() (string, error) {
-	switch len(lb.Hosts) {
+	if lb.Selector != nil {
+		return lb.selectHost()
+	}
+
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	switch len(lb.hosts) {
 	case 0:
 		return "", errors.New("https: no hosts provided")
 	case 1:
-		return lb.Hosts[0], nil
+		return lb.hosts[0], nil
 	default:
-		t, r := timeout(lb.Timeout), rand.Intn(len(lb.Hosts))
-
-		lb.mu.RLock()
-		defer lb.mu.RUnlock()
+		r := rand.Intn(len(lb.hosts))
 
 		now := time.Now()
-		for i := 0; i < len(lb.Hosts); i++ {
-			if timeout, ok := lb.timeout[lb.Hosts[r]]; !ok || now.Sub(timeout) > t {
-				return lb.Hosts[r], nil
+		for i := 0; i < len(lb.hosts); i++ {
+			if lb.available(lb.hosts[r], false, now) {
+				return lb.hosts[r], nil
 			}
-			r = (r + 1) % len(lb.Hosts)
+			r = (r + 1) % len(lb.hosts)
 		}
-		return lb.Hosts[r], nil
+		return lb.hosts[r], nil
+	}
+}
+
+// selectHost returns the host selected by the Selector
+// among all hosts that are not excluded. If all hosts are
+// excluded, it selects among all hosts.
+func (lb *LoadBalancer) selectHost() (string, error) {
+	lb.mu.RLock()
+	var (
+		now   = time.Now()
+		hosts = make([]string, 0, len(lb.hosts))
+	)
+	for _, host := range lb.hosts {
+		if lb.available(host, false, now) {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 {
+		hosts = append(hosts, lb.hosts...)
+	}
+	lb.mu.RUnlock()
+
+	switch len(hosts) {
+	case 0:
+		return "", errors.New("https: no hosts provided")
+	case 1:
+		return hosts[0], nil
+	default:
+		return lb.Selector.Select(hosts), nil
 	}
 }
 
+// CloseIdleConnections closes any idle connections of the
+// underlying RoundTripper.
+func (lb *LoadBalancer) CloseIdleConnections() { closeIdleConnections(lb.RoundTripper) }
+
 // RoundTrip executes the HTTP request and returns the corresponding
 // response on success.
 //
@@ -111,47 +258,282 @@ func (lb *LoadBalancer) Host() (string, error) {
 // is reached.
 // Hosts, for which requests fail, are temporarily excluded and no longer
 // selected for subsequent requests or retries.
+//
+// Requests that may change state on the server, i.e. requests that
+// are not read-only, are only retried if they have not been sent,
+// for example since no connection could be established. Otherwise,
+// retrying them may apply their changes twice.
+//
+// Without a retry Policy, RoundTrip retries requests immediately and
+// at most once per host. Responses are never retried.
 func (lb *LoadBalancer) RoundTrip(req *http.Request) (*http.Response, error) {
-	resp, err := lb.RoundTripper.RoundTrip(req)
-	if err != nil && isRetryable(err) {
-		r := slices.Index(lb.Hosts, req.URL.Host)
-		if r < 0 {
-			return resp, err
+	hosts := lb.Hosts()
+	if !slices.Contains(hosts, req.URL.Host) {
+		return traceRoundTrip(lb.RoundTripper, lb.Tracer, req, 0)
+	}
+
+	var (
+		policy   = lb.Policy
+		attempts = len(hosts)
+		hedge    = policy != nil && policy.Hedge && isReadOnly(req) && isReplayable(req)
+	)
+	if policy != nil && policy.MaxAttempts > 0 {
+		attempts = policy.MaxAttempts
+	}
+
+	var (
+		res   attempt
+		host  = req.URL.Host
+		write = !isReadOnly(req)
+		retry = 0
+	)
+	if lb.excluded(host, write) { // For example, host is not ready for writes
+		if next := lb.next(hosts, host, write); next != "" {
+			host = next
+		}
+	}
+	for {
+		if hedge && retry == 0 {
+			var n int
+			res, n = lb.hedge(req, hosts, host)
+			retry += n - 1
+		} else {
+			res = lb.send(req.Context(), req, host, retry)
 		}
+		req.URL.Host = res.host
 
-		now := time.Now()
-		lb.mu.Lock()
-		if lb.timeout == nil {
-			lb.timeout = map[string]time.Time{}
+		if res.err != nil && isRetryable(res.err) {
+			lb.fail(res.host, res.err)
 		}
-		lb.timeout[req.URL.Host] = now
-		lb.mu.Unlock()
+		retryAfter, ok := lb.isRetryable(req, res)
+		if !ok {
+			return res.resp, res.err
+		}
+
+		retry++
+		next := lb.next(hosts, res.host, write)
+		if retry >= attempts || next == "" {
+			return res.resp, res.err
+		}
+		delay, ok := lb.backoff(retry, retryAfter)
+		if !ok {
+			return res.resp, res.err
+		}
+		closeResponseBody(res.resp)
+
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+		if lb.OnRetry != nil {
+			lb.OnRetry(next)
+		}
+		host = next
+	}
+}
 
-		t := timeout(lb.Timeout)
-		for i := 1; i < len(lb.Hosts); i++ {
-			r = (r + 1) % len(lb.Hosts)
+// Selector selects the host a LoadBalancer sends a request
+// to. It must be safe for concurrent use.
+type Selector interface {
+	// Select returns one of hosts. The hosts are
+	// not excluded and contain at least two hosts.
+	Select(hosts []string) string
 
-			lb.mu.RLock()
-			timeout, ok := lb.timeout[lb.Hosts[r]]
-			lb.mu.RUnlock()
+	// Begin is called whenever a request is sent to
+	// host. The returned function, if not nil, is called
+	// once a response has been received or sending the
+	// request has failed.
+	Begin(host string) func(latency time.Duration, err error)
+}
 
-			if ok && now.Sub(timeout) < t {
-				continue
-			}
-			closeResponseBody(resp)
+// attempt is the result of sending a request to a host.
+type attempt struct {
+	host string
+	resp *http.Response
+	err  error
+}
 
-			req.URL.Host = lb.Hosts[r]
-			resp, err = lb.RoundTripper.RoundTrip(req)
-			if err == nil || !isRetryable(err) {
-				return resp, err
-			}
+// send sends a copy of req to host. The request's context is
+// derived from ctx and is canceled once the response body is
+// closed or, if the Policy has an AttemptTimeout, when no
+// response has been received in time.
+func (lb *LoadBalancer) send(ctx context.Context, req *http.Request, host string, retry int) attempt {
+	ctx, cancel := context.WithCancel(ctx)
+
+	r := req.Clone(ctx)
+	r.URL.Host = host
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return attempt{host: host, err: err}
+		}
+		r.Body = body
+	}
+
+	var timer *time.Timer
+	if lb.Policy != nil && lb.Policy.AttemptTimeout > 0 {
+		timer = time.AfterFunc(lb.Policy.AttemptTimeout, cancel)
+	}
+
+	var done func(time.Duration, error)
+	if lb.Selector != nil {
+		done = lb.Selector.Begin(host)
+	}
+
+	start := time.Now()
+	resp, err := traceRoundTrip(lb.RoundTripper, lb.Tracer, r, retry)
+	if timer != nil && !timer.Stop() && ctx.Err() != nil && req.Context().Err() == nil {
+		closeResponseBody(resp)
+		resp, err = nil, errAttemptTimeout
+	}
+	latency := time.Since(start)
+	if done != nil {
+		done(latency, err)
+	}
+	if err != nil {
+		cancel()
+		return attempt{host: host, err: err}
+	}
+
+	if isUnavailable(resp.StatusCode) {
+		lb.fail(host, errors.New("https: host responded with "+resp.Status))
+	} else {
+		if isReadOnly(req) {
+			lb.latency.Add(latency)
+		}
+		lb.succeed(host, latency)
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return attempt{host: host, resp: resp}
+}
+
+// isRetryable reports whether the request should be retried after
+// the given attempt. If the KMS server asked the client to retry
+// after some time, it returns this duration.
+func (lb *LoadBalancer) isRetryable(req *http.Request, res attempt) (time.Duration, bool) {
+	if req.Context().Err() != nil || !isReplayable(req) {
+		return 0, false
+	}
+	if res.err != nil {
+		if !isReadOnly(req) && !NotSent(res.err) {
+			return 0, false
+		}
+		return 0, isRetryable(res.err)
+	}
+	if lb.Policy == nil {
+		return 0, false
+	}
+
+	// A server responds with 429 before handling a request.
+	// However, a 503 may be caused by a failure while handling
+	// the request. Hence, only read-only requests are safe to
+	// retry.
+	switch res.resp.StatusCode {
+	case http.StatusTooManyRequests:
+	case http.StatusServiceUnavailable:
+		if !isReadOnly(req) {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	return parseRetryAfter(res.resp.Header.Get("Retry-After")), true
+}
+
+// next returns the next host after host that is not
+// excluded, or the empty string if all are excluded.
+// It may return host itself if it's not excluded. If
+// write is true, hosts not ready for writes are excluded.
+func (lb *LoadBalancer) next(hosts []string, host string, write bool) string {
+	r := slices.Index(hosts, host)
+	if r < 0 {
+		return ""
+	}
+
+	now := time.Now()
+
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	for i := 1; i <= len(hosts); i++ {
+		next := hosts[(r+i)%len(hosts)]
+		if lb.available(next, write, now) {
+			return next
+		}
+	}
+	return ""
+}
+
+// excluded reports whether host is currently excluded. If
+// write is true, hosts not ready for writes are excluded.
+func (lb *LoadBalancer) excluded(host string, write bool) bool {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	return !lb.available(host, write, time.Now())
+}
+
+// available reports whether host is neither excluded since a
+// request to it failed nor since it is not ready. If write is
+// true, host must be ready for writes as well. The caller must
+// hold lb.mu.
+func (lb *LoadBalancer) available(host string, write bool, now time.Time) bool {
+	if r, ok := lb.ready[host]; ok && (!r.read || write && !r.write) {
+		return false
+	}
+	t, ok := lb.timeout[host]
+	return !ok || now.Sub(t) >= timeout(lb.Timeout)
+}
+
+// readiness is the result of a health check.
+type readiness struct {
+	read, write bool
+}
+
+// SetReady sets whether host is ready to serve requests and,
+// separately, write requests, as determined by a health check.
+//
+// Hosts that are not ready are excluded until they are ready
+// again, independent of the Timeout. Hosts that are not ready
+// for writes are excluded for requests that aren't read-only.
+// A host that is ready is no longer excluded even if requests
+// to it have failed before.
+func (lb *LoadBalancer) SetReady(host string, ready, writeReady bool) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	if !slices.Contains(lb.hosts, host) {
+		return
+	}
+	if lb.ready == nil {
+		lb.ready = map[string]readiness{}
+	}
+	lb.ready[host] = readiness{read: ready, write: ready && writeReady}
+	if ready {
+		delete(lb.timeout, host)
+		if s, ok := lb.stats[host]; ok {
+			s.consecutive, s.probes = 0, 0
+		}
+	}
+}
+
+// Excluded returns a list of all hosts that are currently
+// excluded since a request to them failed or since they
+// are not ready.
+func (lb *LoadBalancer) Excluded() []string {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
 
-			lb.mu.Lock()
-			lb.timeout[req.URL.Host] = time.Now()
-			lb.mu.Unlock()
+	var (
+		now   = time.Now()
+		hosts []string
+	)
+	for _, host := range lb.hosts {
+		if !lb.available(host, false, now) {
+			hosts = append(hosts, host)
 		}
 	}
-	return resp, err
+	return hosts
 }
 
 func timeout(d time.Duration) time.Duration {
@@ -161,6 +543,32 @@ func timeout(d time.Duration) time.Duration {
 	return d
 }
 
+// NotSent reports whether err, returned when sending a request,
+// indicates that the request has not been sent to the server. For
+// example, since the server is not reachable or the TLS handshake
+// has failed.
+func NotSent(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && (opErr.Op == "dial" || opErr.Op == "proxyconnect") {
+		return true
+	}
+
+	var (
+		recordErr  tls.RecordHeaderError
+		alertErr   tls.AlertError
+		verifyErr  *tls.CertificateVerificationError
+		authErr    x509.UnknownAuthorityError
+		hostErr    x509.HostnameError
+		invalidErr x509.CertificateInvalidError
+	)
+	return errors.As(err, &recordErr) || errors.As(err, &alertErr) || errors.As(err, &verifyErr) ||
+		errors.As(err, &authErr) || errors.As(err, &hostErr) || errors.As(err, &invalidErr)
+}
+
 func isRetryable(err error) bool {
 	if err == nil {
 		return false
@@ -0,0 +1,268 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package https
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadBalancer_Retry(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body, _ := io.ReadAll(r.Body); string(body) != "Hello World" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if calls.Add(1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	const Unreachable = "127.0.0.1:1"
+	lb := &LoadBalancer{
+		RoundTripper: http.DefaultTransport,
+		Policy: &RetryPolicy{
+			MaxAttempts: 4,
+			BaseDelay:   time.Millisecond,
+		},
+	}
+	lb.SetHosts([]string{Unreachable, host(srv)})
+
+	req := newRequest(t, http.MethodPost, Unreachable, "Hello World")
+	resp, err := lb.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Invalid status code: got '%d' - want '%d'", resp.StatusCode, http.StatusOK)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("Invalid number of attempts: got '%d' - want '%d'", n, 3)
+	}
+	if req.URL.Host != host(srv) {
+		t.Fatalf("Invalid request host: got '%s' - want '%s'", req.URL.Host, host(srv))
+	}
+	if excluded := lb.Excluded(); len(excluded) != 1 || excluded[0] != Unreachable {
+		t.Fatalf("Invalid excluded hosts: got '%v' - want '%v'", excluded, []string{Unreachable})
+	}
+}
+
+func TestLoadBalancer_RetryWrite(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack() // Close the connection without response
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	defer broken.Close()
+
+	const Unreachable = "127.0.0.1:1"
+	for i, test := range retryWriteTests {
+		lb := &LoadBalancer{RoundTripper: http.DefaultTransport}
+		lb.SetHosts([]string{Unreachable, host(srv), host(broken)})
+		calls.Store(0)
+
+		req := newRequest(t, test.Method, Unreachable, "Hello World")
+		if test.Broken {
+			req = newRequest(t, test.Method, host(broken), "Hello World")
+		}
+		if test.ReadOnly {
+			req = req.WithContext(WithReadOnly(req.Context()))
+		}
+
+		resp, err := lb.RoundTrip(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if retried := calls.Load() > 0; retried != test.Retried {
+			t.Fatalf("Test %d: got retried '%v' - want '%v': %v", i, retried, test.Retried, err)
+		}
+		if !test.Retried && NotSent(err) {
+			t.Fatalf("Test %d: request has been sent: got '%v'", i, err)
+		}
+	}
+}
+
+var retryWriteTests = []struct {
+	Method   string
+	ReadOnly bool
+	Broken   bool // Send the request to the broken server first
+	Retried  bool
+}{
+	{Method: http.MethodPost, Retried: true},                               // 0
+	{Method: http.MethodPost, Broken: true, Retried: false},                // 1
+	{Method: http.MethodPost, ReadOnly: true, Broken: true, Retried: true}, // 2
+	{Method: http.MethodGet, Broken: true, Retried: true},                  // 3
+	{Method: http.MethodPut, Retried: true},                                // 4
+	{Method: http.MethodPut, Broken: true, Retried: false},                 // 5
+}
+
+func TestLoadBalancer_RetryAfter(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	lb := &LoadBalancer{
+		RoundTripper: http.DefaultTransport,
+		Policy: &RetryPolicy{
+			MaxAttempts: 3,
+			MaxDelay:    time.Second,
+		},
+	}
+	lb.SetHosts([]string{host(srv)})
+
+	resp, err := lb.RoundTrip(newRequest(t, http.MethodGet, host(srv), ""))
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Invalid status code: got '%d' - want '%d'", resp.StatusCode, http.StatusTooManyRequests)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("Request should not have been retried: got '%d' attempts", n)
+	}
+}
+
+func TestLoadBalancer_AttemptTimeout(t *testing.T) {
+	t.Parallel()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer fast.Close()
+
+	lb := &LoadBalancer{
+		RoundTripper: http.DefaultTransport,
+		Policy: &RetryPolicy{
+			AttemptTimeout: 50 * time.Millisecond,
+		},
+	}
+	lb.SetHosts([]string{host(slow), host(fast)})
+
+	req := newRequest(t, http.MethodGet, host(slow), "")
+	resp, err := lb.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	resp.Body.Close()
+
+	if req.URL.Host != host(fast) {
+		t.Fatalf("Invalid request host: got '%s' - want '%s'", req.URL.Host, host(fast))
+	}
+}
+
+func TestLoadBalancer_Hedge(t *testing.T) {
+	t.Parallel()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "Hello World")
+	}))
+	defer fast.Close()
+
+	lb := &LoadBalancer{
+		RoundTripper: http.DefaultTransport,
+		Policy:       &RetryPolicy{Hedge: true},
+	}
+	lb.SetHosts([]string{host(slow), host(fast)})
+	for i := 0; i < 20; i++ {
+		lb.latency.Add(10 * time.Millisecond)
+	}
+
+	start := time.Now()
+	req := newRequest(t, http.MethodPost, host(slow), "")
+	req = req.WithContext(WithReadOnly(req.Context()))
+	resp, err := lb.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("Request has not been hedged: took %v", d)
+	}
+	if body, _ := io.ReadAll(resp.Body); string(body) != "Hello World" {
+		t.Fatalf("Invalid response body: got '%s' - want '%s'", body, "Hello World")
+	}
+	if req.URL.Host != host(fast) {
+		t.Fatalf("Invalid request host: got '%s' - want '%s'", req.URL.Host, host(fast))
+	}
+	if excluded := lb.Excluded(); len(excluded) != 0 {
+		t.Fatalf("Hedging should not exclude hosts: got '%v'", excluded)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range parseRetryAfterTests {
+		if d := parseRetryAfter(test.Value); d != test.Duration {
+			t.Fatalf("Test %d: got '%v' - want '%v'", i, d, test.Duration)
+		}
+	}
+}
+
+var parseRetryAfterTests = []struct {
+	Value    string
+	Duration time.Duration
+}{
+	{Value: "", Duration: 0},
+	{Value: "0", Duration: 0},
+	{Value: "120", Duration: 2 * time.Minute},
+	{Value: "-1", Duration: 0},
+	{Value: "Wed, 21 Oct 2015 07:28:00 GMT", Duration: 0}, // in the past
+	{Value: "invalid", Duration: 0},
+}
+
+func host(srv *httptest.Server) string { return strings.TrimPrefix(srv.URL, "http://") }
+
+func newRequest(t *testing.T, method, host, body string) *http.Request {
+	u := url.URL{Scheme: "http", Host: host, Path: "/"}
+	req, err := http.NewRequestWithContext(context.Background(), method, u.String(), bytes.NewReader([]byte(body)))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	return req
+}
@@ -0,0 +1,91 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package https
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingTransport is an http.RoundTripper that records
+// every request as span and propagates its W3C trace
+// context to the host.
+type TracingTransport struct {
+	// Underlying RoundTripper used to send requests.
+	http.RoundTripper
+
+	// Tracer used to record spans. If nil, no spans
+	// are recorded.
+	Tracer trace.Tracer
+}
+
+// RoundTrip executes the HTTP request and returns the
+// corresponding response on success.
+func (t *TracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return traceRoundTrip(t.RoundTripper, t.Tracer, req, 0)
+}
+
+// CloseIdleConnections closes any idle connections of the
+// underlying RoundTripper.
+func (t *TracingTransport) CloseIdleConnections() { closeIdleConnections(t.RoundTripper) }
+
+// closeIdleConnections closes any idle connections of rt
+// if it keeps connections alive, like an http.Transport.
+func closeIdleConnections(rt http.RoundTripper) {
+	type closeIdler interface {
+		CloseIdleConnections()
+	}
+	if c, ok := rt.(closeIdler); ok {
+		c.CloseIdleConnections()
+	}
+}
+
+// traceRoundTrip sends req using rt. If tracer is not nil, it
+// records the attempt as child span of the span within the
+// request context, if any, and injects the W3C trace context
+// of the attempt into the headers of a copy of req. As an
+// http.RoundTripper, it must not modify req itself.
+//
+// The span's "kms.outcome" attribute is "host_error" if no
+// response has been received, "status_error" for responses
+// with a 4xx or 5xx status code and "ok" otherwise.
+func traceRoundTrip(rt http.RoundTripper, tracer trace.Tracer, req *http.Request, retry int) (*http.Response, error) {
+	if tracer == nil {
+		return rt.RoundTrip(req)
+	}
+
+	ctx, span := tracer.Start(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("kms.host", req.URL.Host),
+			attribute.Int("kms.retry", retry),
+		),
+	)
+	defer span.End()
+
+	req = req.Clone(ctx)
+	propagation.TraceContext{}.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		span.SetAttributes(attribute.String("kms.outcome", "host_error"))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetAttributes(attribute.String("kms.outcome", "status_error"))
+		span.SetStatus(codes.Error, resp.Status)
+	} else {
+		span.SetAttributes(attribute.String("kms.outcome", "ok"))
+	}
+	return resp, nil
+}
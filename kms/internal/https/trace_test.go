@@ -0,0 +1,75 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package https
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestTracingTransport(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Traceparent") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := http.Client{
+		Transport: &TracingTransport{
+			RoundTripper: http.DefaultTransport,
+			Tracer:       sdktrace.NewTracerProvider().Tracer("test"),
+		},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Trace context has not been propagated: got '%s' - want '%s'", resp.Status, "200 OK")
+	}
+	if v := req.Header.Get("Traceparent"); v != "" {
+		t.Fatalf("Request has been modified: got traceparent '%s' - want none", v)
+	}
+}
+
+func TestCloseIdleConnections(t *testing.T) {
+	t.Parallel()
+
+	rt := &closeIdleTransport{}
+	for i, client := range []http.Client{
+		{Transport: &TracingTransport{RoundTripper: rt}}, // 0
+		{Transport: &LoadBalancer{RoundTripper: rt}},     // 1
+	} {
+		client.CloseIdleConnections()
+		if rt.N != i+1 {
+			t.Fatalf("Test %d: idle connections have not been closed: got %d calls - want %d", i, rt.N, i+1)
+		}
+	}
+}
+
+// closeIdleTransport counts how often its idle connections
+// have been closed.
+type closeIdleTransport struct {
+	http.RoundTripper
+
+	N int
+}
+
+func (t *closeIdleTransport) CloseIdleConnections() { t.N++ }
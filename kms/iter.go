@@ -76,3 +76,18 @@ func (i *Iter[T]) Next(ctx context.Context) (item T, err error) {
 	i.items = i.items[1:]
 	return item, nil
 }
+
+// Collect seeks to the position specified by req, like SeekTo,
+// and returns all items from this position until the end of the
+// stream.
+func (i *Iter[T]) Collect(ctx context.Context, req *ListRequest) ([]T, error) {
+	var items []T
+	item, err := i.SeekTo(ctx, req)
+	for ; err == nil; item, err = i.Next(ctx) {
+		items = append(items, item)
+	}
+	if err != io.EOF {
+		return nil, err
+	}
+	return items, nil
+}
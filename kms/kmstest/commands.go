@@ -0,0 +1,1040 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package kmstest
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"aead.dev/mtls"
+	"github.com/minio/kms-go/kms"
+	"github.com/minio/kms-go/kms/cmds"
+	pb "github.com/minio/kms-go/kms/protobuf"
+	"golang.org/x/crypto/chacha20poly1305"
+	"google.golang.org/protobuf/proto"
+)
+
+// Errors returned by a Server that have no corresponding
+// error in the kms package.
+var (
+	errNodeExists     = kms.Error{Code: http.StatusConflict, Err: "node already exists"}
+	errNodeNotFound   = kms.Error{Code: http.StatusNotFound, Err: "node does not exist"}
+	errHSMExists      = kms.Error{Code: http.StatusConflict, Err: "hsm already exists"}
+	errHSMNotFound    = kms.Error{Code: http.StatusNotFound, Err: "hsm does not exist"}
+	errInvalidKeyType = kms.Error{Code: http.StatusBadRequest, Err: "invalid key type"}
+	errInvalidKeyLen  = kms.Error{Code: http.StatusBadRequest, Err: "invalid key length"}
+	errNoIdentity     = kms.Error{Code: http.StatusBadRequest, Err: "identity is empty"}
+)
+
+// listLimit is the max. number of items returned by list
+// commands if the request does not specify a limit.
+const listLimit = 250
+
+type enclave struct {
+	CreatedAt  time.Time
+	CreatedBy  mtls.Identity
+	Keys       map[string]*keyRing
+	Policies   map[string]*policy
+	Identities map[mtls.Identity]*identity
+}
+
+func newEnclave(createdAt time.Time, createdBy mtls.Identity) *enclave {
+	return &enclave{
+		CreatedAt:  createdAt,
+		CreatedBy:  createdBy,
+		Keys:       map[string]*keyRing{},
+		Policies:   map[string]*policy{},
+		Identities: map[mtls.Identity]*identity{},
+	}
+}
+
+// keyRing is a set of secret key versions. Versions
+// are never reused, even after they got deleted.
+type keyRing struct {
+	Versions map[int]*secretKey
+	N        int // The most recent version ever created
+}
+
+// Latest returns the latest key version present.
+func (r *keyRing) Latest() (int, *secretKey) {
+	var latest int
+	for v := range r.Versions {
+		latest = max(latest, v)
+	}
+	return latest, r.Versions[latest]
+}
+
+// Get returns the key version v or the latest version
+// if v is 0.
+func (r *keyRing) Get(v int) (int, *secretKey, error) {
+	if v == 0 {
+		v, key := r.Latest()
+		return v, key, nil
+	}
+	key, ok := r.Versions[v]
+	if !ok {
+		return 0, nil, kms.ErrKeyNotFound
+	}
+	return v, key, nil
+}
+
+type secretKey struct {
+	Type      kms.SecretKeyType
+	Key       []byte
+	CreatedAt time.Time
+	CreatedBy mtls.Identity
+}
+
+// Encrypt encrypts the plaintext and returns the nonce
+// followed by the ciphertext.
+func (k *secretKey) Encrypt(plaintext, associatedData []byte) ([]byte, error) {
+	c, err := k.aead()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, c.NonceSize(), c.NonceSize()+len(plaintext)+c.Overhead())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return c.Seal(nonce, nonce, plaintext, associatedData), nil
+}
+
+// Decrypt decrypts a ciphertext produced by Encrypt.
+func (k *secretKey) Decrypt(ciphertext, associatedData []byte) ([]byte, error) {
+	c, err := k.aead()
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < c.NonceSize() {
+		return nil, kms.ErrDecrypt
+	}
+
+	nonce, ciphertext := ciphertext[:c.NonceSize()], ciphertext[c.NonceSize():]
+	plaintext, err := c.Open(nil, nonce, ciphertext, associatedData)
+	if err != nil {
+		return nil, kms.ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+func (k *secretKey) aead() (cipher.AEAD, error) {
+	switch k.Type {
+	case kms.AES256:
+		block, err := aes.NewCipher(k.Key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case kms.ChaCha20:
+		return chacha20poly1305.New(k.Key)
+	default:
+		return nil, errInvalidKeyType
+	}
+}
+
+type policy struct {
+	kms.Policy
+	CreatedAt time.Time
+	CreatedBy mtls.Identity
+}
+
+type identity struct {
+	Privilege        kms.Privilege
+	Policy           string
+	CreatedAt        time.Time
+	CreatedBy        mtls.Identity
+	IsServiceAccount bool
+	ServiceAccounts  []mtls.Identity
+	Tags             map[string]string
+}
+
+// exec decodes the next command from b, executes it and appends
+// the command response, if any, to resp. It returns resp and the
+// remaining, not yet decoded, commands.
+func (s *Server) exec(req *request, cmd cmds.Command, resp, b []byte) ([]byte, []byte, error) {
+	switch cmd {
+	case cmds.ClusterStatus:
+		return handle(resp, b, cmd, func(*pb.ClusterStatusRequest) (proto.Message, error) { return s.clusterStatus(req) })
+	case cmds.ClusterAddNode:
+		return handle(resp, b, cmd, func(m *pb.AddClusterNodeRequest) (proto.Message, error) { return nil, s.addNode(req, m) })
+	case cmds.ClusterRemoveNode:
+		return handle(resp, b, cmd, func(m *pb.RemoveClusterNodeRequest) (proto.Message, error) { return nil, s.removeNode(req, m) })
+	case cmds.ClusterEdit:
+		return handle(resp, b, cmd, func(m *pb.EditClusterRequest) (proto.Message, error) { return nil, s.editCluster(req, m) })
+	case cmds.ClusterAddHSM:
+		return handle(resp, b, cmd, func(m *pb.AddHSMRequest) (proto.Message, error) { return nil, s.addHSM(req, m) })
+	case cmds.ClusterRemoveHSM:
+		return handle(resp, b, cmd, func(m *pb.RemoveHSMRequest) (proto.Message, error) { return nil, s.removeHSM(req, m) })
+
+	case cmds.EnclaveCreate:
+		return handle(resp, b, cmd, func(m *pb.CreateEnclaveRequest) (proto.Message, error) { return nil, s.createEnclave(req, m) })
+	case cmds.EnclaveDelete:
+		return handle(resp, b, cmd, func(m *pb.DeleteEnclaveRequest) (proto.Message, error) { return nil, s.deleteEnclave(req, m) })
+	case cmds.EnclaveStatus:
+		return handle(resp, b, cmd, func(m *pb.EnclaveStatusRequest) (proto.Message, error) { return s.enclaveStatus(req, m) })
+	case cmds.EnclaveList:
+		return handle(resp, b, cmd, func(m *pb.ListRequest) (proto.Message, error) { return s.listEnclaves(req, m) })
+
+	case cmds.KeyCreate:
+		return handle(resp, b, cmd, func(m *pb.CreateKeyRequest) (proto.Message, error) { return nil, s.createKey(req, m) })
+	case cmds.KeyImport:
+		return handle(resp, b, cmd, func(m *pb.ImportKeyRequest) (proto.Message, error) { return nil, s.importKey(req, m) })
+	case cmds.KeyDelete:
+		return handle(resp, b, cmd, func(m *pb.DeleteKeyRequest) (proto.Message, error) { return nil, s.deleteKey(req, m) })
+	case cmds.KeyStatus:
+		return handle(resp, b, cmd, func(m *pb.KeyStatusRequest) (proto.Message, error) { return s.keyStatus(req, m) })
+	case cmds.KeyList:
+		return handle(resp, b, cmd, func(m *pb.ListRequest) (proto.Message, error) { return s.listKeys(req, m) })
+	case cmds.KeyListVersions:
+		return handle(resp, b, cmd, func(m *pb.ListKeyVersionsRequest) (proto.Message, error) { return s.listKeyVersions(req, m) })
+	case cmds.KeyEncrypt:
+		return handle(resp, b, cmd, func(m *pb.EncryptRequest) (proto.Message, error) { return s.encrypt(req, m) })
+	case cmds.KeyDecrypt:
+		return handle(resp, b, cmd, func(m *pb.DecryptRequest) (proto.Message, error) { return s.decrypt(req, m) })
+	case cmds.KeyGenerate:
+		return handle(resp, b, cmd, func(m *pb.GenerateKeyRequest) (proto.Message, error) { return s.generateKey(req, m) })
+	case cmds.KeyMAC:
+		return handle(resp, b, cmd, func(m *pb.MACRequest) (proto.Message, error) { return s.mac(req, m) })
+
+	case cmds.PolicyCreate:
+		return handle(resp, b, cmd, func(m *pb.CreatePolicyRequest) (proto.Message, error) { return nil, s.createPolicy(req, m) })
+	case cmds.PolicyAssign:
+		return handle(resp, b, cmd, func(m *pb.AssignPolicyRequest) (proto.Message, error) { return nil, s.assignPolicy(req, m) })
+	case cmds.PolicyDelete:
+		return handle(resp, b, cmd, func(m *pb.DeletePolicyRequest) (proto.Message, error) { return nil, s.deletePolicy(req, m) })
+	case cmds.PolicyGet:
+		return handle(resp, b, cmd, func(m *pb.PolicyRequest) (proto.Message, error) { return s.getPolicy(req, m) })
+	case cmds.PolicyStatus:
+		return handle(resp, b, cmd, func(m *pb.PolicyRequest) (proto.Message, error) { return s.policyStatus(req, m) })
+	case cmds.PolicyList:
+		return handle(resp, b, cmd, func(m *pb.ListRequest) (proto.Message, error) { return s.listPolicies(req, m) })
+
+	case cmds.IdentityCreate:
+		return handle(resp, b, cmd, func(m *pb.CreateIdentityRequest) (proto.Message, error) { return nil, s.createIdentity(req, m) })
+	case cmds.IdentityDelete:
+		return handle(resp, b, cmd, func(m *pb.DeleteIdentityRequest) (proto.Message, error) { return nil, s.deleteIdentity(req, m) })
+	case cmds.IdentityGet:
+		return handle(resp, b, cmd, func(m *pb.IdentityRequest) (proto.Message, error) { return s.getIdentity(req, m) })
+	case cmds.IdentityList:
+		return handle(resp, b, cmd, func(m *pb.ListRequest) (proto.Message, error) { return s.listIdentities(req, m) })
+	default:
+		return nil, nil, kms.Error{Code: http.StatusBadRequest, Err: "unsupported command '" + cmd.String() + "'"}
+	}
+}
+
+// handle decodes the next command argument from b and calls fn.
+// It appends the response returned by fn, if any, to resp.
+func handle[M any, P pb.Pointer[M]](resp, b []byte, cmd cmds.Command, fn func(P) (proto.Message, error)) ([]byte, []byte, error) {
+	var m M
+	var p P = &m
+
+	b, err := cmds.DecodePB(b, cmd, p)
+	if err != nil {
+		return nil, nil, kms.Error{Code: http.StatusBadRequest, Err: err.Error()}
+	}
+
+	msg, err := fn(p)
+	if err != nil {
+		return resp, b, err // Return the remaining commands to support partial results
+	}
+	if msg != nil {
+		if resp, err = cmds.EncodePB(resp, cmd, msg); err != nil {
+			return nil, nil, err
+		}
+	}
+	return resp, b, nil
+}
+
+// authorize reports whether the identity that sent req is allowed
+// to execute cmd on the given resource, like a key name. It returns
+// kms.ErrPermission if not.
+func (s *Server) authorize(req *request, cmd cmds.Command, resource string) error {
+	if req.Identity == s.root {
+		return nil
+	}
+
+	var id *identity
+	if cmd.IsCluster() {
+		for _, e := range s.enclaves {
+			if id = e.Identities[req.Identity]; id != nil {
+				break
+			}
+		}
+	} else if e, ok := s.enclaves[req.Enclave]; ok {
+		id = e.Identities[req.Identity]
+	}
+	if id == nil {
+		return kms.ErrPermission
+	}
+
+	switch id.Privilege {
+	case kms.SysAdmin:
+		return nil
+	case kms.Admin:
+		if cmd.IsCluster() {
+			return kms.ErrPermission
+		}
+		return nil
+	default:
+		if cmd.IsCluster() {
+			return kms.ErrPermission
+		}
+
+		p, ok := s.enclaves[req.Enclave].Policies[id.Policy]
+		if !ok {
+			return kms.ErrPermission
+		}
+		return p.Verify(cmd, resource, &kms.AccessRequest{
+			SourceIP:       req.Source,
+			Time:           time.Now(),
+			BatchSize:      req.BatchSize,
+			AssociatedData: req.AssociatedData,
+		})
+	}
+}
+
+// enclave returns the enclave referenced by req after
+// checking that the request identity is allowed to
+// execute cmd on the resource within this enclave.
+func (s *Server) enclave(req *request, cmd cmds.Command, resource string) (*enclave, error) {
+	if err := s.authorize(req, cmd, resource); err != nil {
+		return nil, err
+	}
+
+	e, ok := s.enclaves[req.Enclave]
+	if !ok {
+		return nil, kms.ErrEnclaveNotFound
+	}
+	return e, nil
+}
+
+func (s *Server) clusterStatus(req *request) (*pb.ClusterStatusResponse, error) {
+	if err := s.authorize(req, cmds.ClusterStatus, ""); err != nil {
+		return nil, err
+	}
+	// All nodes, except the server itself, are added by
+	// clients but don't exist. Hence, they are down.
+	nodesDown := make(map[uint32]string, len(s.nodes))
+	for id, node := range s.nodes {
+		if id != 0 {
+			nodesDown[uint32(id)] = node
+		}
+	}
+	return &pb.ClusterStatusResponse{
+		NodesUp: map[uint32]*pb.ServerStatusResponse{
+			0: s.serverStatus(),
+		},
+		NodesDown: nodesDown,
+	}, nil
+}
+
+func (s *Server) addNode(req *request, m *pb.AddClusterNodeRequest) error {
+	if err := s.authorize(req, cmds.ClusterAddNode, m.Host); err != nil {
+		return err
+	}
+
+	var id int
+	for i, node := range s.nodes {
+		if node == m.Host {
+			return errNodeExists
+		}
+		id = max(id, i)
+	}
+	s.nodes[id+1] = m.Host
+	s.commit++
+	return nil
+}
+
+func (s *Server) removeNode(req *request, m *pb.RemoveClusterNodeRequest) error {
+	if err := s.authorize(req, cmds.ClusterRemoveNode, m.Host); err != nil {
+		return err
+	}
+
+	for id, node := range s.nodes {
+		if node == m.Host {
+			delete(s.nodes, id)
+			s.commit++
+			return nil
+		}
+	}
+	return errNodeNotFound
+}
+
+func (s *Server) editCluster(req *request, m *pb.EditClusterRequest) error {
+	if err := s.authorize(req, cmds.ClusterEdit, m.Host); err != nil {
+		return err
+	}
+
+	for _, id := range m.RemoveIDs {
+		delete(s.nodes, int(id))
+	}
+	s.commit++
+	return nil
+}
+
+func (s *Server) addHSM(req *request, m *pb.AddHSMRequest) error {
+	if err := s.authorize(req, cmds.ClusterAddHSM, m.Name); err != nil {
+		return err
+	}
+
+	if slices.Contains(s.hsms, m.Name) {
+		if !m.Overwrite {
+			return errHSMExists
+		}
+		return nil
+	}
+	s.hsms = append(s.hsms, m.Name)
+	s.commit++
+	return nil
+}
+
+func (s *Server) removeHSM(req *request, m *pb.RemoveHSMRequest) error {
+	if err := s.authorize(req, cmds.ClusterRemoveHSM, m.Name); err != nil {
+		return err
+	}
+
+	i := slices.Index(s.hsms, m.Name)
+	if i < 0 {
+		return errHSMNotFound
+	}
+	s.hsms = slices.Delete(s.hsms, i, i+1)
+	s.commit++
+	return nil
+}
+
+func (s *Server) createEnclave(req *request, m *pb.CreateEnclaveRequest) error {
+	if err := s.authorize(req, cmds.EnclaveCreate, m.Name); err != nil {
+		return err
+	}
+
+	if _, ok := s.enclaves[m.Name]; ok {
+		return kms.ErrEnclaveExists
+	}
+	s.enclaves[m.Name] = newEnclave(time.Now(), req.Identity)
+	s.commit++
+	return nil
+}
+
+func (s *Server) deleteEnclave(req *request, m *pb.DeleteEnclaveRequest) error {
+	if err := s.authorize(req, cmds.EnclaveDelete, m.Name); err != nil {
+		return err
+	}
+
+	if _, ok := s.enclaves[m.Name]; !ok {
+		return kms.ErrEnclaveNotFound
+	}
+	delete(s.enclaves, m.Name)
+	s.commit++
+	return nil
+}
+
+func (s *Server) enclaveStatus(req *request, m *pb.EnclaveStatusRequest) (*pb.EnclaveStatusResponse, error) {
+	if err := s.authorize(req, cmds.EnclaveStatus, m.Name); err != nil {
+		return nil, err
+	}
+
+	e, ok := s.enclaves[m.Name]
+	if !ok {
+		return nil, kms.ErrEnclaveNotFound
+	}
+	return &pb.EnclaveStatusResponse{
+		Name:      m.Name,
+		CreatedAt: pb.Time(e.CreatedAt),
+		CreatedBy: e.CreatedBy.String(),
+	}, nil
+}
+
+func (s *Server) listEnclaves(req *request, m *pb.ListRequest) (*pb.ListEnclavesResponse, error) {
+	if err := s.authorize(req, cmds.EnclaveList, m.Prefix); err != nil {
+		return nil, err
+	}
+
+	names, continueAt := list(keys(s.enclaves), m)
+	resp := &pb.ListEnclavesResponse{
+		Enclaves:   make([]*pb.EnclaveStatusResponse, 0, len(names)),
+		ContinueAt: continueAt,
+	}
+	for _, name := range names {
+		e := s.enclaves[name]
+		resp.Enclaves = append(resp.Enclaves, &pb.EnclaveStatusResponse{
+			Name:      name,
+			CreatedAt: pb.Time(e.CreatedAt),
+			CreatedBy: e.CreatedBy.String(),
+		})
+	}
+	return resp, nil
+}
+
+func (s *Server) createKey(req *request, m *pb.CreateKeyRequest) error {
+	e, err := s.enclave(req, cmds.KeyCreate, m.Name)
+	if err != nil {
+		return err
+	}
+
+	var r kms.CreateKeyRequest
+	if err = r.UnmarshalPB(m); err != nil {
+		return kms.Error{Code: http.StatusBadRequest, Err: err.Error()}
+	}
+	if r.Type == 0 {
+		r.Type = kms.AES256
+	}
+
+	ring, ok := e.Keys[r.Name]
+	if ok && !r.AddVersion {
+		return kms.ErrKeyExists
+	}
+	if !ok && r.AddVersion {
+		return kms.ErrKeyNotFound
+	}
+
+	key := make([]byte, 32)
+	if _, err = rand.Read(key); err != nil {
+		return err
+	}
+	if !ok {
+		ring = &keyRing{Versions: map[int]*secretKey{}}
+		e.Keys[r.Name] = ring
+	}
+	ring.N++
+	ring.Versions[ring.N] = &secretKey{
+		Type:      r.Type,
+		Key:       key,
+		CreatedAt: time.Now(),
+		CreatedBy: req.Identity,
+	}
+	s.commit++
+	return nil
+}
+
+func (s *Server) importKey(req *request, m *pb.ImportKeyRequest) error {
+	e, err := s.enclave(req, cmds.KeyImport, m.Name)
+	if err != nil {
+		return err
+	}
+
+	var r kms.ImportKeyRequest
+	if err = r.UnmarshalPB(m); err != nil {
+		return kms.Error{Code: http.StatusBadRequest, Err: err.Error()}
+	}
+	if r.Type == 0 {
+		r.Type = kms.AES256
+	}
+	if len(r.Key) != 32 {
+		return errInvalidKeyLen
+	}
+	if _, ok := e.Keys[r.Name]; ok {
+		return kms.ErrKeyExists
+	}
+
+	e.Keys[r.Name] = &keyRing{
+		Versions: map[int]*secretKey{
+			1: {
+				Type:      r.Type,
+				Key:       slices.Clone(r.Key),
+				CreatedAt: time.Now(),
+				CreatedBy: req.Identity,
+			},
+		},
+		N: 1,
+	}
+	s.commit++
+	return nil
+}
+
+func (s *Server) deleteKey(req *request, m *pb.DeleteKeyRequest) error {
+	e, err := s.enclave(req, cmds.KeyDelete, m.Name)
+	if err != nil {
+		return err
+	}
+
+	var r kms.DeleteKeyRequest
+	if err = r.UnmarshalPB(m); err != nil {
+		return kms.Error{Code: http.StatusBadRequest, Err: err.Error()}
+	}
+
+	ring, ok := e.Keys[r.Name]
+	if !ok {
+		return kms.ErrKeyNotFound
+	}
+	if r.AllVersions {
+		delete(e.Keys, r.Name)
+		s.commit++
+		return nil
+	}
+
+	version, _, err := ring.Get(r.Version)
+	if err != nil {
+		return err
+	}
+	delete(ring.Versions, version)
+	if len(ring.Versions) == 0 {
+		delete(e.Keys, r.Name)
+	}
+	s.commit++
+	return nil
+}
+
+func (s *Server) keyStatus(req *request, m *pb.KeyStatusRequest) (*pb.KeyStatusResponse, error) {
+	e, err := s.enclave(req, cmds.KeyStatus, m.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	ring, ok := e.Keys[m.Name]
+	if !ok {
+		return nil, kms.ErrKeyNotFound
+	}
+	version, key, err := ring.Get(int(m.Version))
+	if err != nil {
+		return nil, err
+	}
+	return keyStatusResponse(m.Name, version, key), nil
+}
+
+func (s *Server) listKeys(req *request, m *pb.ListRequest) (*pb.ListKeysResponse, error) {
+	e, err := s.enclave(req, cmds.KeyList, m.Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	names, continueAt := list(keys(e.Keys), m)
+	resp := &pb.ListKeysResponse{
+		Keys:       make([]*pb.KeyStatusResponse, 0, len(names)),
+		ContinueAt: continueAt,
+	}
+	for _, name := range names {
+		version, key := e.Keys[name].Latest()
+		resp.Keys = append(resp.Keys, keyStatusResponse(name, version, key))
+	}
+	return resp, nil
+}
+
+func (s *Server) listKeyVersions(req *request, m *pb.ListKeyVersionsRequest) (*pb.ListKeyVersionsResponse, error) {
+	e, err := s.enclave(req, cmds.KeyListVersions, m.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	ring, ok := e.Keys[m.Name]
+	if !ok {
+		return nil, kms.ErrKeyNotFound
+	}
+	versions := make([]int, 0, len(ring.Versions))
+	for v := range ring.Versions {
+		if v >= int(m.ContinueAt) {
+			versions = append(versions, v)
+		}
+	}
+	slices.Sort(versions)
+
+	limit := int(m.Limit)
+	if limit <= 0 || limit > listLimit {
+		limit = listLimit
+	}
+	resp := &pb.ListKeyVersionsResponse{}
+	if len(versions) > limit {
+		resp.ContinueAt = uint32(versions[limit])
+		versions = versions[:limit]
+	}
+	for _, v := range versions {
+		resp.Versions = append(resp.Versions, keyStatusResponse(m.Name, v, ring.Versions[v]))
+	}
+	return resp, nil
+}
+
+func (s *Server) encrypt(req *request, m *pb.EncryptRequest) (*pb.EncryptResponse, error) {
+	e, err := s.enclave(req.withAssociatedData(m.AssociatedData), cmds.KeyEncrypt, m.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	ring, ok := e.Keys[m.Name]
+	if !ok {
+		return nil, kms.ErrKeyNotFound
+	}
+	version, key, err := ring.Get(int(m.Version))
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := key.Encrypt(m.Plaintext, m.AssociatedData)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.EncryptResponse{
+		Version:    uint32(version),
+		Ciphertext: ciphertext,
+	}, nil
+}
+
+func (s *Server) decrypt(req *request, m *pb.DecryptRequest) (*pb.DecryptResponse, error) {
+	e, err := s.enclave(req.withAssociatedData(m.AssociatedData), cmds.KeyDecrypt, m.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	ring, ok := e.Keys[m.Name]
+	if !ok {
+		return nil, kms.ErrKeyNotFound
+	}
+	_, key, err := ring.Get(int(m.Version))
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := key.Decrypt(m.Ciphertext, m.AssociatedData)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.DecryptResponse{
+		Plaintext: plaintext,
+	}, nil
+}
+
+func (s *Server) generateKey(req *request, m *pb.GenerateKeyRequest) (*pb.GenerateKeyResponse, error) {
+	e, err := s.enclave(req.withAssociatedData(m.AssociatedData), cmds.KeyGenerate, m.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	length := int(m.Length)
+	if length == 0 {
+		length = 32
+	}
+	if length > 1024 {
+		return nil, errInvalidKeyLen
+	}
+
+	ring, ok := e.Keys[m.Name]
+	if !ok {
+		return nil, kms.ErrKeyNotFound
+	}
+	version, key, err := ring.Get(int(m.Version))
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := make([]byte, length)
+	if _, err = rand.Read(plaintext); err != nil {
+		return nil, err
+	}
+	ciphertext, err := key.Encrypt(plaintext, m.AssociatedData)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GenerateKeyResponse{
+		Version:    uint32(version),
+		Plaintext:  plaintext,
+		Ciphertext: ciphertext,
+	}, nil
+}
+
+func (s *Server) mac(req *request, m *pb.MACRequest) (*pb.MACResponse, error) {
+	e, err := s.enclave(req, cmds.KeyMAC, m.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	ring, ok := e.Keys[m.Name]
+	if !ok {
+		return nil, kms.ErrKeyNotFound
+	}
+	version, key, err := ring.Get(int(m.Version))
+	if err != nil {
+		return nil, err
+	}
+
+	h := hmac.New(sha256.New, key.Key)
+	h.Write(m.Message)
+	return &pb.MACResponse{
+		Version: uint32(version),
+		MAC:     h.Sum(nil),
+	}, nil
+}
+
+func (s *Server) createPolicy(req *request, m *pb.CreatePolicyRequest) error {
+	e, err := s.enclave(req, cmds.PolicyCreate, m.Name)
+	if err != nil {
+		return err
+	}
+
+	var r kms.CreatePolicyRequest
+	if err = r.UnmarshalPB(m); err != nil {
+		return kms.Error{Code: http.StatusBadRequest, Err: err.Error()}
+	}
+	e.Policies[r.Name] = &policy{
+		Policy:    kms.Policy{Allow: r.Allow, Deny: r.Deny},
+		CreatedAt: time.Now(),
+		CreatedBy: req.Identity,
+	}
+	s.commit++
+	return nil
+}
+
+func (s *Server) assignPolicy(req *request, m *pb.AssignPolicyRequest) error {
+	e, err := s.enclave(req, cmds.PolicyAssign, m.Policy)
+	if err != nil {
+		return err
+	}
+
+	var r kms.AssignPolicyRequest
+	if err = r.UnmarshalPB(m); err != nil {
+		return kms.Error{Code: http.StatusBadRequest, Err: err.Error()}
+	}
+	if _, ok := e.Policies[r.Policy]; !ok {
+		return kms.ErrPolicyNotFound
+	}
+	id, ok := e.Identities[r.Identity]
+	if !ok {
+		return kms.ErrIdentityNotFound
+	}
+	id.Policy = r.Policy
+	s.commit++
+	return nil
+}
+
+func (s *Server) deletePolicy(req *request, m *pb.DeletePolicyRequest) error {
+	e, err := s.enclave(req, cmds.PolicyDelete, m.Name)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := e.Policies[m.Name]; !ok {
+		return kms.ErrPolicyNotFound
+	}
+	delete(e.Policies, m.Name)
+	s.commit++
+	return nil
+}
+
+func (s *Server) getPolicy(req *request, m *pb.PolicyRequest) (*pb.PolicyResponse, error) {
+	e, err := s.enclave(req, cmds.PolicyGet, m.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	p, ok := e.Policies[m.Name]
+	if !ok {
+		return nil, kms.ErrPolicyNotFound
+	}
+
+	resp := &kms.PolicyResponse{
+		Name:      m.Name,
+		Allow:     p.Allow,
+		Deny:      p.Deny,
+		CreatedAt: p.CreatedAt,
+		CreatedBy: p.CreatedBy,
+	}
+	var v pb.PolicyResponse
+	if err = resp.MarshalPB(&v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (s *Server) policyStatus(req *request, m *pb.PolicyRequest) (*pb.PolicyStatusResponse, error) {
+	e, err := s.enclave(req, cmds.PolicyStatus, m.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	p, ok := e.Policies[m.Name]
+	if !ok {
+		return nil, kms.ErrPolicyNotFound
+	}
+	return &pb.PolicyStatusResponse{
+		Name:      m.Name,
+		CreatedAt: pb.Time(p.CreatedAt),
+		CreatedBy: p.CreatedBy.String(),
+	}, nil
+}
+
+func (s *Server) listPolicies(req *request, m *pb.ListRequest) (*pb.ListPoliciesResponse, error) {
+	e, err := s.enclave(req, cmds.PolicyList, m.Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	names, continueAt := list(keys(e.Policies), m)
+	resp := &pb.ListPoliciesResponse{
+		Policies:   make([]*pb.PolicyStatusResponse, 0, len(names)),
+		ContinueAt: continueAt,
+	}
+	for _, name := range names {
+		p := e.Policies[name]
+		resp.Policies = append(resp.Policies, &pb.PolicyStatusResponse{
+			Name:      name,
+			CreatedAt: pb.Time(p.CreatedAt),
+			CreatedBy: p.CreatedBy.String(),
+		})
+	}
+	return resp, nil
+}
+
+func (s *Server) createIdentity(req *request, m *pb.CreateIdentityRequest) error {
+	e, err := s.enclave(req, cmds.IdentityCreate, m.Identity)
+	if err != nil {
+		return err
+	}
+
+	var r kms.CreateIdentityRequest
+	if err = r.UnmarshalPB(m); err != nil {
+		return kms.Error{Code: http.StatusBadRequest, Err: err.Error()}
+	}
+	if r.Identity.IsZero() {
+		return errNoIdentity
+	}
+	if r.Privilege == 0 {
+		r.Privilege = kms.User
+	}
+
+	if r.IsServiceAccount {
+		if parent, ok := e.Identities[req.Identity]; ok && !slices.Contains(parent.ServiceAccounts, r.Identity) {
+			parent.ServiceAccounts = append(parent.ServiceAccounts, r.Identity)
+		}
+	}
+	e.Identities[r.Identity] = &identity{
+		Privilege:        r.Privilege,
+		CreatedAt:        time.Now(),
+		CreatedBy:        req.Identity,
+		IsServiceAccount: r.IsServiceAccount,
+		Tags:             r.Tags,
+	}
+	s.commit++
+	return nil
+}
+
+func (s *Server) deleteIdentity(req *request, m *pb.DeleteIdentityRequest) error {
+	e, err := s.enclave(req, cmds.IdentityDelete, m.Identity)
+	if err != nil {
+		return err
+	}
+
+	var r kms.DeleteIdentityRequest
+	if err = r.UnmarshalPB(m); err != nil {
+		return kms.Error{Code: http.StatusBadRequest, Err: err.Error()}
+	}
+	id, ok := e.Identities[r.Identity]
+	if !ok {
+		return kms.ErrIdentityNotFound
+	}
+	for _, a := range id.ServiceAccounts {
+		delete(e.Identities, a)
+	}
+	delete(e.Identities, r.Identity)
+	s.commit++
+	return nil
+}
+
+func (s *Server) getIdentity(req *request, m *pb.IdentityRequest) (*pb.IdentityResponse, error) {
+	e, err := s.enclave(req, cmds.IdentityGet, m.Identity)
+	if err != nil {
+		return nil, err
+	}
+
+	var r kms.IdentityRequest
+	if err = r.UnmarshalPB(m); err != nil {
+		return nil, kms.Error{Code: http.StatusBadRequest, Err: err.Error()}
+	}
+	id, ok := e.Identities[r.Identity]
+	if !ok {
+		return nil, kms.ErrIdentityNotFound
+	}
+	return identityResponse(r.Identity, id)
+}
+
+func (s *Server) listIdentities(req *request, m *pb.ListRequest) (*pb.ListIdentitiesResponse, error) {
+	e, err := s.enclave(req, cmds.IdentityList, m.Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]mtls.Identity, len(e.Identities))
+	for id := range e.Identities {
+		ids[id.String()] = id
+	}
+
+	names, continueAt := list(keys(ids), m)
+	resp := &pb.ListIdentitiesResponse{
+		Identities: make([]*pb.IdentityResponse, 0, len(names)),
+		ContinueAt: continueAt,
+	}
+	for _, name := range names {
+		v, err := identityResponse(ids[name], e.Identities[ids[name]])
+		if err != nil {
+			return nil, err
+		}
+		resp.Identities = append(resp.Identities, v)
+	}
+	return resp, nil
+}
+
+func keyStatusResponse(name string, version int, key *secretKey) *pb.KeyStatusResponse {
+	return &pb.KeyStatusResponse{
+		Name:      name,
+		Version:   uint32(version),
+		Type:      key.Type.String(),
+		CreatedAt: pb.Time(key.CreatedAt),
+		CreatedBy: key.CreatedBy.String(),
+	}
+}
+
+func identityResponse(identity mtls.Identity, id *identity) (*pb.IdentityResponse, error) {
+	resp := &kms.IdentityResponse{
+		Identity:         identity,
+		Privilege:        id.Privilege,
+		Policy:           id.Policy,
+		CreatedAt:        id.CreatedAt,
+		CreatedBy:        id.CreatedBy,
+		IsServiceAccount: id.IsServiceAccount,
+		ServiceAccounts:  id.ServiceAccounts,
+		Tags:             id.Tags,
+	}
+	var v pb.IdentityResponse
+	if err := resp.MarshalPB(&v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// keys returns the keys of m.
+func keys[T any](m map[string]T) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	return names
+}
+
+// list returns the page of names described by the ListRequest
+// in lexicographical order and the name at which the next page
+// starts, if any.
+func list(names []string, req *pb.ListRequest) ([]string, string) {
+	slices.Sort(names)
+
+	page := make([]string, 0, len(names))
+	for _, name := range names {
+		if strings.HasPrefix(name, req.Prefix) && name >= req.ContinueAt {
+			page = append(page, name)
+		}
+	}
+
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = listLimit
+	}
+	if len(page) > limit {
+		return page[:limit], page[limit]
+	}
+	return page, ""
+}
@@ -0,0 +1,403 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+// Package kmstest provides an in-memory KMS server for testing
+// applications that use a kms.Client.
+//
+// A Server speaks the same binary command protocol as a KMS
+// cluster. Hence, a kms.Client can talk to it without any
+// modifications:
+//
+//	srv := kmstest.NewServer()
+//	defer srv.Close()
+//
+//	client := srv.Client()
+//	err := client.CreateKey(ctx, kmstest.DefaultEnclave, &kms.CreateKeyRequest{
+//		Name: "my-key",
+//	})
+package kmstest
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"aead.dev/mtls"
+	"github.com/minio/kms-go/kms"
+	"github.com/minio/kms-go/kms/cmds"
+	"github.com/minio/kms-go/kms/internal/api"
+	"github.com/minio/kms-go/kms/internal/headers"
+	pb "github.com/minio/kms-go/kms/protobuf"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultEnclave is the name of the enclave that
+// exists on every new Server.
+const DefaultEnclave = "default"
+
+// Version is the version reported by a Server.
+const Version = "kmstest"
+
+// API limits reported by a Server.
+const (
+	apiTimeout = 15 * time.Second
+	maxBody    = 1 << 20
+)
+
+// Server is an in-memory KMS server listening on a system-chosen
+// port on the local loopback interface. It's intended for use in
+// end-to-end tests.
+//
+// A Server keeps all enclaves, keys, policies and identities in
+// memory. It behaves like a single-node KMS cluster and returns
+// the same errors, like kms.ErrKeyNotFound, with the same HTTP
+// status codes as a real KMS server.
+type Server struct {
+	// URL is the base URL of the form https://ipaddr:port
+	// with no trailing slash.
+	URL string
+
+	// APIKey is the API key of the server's root identity.
+	// The root identity has SysAdmin privileges.
+	APIKey mtls.PrivateKey
+
+	srv     *httptest.Server
+	started time.Time
+
+	mu       sync.Mutex
+	root     mtls.Identity
+	commit   uint64
+	nodes    map[int]string
+	hsms     []string
+	enclaves map[string]*enclave
+}
+
+// NewServer starts and returns a new Server. The server
+// contains the DefaultEnclave. The caller should call
+// Close when finished, to shut it down.
+//
+// NewServer panics if it fails to generate the root API
+// key or to start listening.
+func NewServer() *Server {
+	key, err := mtls.GenerateKeyEdDSA(rand.Reader)
+	if err != nil {
+		panic("kmstest: failed to generate API key: " + err.Error())
+	}
+
+	s := &Server{
+		APIKey:   key,
+		root:     key.Identity(),
+		started:  time.Now(),
+		enclaves: map[string]*enclave{},
+	}
+	s.enclaves[DefaultEnclave] = newEnclave(s.started, s.root)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(api.PathVersion, s.handleVersion)
+	mux.HandleFunc(api.PathHealthLive, s.handleHealth)
+	mux.HandleFunc(api.PathHealthReady, s.handleHealth)
+	mux.HandleFunc(api.PathHealthStatus, s.handleStatus)
+	mux.HandleFunc(api.PathHealthAPIs, s.handleAPIs)
+	mux.HandleFunc(api.PathKMS, s.handleKMS)
+
+	s.srv = httptest.NewUnstartedServer(mux)
+	s.srv.TLS = &tls.Config{
+		ClientAuth: tls.RequestClientCert,
+	}
+	s.srv.StartTLS()
+
+	s.URL = s.srv.URL
+	s.nodes = map[int]string{0: s.Host()}
+	return s
+}
+
+// Host returns the server's address of the form ipaddr:port.
+func (s *Server) Host() string { return strings.TrimPrefix(s.URL, "https://") }
+
+// Certificate returns the certificate used by the server.
+func (s *Server) Certificate() *x509.Certificate { return s.srv.Certificate() }
+
+// Client returns a new kms.Client that is configured to talk
+// to the server using the root API key. The client trusts
+// the server's TLS certificate.
+func (s *Server) Client() *kms.Client { return s.ClientWithAPIKey(s.APIKey) }
+
+// ClientWithAPIKey returns a new kms.Client that is configured
+// to talk to the server using the given API key. The client
+// trusts the server's TLS certificate.
+func (s *Server) ClientWithAPIKey(key mtls.PrivateKey) *kms.Client {
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(s.Certificate())
+
+	client, err := kms.NewClient(&kms.Config{
+		Endpoints: []string{s.Host()},
+		APIKey:    key,
+		TLS: &tls.Config{
+			RootCAs: rootCAs,
+		},
+	})
+	if err != nil {
+		panic("kmstest: failed to create client: " + err.Error())
+	}
+	return client
+}
+
+// Close shuts down the server and blocks until all outstanding
+// requests on this server have completed.
+func (s *Server) Close() { s.srv.Close() }
+
+func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, errMethodNotAllowed)
+		return
+	}
+	writeProto(w, &pb.VersionResponse{
+		Version:    Version,
+		Commit:     Version,
+		APIVersion: "v1",
+		Host:       s.Host(),
+	})
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, errMethodNotAllowed)
+		return
+	}
+	w.Header().Set(headers.ContentLength, "0")
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, errMethodNotAllowed)
+		return
+	}
+
+	identity, err := mtls.PeerIdentity(r.TLS)
+	if err != nil {
+		writeError(w, kms.ErrPermission)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err = s.authorize(&request{Identity: identity}, cmds.ClusterStatus, ""); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeProto(w, s.serverStatus())
+}
+
+func (s *Server) handleAPIs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, errMethodNotAllowed)
+		return
+	}
+
+	writeProto(w, &pb.ListAPIsResponse{
+		APIs: []*pb.APIResponse{
+			{Method: http.MethodGet, Path: api.PathVersion, Timeout: pb.Duration(apiTimeout)},
+			{Method: http.MethodGet, Path: api.PathHealthLive, Timeout: pb.Duration(apiTimeout)},
+			{Method: http.MethodGet, Path: api.PathHealthReady, Timeout: pb.Duration(apiTimeout)},
+			{Method: http.MethodGet, Path: api.PathHealthStatus, Timeout: pb.Duration(apiTimeout)},
+			{Method: http.MethodGet, Path: api.PathHealthAPIs, Timeout: pb.Duration(apiTimeout)},
+			{Method: http.MethodPost, Path: api.PathKMS, MaxBody: maxBody, Timeout: pb.Duration(apiTimeout)},
+		},
+	})
+}
+
+func (s *Server) handleKMS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, errMethodNotAllowed)
+		return
+	}
+
+	identity, err := mtls.PeerIdentity(r.TLS)
+	if err != nil {
+		writeError(w, kms.ErrPermission)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+	if err != nil {
+		if _, ok := err.(*http.MaxBytesError); ok {
+			err = errTooLarge
+		}
+		writeError(w, err)
+		return
+	}
+
+	enclave := strings.Trim(strings.TrimPrefix(r.URL.Path, api.PathKMS), "/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req := &request{
+		Identity:  identity,
+		Enclave:   enclave,
+		Partial:   r.URL.Query().Has(api.QueryPartial),
+		Source:    sourceIP(r),
+		BatchSize: numCommands(body),
+	}
+	var resp []byte
+	for len(body) > 0 {
+		if len(body) < 6 {
+			writeError(w, errInvalidCommand)
+			return
+		}
+		cmd := cmds.Command(binary.BigEndian.Uint16(body))
+
+		next, rest, err := s.exec(req, cmd, resp, body)
+		if err != nil {
+			if !req.Partial || rest == nil {
+				writeError(w, err)
+				return
+			}
+			next = appendError(next, err)
+		}
+		resp, body = next, rest
+	}
+	writeBinary(w, resp)
+}
+
+// request describes the context of a KMS request.
+type request struct {
+	Identity  mtls.Identity // The identity that sent the request
+	Enclave   string        // The enclave the request refers to
+	Partial   bool          // Whether the client accepts partial results
+	Source    netip.Addr    // The IP address the request has been sent from
+	BatchSize int           // The number of commands within the request
+
+	// AssociatedData is the associated data of the
+	// command currently executed, if any.
+	AssociatedData []byte
+}
+
+// withAssociatedData returns a copy of the request
+// with the given associated data.
+func (r *request) withAssociatedData(data []byte) *request {
+	req := *r
+	req.AssociatedData = data
+	return &req
+}
+
+// sourceIP returns the IP address of the client that
+// sent r, or an invalid address if it is unknown.
+func sourceIP(r *http.Request) netip.Addr {
+	addr, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr.Addr().Unmap()
+}
+
+// numCommands returns the number of commands within the
+// request body b. It ignores any incomplete trailing command.
+func numCommands(b []byte) int {
+	var n int
+	for len(b) >= 6 {
+		size := int(binary.BigEndian.Uint32(b[2:]))
+		if len(b)-6 < size {
+			break
+		}
+		b, n = b[6+size:], n+1
+	}
+	return n
+}
+
+// serverStatus returns the status of the server. It
+// must only be called when s.mu is locked.
+func (s *Server) serverStatus() *pb.ServerStatusResponse {
+	nodes := make(map[uint32]string, len(s.nodes))
+	for id, node := range s.nodes {
+		nodes[uint32(id)] = node
+	}
+	return &pb.ServerStatusResponse{
+		Version:           Version,
+		APIVersion:        "v1",
+		Host:              s.Host(),
+		UpTime:            pb.Duration(time.Since(s.started)),
+		Role:              "Leader",
+		Commit:            s.commit,
+		Nodes:             nodes,
+		ID:                0,
+		LeaderID:          0,
+		LastHeartbeat:     pb.Duration(0),
+		HeartbeatInterval: pb.Duration(500 * time.Millisecond),
+		ElectionTimeout:   pb.Duration(1500 * time.Millisecond),
+		OS:                runtime.GOOS,
+		Arch:              runtime.GOARCH,
+		CPUs:              uint32(runtime.NumCPU()),
+		UsableCPUs:        uint32(runtime.GOMAXPROCS(0)),
+		HSMs:              s.hsms,
+		ConfiguredHSMs:    s.hsms,
+	}
+}
+
+var (
+	errMethodNotAllowed = kms.Error{Code: http.StatusMethodNotAllowed, Err: "method not allowed"}
+	errInvalidCommand   = kms.Error{Code: http.StatusBadRequest, Err: "invalid command format"}
+	errTooLarge         = kms.Error{Code: http.StatusRequestEntityTooLarge, Err: "request body too large"}
+)
+
+// writeProto writes msg as binary protobuf response.
+func writeProto(w http.ResponseWriter, msg proto.Message) {
+	body, err := proto.Marshal(msg)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeBinary(w, body)
+}
+
+// writeBinary writes body as binary response with status 200 OK.
+func writeBinary(w http.ResponseWriter, body []byte) {
+	w.Header().Set(headers.ContentType, headers.ContentTypeBinary)
+	w.Header().Set(headers.ContentLength, strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// appendError appends err as cmds.Error to a multi-command
+// response.
+func appendError(resp []byte, err error) []byte {
+	var e kms.Error
+	if !errors.As(err, &e) {
+		e = kms.Error{Code: http.StatusInternalServerError, Err: err.Error()}
+	}
+	resp, _ = cmds.EncodePB(resp, cmds.Error, &pb.ErrResponse{
+		Message: e.Err,
+		Code:    uint32(e.Code),
+	})
+	return resp
+}
+
+// writeError writes err as binary pb.ErrResponse. If err is
+// a kms.Error, the response status code is the error code.
+// Otherwise, it is 500 Internal Server Error.
+func writeError(w http.ResponseWriter, err error) {
+	var e kms.Error
+	if !errors.As(err, &e) {
+		e = kms.Error{Code: http.StatusInternalServerError, Err: err.Error()}
+	}
+
+	body, _ := proto.Marshal(&pb.ErrResponse{Message: e.Err})
+	w.Header().Set(headers.ContentType, headers.ContentTypeBinary)
+	w.Header().Set(headers.ContentLength, strconv.Itoa(len(body)))
+	w.WriteHeader(e.Code)
+	w.Write(body)
+}
@@ -0,0 +1,387 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package kmstest_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/netip"
+	"os"
+	"strconv"
+	"testing"
+
+	"aead.dev/mtls"
+	"github.com/minio/kms-go/kms"
+	"github.com/minio/kms-go/kms/cmds"
+	"github.com/minio/kms-go/kms/kmstest"
+)
+
+func TestServer_Health(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := srv.Client()
+	if err := client.Live(ctx, &kms.LivenessRequest{}); err != nil {
+		t.Fatalf("Server is not alive: %v", err)
+	}
+	if err := client.Ready(ctx, &kms.ReadinessRequest{Write: true}); err != nil {
+		t.Fatalf("Server is not ready: %v", err)
+	}
+
+	versions, err := client.Version(ctx, &kms.VersionRequest{})
+	if err != nil {
+		t.Fatalf("Failed to fetch server version: %v", err)
+	}
+	if len(versions) != 1 || versions[0].Version != kmstest.Version {
+		t.Fatalf("Version mismatch: got '%v' - want '%s'", versions, kmstest.Version)
+	}
+}
+
+func TestServer_Enclave(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := srv.Client()
+	if err := client.CreateEnclave(ctx, &kms.CreateEnclaveRequest{Name: "my-enclave"}); err != nil {
+		t.Fatalf("Failed to create enclave: %v", err)
+	}
+	if err := client.CreateEnclave(ctx, &kms.CreateEnclaveRequest{Name: "my-enclave"}); !errors.Is(err, kms.ErrEnclaveExists) {
+		t.Fatalf("Creating an existing enclave should have failed: got '%v' - want '%v'", err, kms.ErrEnclaveExists)
+	}
+
+	page, err := client.ListEnclaves(ctx, &kms.ListRequest{})
+	if err != nil {
+		t.Fatalf("Failed to list enclaves: %v", err)
+	}
+	if n := len(page.Items); n != 2 {
+		t.Fatalf("Invalid enclave listing: got %d enclaves - want %d", n, 2)
+	}
+
+	if err = client.DeleteEnclave(ctx, &kms.DeleteEnclaveRequest{Name: "my-enclave"}); err != nil {
+		t.Fatalf("Failed to delete enclave: %v", err)
+	}
+	if _, err = client.EnclaveStatus(ctx, &kms.EnclaveStatusRequest{Name: "my-enclave"}); !errors.Is(err, kms.ErrEnclaveNotFound) {
+		t.Fatalf("Fetching a deleted enclave should have failed: got '%v' - want '%v'", err, kms.ErrEnclaveNotFound)
+	}
+}
+
+func TestServer_Key(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	const (
+		Enclave = kmstest.DefaultEnclave
+		KeyName = "my-key"
+	)
+	var (
+		ctx       = context.Background()
+		client    = srv.Client()
+		plaintext = []byte("Hello World")
+	)
+	for _, keyType := range []kms.SecretKeyType{kms.AES256, kms.ChaCha20} {
+		if err := client.CreateKey(ctx, Enclave, &kms.CreateKeyRequest{Name: KeyName, Type: keyType}); err != nil {
+			t.Fatalf("Failed to create key: %v", err)
+		}
+		if err := client.CreateKey(ctx, Enclave, &kms.CreateKeyRequest{Name: KeyName}); !errors.Is(err, kms.ErrKeyExists) {
+			t.Fatalf("Creating an existing key should have failed: got '%v' - want '%v'", err, kms.ErrKeyExists)
+		}
+		if err := client.CreateKey(ctx, Enclave, &kms.CreateKeyRequest{Name: KeyName, Type: keyType, AddVersion: true}); err != nil {
+			t.Fatalf("Failed to add key version: %v", err)
+		}
+
+		stat, err := client.KeyStatus(ctx, Enclave, &kms.KeyStatusRequest{Name: KeyName})
+		if err != nil {
+			t.Fatalf("Failed to fetch key status: %v", err)
+		}
+		if stat[0].Version != 2 || stat[0].Type != keyType {
+			t.Fatalf("Key status mismatch: got version %d and type %v - want version %d and type %v", stat[0].Version, stat[0].Type, 2, keyType)
+		}
+
+		ciphertexts, err := client.Encrypt(ctx, Enclave, &kms.EncryptRequest{
+			Name:      KeyName,
+			Plaintext: plaintext,
+		}, &kms.EncryptRequest{
+			Name:      KeyName,
+			Version:   1,
+			Plaintext: plaintext,
+		})
+		if err != nil {
+			t.Fatalf("Failed to encrypt: %v", err)
+		}
+		if n := len(ciphertexts); n != 2 {
+			t.Fatalf("Invalid number of encrypt responses: got %d - want %d", n, 2)
+		}
+
+		for _, c := range ciphertexts {
+			resp, err := client.Decrypt(ctx, Enclave, &kms.DecryptRequest{
+				Name:       KeyName,
+				Version:    c.Version,
+				Ciphertext: c.Ciphertext,
+			})
+			if err != nil {
+				t.Fatalf("Failed to decrypt: %v", err)
+			}
+			if !bytes.Equal(resp[0].Plaintext, plaintext) {
+				t.Fatalf("Plaintext mismatch: got '%s' - want '%s'", resp[0].Plaintext, plaintext)
+			}
+
+			_, err = client.Decrypt(ctx, Enclave, &kms.DecryptRequest{
+				Name:           KeyName,
+				Version:        c.Version,
+				Ciphertext:     c.Ciphertext,
+				AssociatedData: []byte("invalid"),
+			})
+			if !errors.Is(err, kms.ErrDecrypt) {
+				t.Fatalf("Decrypting with invalid associated data should have failed: got '%v' - want '%v'", err, kms.ErrDecrypt)
+			}
+		}
+
+		if err = client.DeleteKey(ctx, Enclave, &kms.DeleteKeyRequest{Name: KeyName, AllVersions: true}); err != nil {
+			t.Fatalf("Failed to delete key: %v", err)
+		}
+		_, err = client.GenerateKey(ctx, Enclave, &kms.GenerateKeyRequest{Name: KeyName})
+		if !errors.Is(err, kms.ErrKeyNotFound) {
+			t.Fatalf("Using a deleted key should have failed: got '%v' - want '%v'", err, kms.ErrKeyNotFound)
+		}
+		if e := (kms.Error{}); !errors.As(err, &e) || e.Code != http.StatusNotFound {
+			t.Fatalf("Invalid error status code: got '%v' - want %d", err, http.StatusNotFound)
+		}
+	}
+}
+
+func TestServer_Policy(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	const Enclave = kmstest.DefaultEnclave
+	ctx := context.Background()
+	client := srv.Client()
+
+	if err := client.CreateKey(ctx, Enclave, &kms.CreateKeyRequest{Name: "my-key"}); err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	if err := client.CreateKey(ctx, Enclave, &kms.CreateKeyRequest{Name: "other-key"}); err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	err := client.CreatePolicy(ctx, Enclave, &kms.CreatePolicyRequest{
+		Name: "my-policy",
+		Allow: map[cmds.Command]kms.RuleSet{
+			cmds.KeyGenerate: {"my-*": {}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create policy: %v", err)
+	}
+
+	key, err := mtls.GenerateKeyEdDSA(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate API key: %v", err)
+	}
+	if err = client.CreateIdentity(ctx, Enclave, &kms.CreateIdentityRequest{Identity: key.Identity()}); err != nil {
+		t.Fatalf("Failed to create identity: %v", err)
+	}
+	if err = client.AssignPolicy(ctx, Enclave, &kms.AssignPolicyRequest{Policy: "my-policy", Identity: key.Identity()}); err != nil {
+		t.Fatalf("Failed to assign policy: %v", err)
+	}
+
+	user := srv.ClientWithAPIKey(key)
+	if _, err = user.GenerateKey(ctx, Enclave, &kms.GenerateKeyRequest{Name: "my-key"}); err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	if _, err = user.GenerateKey(ctx, Enclave, &kms.GenerateKeyRequest{Name: "other-key"}); !errors.Is(err, kms.ErrPermission) {
+		t.Fatalf("Generating a key should have failed: got '%v' - want '%v'", err, kms.ErrPermission)
+	}
+	if err = user.CreateKey(ctx, Enclave, &kms.CreateKeyRequest{Name: "my-key-2"}); !errors.Is(err, kms.ErrPermission) {
+		t.Fatalf("Creating a key should have failed: got '%v' - want '%v'", err, kms.ErrPermission)
+	}
+}
+
+func TestServer_PolicyTests(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	const Enclave = kmstest.DefaultEnclave
+	var (
+		ctx     = context.Background()
+		client  = srv.Client()
+		execute = map[cmds.Command]func(*kms.Client, string) error{
+			cmds.KeyStatus: func(c *kms.Client, name string) error {
+				_, err := c.KeyStatus(ctx, Enclave, &kms.KeyStatusRequest{Name: name})
+				return err
+			},
+			cmds.KeyGenerate: func(c *kms.Client, name string) error {
+				_, err := c.GenerateKey(ctx, Enclave, &kms.GenerateKeyRequest{Name: name})
+				return err
+			},
+			cmds.KeyDelete: func(c *kms.Client, name string) error {
+				return c.DeleteKey(ctx, Enclave, &kms.DeleteKeyRequest{Name: name})
+			},
+			cmds.PolicyGet: func(c *kms.Client, name string) error {
+				_, err := c.GetPolicy(ctx, Enclave, &kms.PolicyRequest{Name: name})
+				return err
+			},
+		}
+	)
+
+	for i, test := range readPolicyTests(t, "../testdata/policies.json") {
+		name := "policy-" + strconv.Itoa(i)
+		err := client.CreatePolicy(ctx, Enclave, &kms.CreatePolicyRequest{
+			Name:  name,
+			Allow: test.Policy.Allow,
+			Deny:  test.Policy.Deny,
+		})
+		if err != nil {
+			t.Fatalf("Test %d: failed to create policy: %v", i, err)
+		}
+
+		key, err := mtls.GenerateKeyEdDSA(rand.Reader)
+		if err != nil {
+			t.Fatalf("Test %d: failed to generate API key: %v", i, err)
+		}
+		if err = client.CreateIdentity(ctx, Enclave, &kms.CreateIdentityRequest{Identity: key.Identity()}); err != nil {
+			t.Fatalf("Test %d: failed to create identity: %v", i, err)
+		}
+		if err = client.AssignPolicy(ctx, Enclave, &kms.AssignPolicyRequest{Policy: name, Identity: key.Identity()}); err != nil {
+			t.Fatalf("Test %d: failed to assign policy: %v", i, err)
+		}
+
+		user := srv.ClientWithAPIKey(key)
+		for _, c := range test.Tests {
+			exec, ok := execute[c.Command]
+			if !ok {
+				t.Fatalf("Test %d: command '%v' is not supported", i, c.Command)
+			}
+
+			err := exec(user, c.Resource)
+			if allowed := !errors.Is(err, kms.ErrPermission); allowed != c.Allowed {
+				t.Fatalf("Test %d: '%v' on '%s': got '%v' - want '%v': %v", i, c.Command, c.Resource, allowed, c.Allowed, err)
+			}
+		}
+	}
+}
+
+func TestServer_PolicyConditions(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	const Enclave = kmstest.DefaultEnclave
+	ctx := context.Background()
+	client := srv.Client()
+
+	if err := client.CreateKey(ctx, Enclave, &kms.CreateKeyRequest{Name: "my-key"}); err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	err := client.CreatePolicy(ctx, Enclave, &kms.CreatePolicyRequest{
+		Name: "my-policy",
+		Allow: map[cmds.Command]kms.RuleSet{
+			cmds.KeyEncrypt: {"my-key": {MaxBatchSize: 1}},
+			cmds.KeyDecrypt: {"my-key": {AssociatedDataPrefixes: [][]byte{[]byte("bucket=a")}}},
+			cmds.KeyStatus:  {"my-key": {SourceIPs: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}}},
+		},
+		Deny: map[cmds.Command]kms.RuleSet{
+			cmds.KeyDecrypt: {"my-key": {AssociatedData: [][]byte{[]byte("bucket=ab")}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create policy: %v", err)
+	}
+
+	key, err := mtls.GenerateKeyEdDSA(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate API key: %v", err)
+	}
+	if err = client.CreateIdentity(ctx, Enclave, &kms.CreateIdentityRequest{Identity: key.Identity()}); err != nil {
+		t.Fatalf("Failed to create identity: %v", err)
+	}
+	if err = client.AssignPolicy(ctx, Enclave, &kms.AssignPolicyRequest{Policy: "my-policy", Identity: key.Identity()}); err != nil {
+		t.Fatalf("Failed to assign policy: %v", err)
+	}
+
+	user := srv.ClientWithAPIKey(key)
+	encrypt := func(associatedData string) *kms.EncryptResponse {
+		resp, err := user.Encrypt(ctx, Enclave, &kms.EncryptRequest{
+			Name:           "my-key",
+			Plaintext:      []byte("Hello World"),
+			AssociatedData: []byte(associatedData),
+		})
+		if err != nil {
+			t.Fatalf("Failed to encrypt: %v", err)
+		}
+		return resp[0]
+	}
+	decrypt := func(c *kms.EncryptResponse, associatedData string) error {
+		_, err := user.Decrypt(ctx, Enclave, &kms.DecryptRequest{
+			Name:           "my-key",
+			Version:        c.Version,
+			Ciphertext:     c.Ciphertext,
+			AssociatedData: []byte(associatedData),
+		})
+		return err
+	}
+
+	if err = decrypt(encrypt("bucket=a1"), "bucket=a1"); err != nil {
+		t.Fatalf("Failed to decrypt: %v", err)
+	}
+	if err = decrypt(encrypt("bucket=b"), "bucket=b"); !errors.Is(err, kms.ErrPermission) {
+		t.Fatalf("Decrypting should have failed: got '%v' - want '%v'", err, kms.ErrPermission)
+	}
+	if err = decrypt(encrypt("bucket=ab"), "bucket=ab"); !errors.Is(err, kms.ErrPermission) {
+		t.Fatalf("Decrypting should have failed: got '%v' - want '%v'", err, kms.ErrPermission)
+	}
+
+	plaintext := []byte("Hello World")
+	_, err = user.Encrypt(ctx, Enclave, &kms.EncryptRequest{Name: "my-key", Plaintext: plaintext}, &kms.EncryptRequest{Name: "my-key", Plaintext: plaintext})
+	if !errors.Is(err, kms.ErrPermission) {
+		t.Fatalf("Encrypting a batch should have failed: got '%v' - want '%v'", err, kms.ErrPermission)
+	}
+	if _, err = user.KeyStatus(ctx, Enclave, &kms.KeyStatusRequest{Name: "my-key"}); !errors.Is(err, kms.ErrPermission) {
+		t.Fatalf("Fetching key status should have failed: got '%v' - want '%v'", err, kms.ErrPermission)
+	}
+}
+
+// policyTest is a policy test vector shared
+// with the kms package's policy tests.
+type policyTest struct {
+	Policy kms.Policy
+	Tests  []struct {
+		Command  cmds.Command
+		Resource string
+		Allowed  bool
+	}
+}
+
+func readPolicyTests(t *testing.T, filename string) []policyTest {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read policy tests: %v", err)
+	}
+
+	var tests []policyTest
+	if err = json.Unmarshal(b, &tests); err != nil {
+		t.Fatalf("Failed to parse policy tests: %v", err)
+	}
+	if len(tests) == 0 {
+		t.Fatalf("No policy tests found in '%s'", filename)
+	}
+	return tests
+}
@@ -0,0 +1,138 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package kms
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// leaderTTL is the amount of time after which the Client
+	// learns the cluster leader again, to detect elections
+	// that have not caused any errors.
+	leaderTTL = 30 * time.Second
+
+	// leaderRetryDelay is the min. amount of time between two
+	// attempts to learn the cluster leader while it's unknown.
+	leaderRetryDelay = 1 * time.Second
+
+	// leaderLearnTimeout limits how long learning the cluster
+	// leader may take.
+	leaderLearnTimeout = 10 * time.Second
+)
+
+// leaderRouter tracks the cluster leader such that write
+// requests can be sent to the leader directly instead of
+// being forwarded by another KMS server.
+type leaderRouter struct {
+	client *Client
+
+	mu       sync.Mutex
+	host     string        // The leader's host. Empty if unknown
+	updated  time.Time     // Last time the leader has been learned
+	learning chan struct{} // Closed once the leader has been learned. Nil if not learning
+}
+
+// Host returns the host of the current cluster leader or the
+// empty string if there is no leader or it is unknown.
+//
+// It learns the leader from the server status of all hosts
+// if the leader is unknown or has been learned too long ago.
+// Concurrent calls share the same attempt to learn the leader.
+// The attempt is not bound to ctx, such that a canceled ctx
+// neither cancels it for other callers nor causes the leader
+// to be considered unknown. If ctx is done before the leader
+// has been learned, Host returns the empty string.
+func (r *leaderRouter) Host(ctx context.Context) string {
+	r.mu.Lock()
+	now := time.Now()
+	if r.host != "" && now.Sub(r.updated) < leaderTTL {
+		defer r.mu.Unlock()
+		return r.host
+	}
+	if r.host == "" && now.Sub(r.updated) < leaderRetryDelay {
+		r.mu.Unlock()
+		return ""
+	}
+
+	learning := r.learning
+	if learning == nil {
+		learning = make(chan struct{})
+		r.learning = learning
+		go r.update(learning)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-learning:
+	case <-ctx.Done():
+		return ""
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.host
+}
+
+// Reset forgets the leader if it is host, for example, since a
+// request to host has failed. The leader is learned again when
+// sending the next write request.
+func (r *leaderRouter) Reset(host string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.host == host {
+		r.host, r.updated = "", time.Time{}
+	}
+}
+
+// update learns the leader and closes learning once done.
+func (r *leaderRouter) update(learning chan struct{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), leaderLearnTimeout)
+	defer cancel()
+
+	host := r.learn(ctx)
+
+	r.mu.Lock()
+	r.host, r.updated = host, time.Now()
+	r.learning = nil
+	r.mu.Unlock()
+
+	close(learning)
+}
+
+// learn returns the host of the current cluster leader, or the
+// empty string, if no host reports a leader that is one of the
+// client's hosts.
+func (r *leaderRouter) learn(ctx context.Context) string {
+	hosts := r.client.Hosts()
+	status, _ := r.client.ServerStatus(ctx, &ServerStatusRequest{Hosts: hosts})
+
+	var leader string
+	for _, s := range status {
+		if s.ID == s.LeaderID && slices.Contains(hosts, s.Host) {
+			return s.Host // Prefer the leader's own status
+		}
+		if node, ok := s.Nodes[s.LeaderID]; ok && s.LeaderID >= 0 && slices.Contains(hosts, node) {
+			leader = node
+		}
+	}
+	return leader
+}
+
+// isLeaderError reports whether err, returned by a request to
+// the cluster leader, indicates that the leader has changed or
+// is unavailable. Errors caused by the request itself, like
+// ErrKeyNotFound, don't.
+func isLeaderError(err error) bool {
+	if e := (Error{}); errors.As(err, &e) {
+		return e.Code >= 500
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,110 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package kms_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"strconv"
+	"testing"
+
+	"github.com/minio/kms-go/kms"
+	"github.com/minio/kms-go/kms/kmstest"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestClient_LeaderRouting(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	const (
+		Enclave     = kmstest.DefaultEnclave
+		Unreachable = "127.0.0.1:1"
+	)
+	var (
+		ctx     = context.Background()
+		metrics = kms.NewMetrics()
+		rootCAs = x509.NewCertPool()
+	)
+	rootCAs.AddCert(srv.Certificate())
+
+	// Without leader routing, the LB sends about half of all first
+	// requests to the unreachable host and retries them. With leader
+	// routing, writes are sent to the leader directly.
+	for i := 0; i < 16; i++ {
+		client, err := kms.NewClient(&kms.Config{
+			Endpoints:     []string{Unreachable, srv.Host()},
+			APIKey:        srv.APIKey,
+			TLS:           &tls.Config{RootCAs: rootCAs},
+			Metrics:       metrics,
+			LeaderRouting: true,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+
+		name := "my-key-" + strconv.Itoa(i)
+		if err = client.CreateKey(ctx, Enclave, &kms.CreateKeyRequest{Name: name}); err != nil {
+			t.Fatalf("Failed to create key: %v", err)
+		}
+		if err = client.CreateKey(ctx, Enclave, &kms.CreateKeyRequest{Name: name + "-2"}); err != nil {
+			t.Fatalf("Failed to create key: %v", err)
+		}
+	}
+	if n := testutil.CollectAndCount(metrics, "kms_client_retries_total"); n != 0 {
+		t.Fatalf("Write requests have been retried: got %d - want %d", n, 0)
+	}
+	if n := testutil.CollectAndCount(metrics, "kms_client_requests_total"); n != 1 {
+		t.Fatalf("Write requests have been sent to multiple hosts: got %d - want %d", n, 1)
+	}
+}
+
+func TestClient_LeaderRouting_Canceled(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	const (
+		Enclave     = kmstest.DefaultEnclave
+		Unreachable = "127.0.0.1:1"
+	)
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(srv.Certificate())
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// A write request with a canceled context must not cause
+	// the leader to be considered unknown for other requests.
+	// Hence, no other request must be retried.
+	metrics := kms.NewMetrics()
+	for i := 0; i < 16; i++ {
+		client, err := kms.NewClient(&kms.Config{
+			Endpoints:     []string{Unreachable, srv.Host()},
+			APIKey:        srv.APIKey,
+			TLS:           &tls.Config{RootCAs: rootCAs},
+			Metrics:       metrics,
+			LeaderRouting: true,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+
+		name := "my-key-" + strconv.Itoa(i)
+		if err = client.CreateKey(canceled, Enclave, &kms.CreateKeyRequest{Name: name}); err == nil {
+			t.Fatal("Creating a key with a canceled context should have failed")
+		}
+		if err = client.CreateKey(context.Background(), Enclave, &kms.CreateKeyRequest{Name: name}); err != nil {
+			t.Fatalf("Failed to create key: %v", err)
+		}
+	}
+	if n := testutil.CollectAndCount(metrics, "kms_client_retries_total"); n != 0 {
+		t.Fatalf("Write requests have been retried: got %d - want %d", n, 0)
+	}
+}
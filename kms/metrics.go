@@ -0,0 +1,191 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package kms
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/minio/kms-go/kms/internal/https"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics is a prometheus.Collector that exports metrics about
+// the requests sent by one or multiple Clients. A Client records
+// metrics if its Config contains a Metrics.
+//
+// Metrics exports:
+//   - kms_client_requests_total: number of requests sent, labeled by command, enclave and host.
+//   - kms_client_request_duration_seconds: request latency, including retries, labeled by command, enclave and host.
+//   - kms_client_errors_total: number of failed requests, labeled by command and error code.
+//   - kms_client_retries_total: number of requests retried by the load balancer, labeled by host.
+//   - kms_client_host_exclusions_total: number of times a host got excluded by the load balancer.
+//   - kms_client_excluded_hosts: hosts currently excluded by the load balancer or health checks.
+//   - kms_client_batch_size: number of commands per request, labeled by command.
+//
+// Requests are labeled with the first command of their request body.
+// Errors that don't originate from a KMS server, for example network
+// errors, are counted with error code 0.
+type Metrics struct {
+	requests   *prometheus.CounterVec
+	latency    *prometheus.HistogramVec
+	errors     *prometheus.CounterVec
+	retries    *prometheus.CounterVec
+	exclusions *prometheus.CounterVec
+	batchSize  *prometheus.HistogramVec
+	excluded   *prometheus.Desc
+
+	mu  sync.Mutex
+	lbs []*https.LoadBalancer
+}
+
+var _ prometheus.Collector = (*Metrics)(nil) // compiler check
+
+// NewMetrics returns a new Metrics.
+func NewMetrics() *Metrics {
+	const Namespace, Subsystem = "kms", "client"
+	return &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "requests_total",
+			Help:      "Number of requests sent to KMS servers.",
+		}, []string{"command", "enclave", "host"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "request_duration_seconds",
+			Help:      "Latency of requests sent to KMS servers, including retries.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"command", "enclave", "host"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "errors_total",
+			Help:      "Number of failed requests by error code.",
+		}, []string{"command", "code"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "retries_total",
+			Help:      "Number of requests retried with another host.",
+		}, []string{"host"}),
+		exclusions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "host_exclusions_total",
+			Help:      "Number of times a host got excluded since a request to it failed.",
+		}, []string{"host"}),
+		batchSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "batch_size",
+			Help:      "Number of commands per request.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 10),
+		}, []string{"command"}),
+		excluded: prometheus.NewDesc(
+			prometheus.BuildFQName(Namespace, Subsystem, "excluded_hosts"),
+			"Hosts currently excluded since a request to them failed.",
+			[]string{"host"}, nil,
+		),
+	}
+}
+
+// Describe sends the descriptors of all metrics to ch.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.requests.Describe(ch)
+	m.latency.Describe(ch)
+	m.errors.Describe(ch)
+	m.retries.Describe(ch)
+	m.exclusions.Describe(ch)
+	m.batchSize.Describe(ch)
+	ch <- m.excluded
+}
+
+// Collect sends all metrics to ch.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.requests.Collect(ch)
+	m.latency.Collect(ch)
+	m.errors.Collect(ch)
+	m.retries.Collect(ch)
+	m.exclusions.Collect(ch)
+	m.batchSize.Collect(ch)
+
+	m.mu.Lock()
+	lbs := m.lbs
+	m.mu.Unlock()
+
+	excluded := map[string]struct{}{}
+	for _, lb := range lbs {
+		for _, host := range lb.Excluded() {
+			excluded[host] = struct{}{}
+		}
+	}
+	for host := range excluded {
+		ch <- prometheus.MustNewConstMetric(m.excluded, prometheus.GaugeValue, 1, host)
+	}
+}
+
+// register records the retries and exclusions of the load
+// balancer and exports its excluded hosts until unregister
+// is called. Existing OnRetry and OnExclude hooks of the load
+// balancer are still called.
+func (m *Metrics) register(lb *https.LoadBalancer) {
+	onRetry, onExclude := lb.OnRetry, lb.OnExclude
+	lb.OnRetry = func(host string) {
+		m.retries.WithLabelValues(host).Inc()
+		if onRetry != nil {
+			onRetry(host)
+		}
+	}
+	lb.OnExclude = func(host string) {
+		m.exclusions.WithLabelValues(host).Inc()
+		if onExclude != nil {
+			onExclude(host)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lbs = append(m.lbs, lb)
+}
+
+// unregister stops exporting the excluded hosts of the
+// load balancer. It is a no-op if lb is not registered.
+func (m *Metrics) unregister(lb *https.LoadBalancer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lbs = slices.DeleteFunc(slices.Clone(m.lbs), func(l *https.LoadBalancer) bool { return l == lb })
+}
+
+// interceptor returns an Interceptor that records the
+// requests, latencies, errors and batch sizes of all calls.
+func (m *Metrics) interceptor() Interceptor {
+	return func(ctx context.Context, call *Call, next Invoker) (*http.Response, error) {
+		var command string
+		if len(call.Commands) > 0 {
+			command = call.Commands[0].String()
+		}
+		m.batchSize.WithLabelValues(command).Observe(float64(len(call.Commands)))
+
+		resp, err := next(ctx, call)
+		if call.Host != "" {
+			m.requests.WithLabelValues(command, call.Request.Enclave, call.Host).Inc()
+			m.latency.WithLabelValues(command, call.Request.Enclave, call.Host).Observe(call.Latency.Seconds())
+		}
+		if err != nil {
+			var code int
+			if e := (Error{}); errors.As(err, &e) {
+				code = e.Code
+			}
+			m.errors.WithLabelValues(command, strconv.Itoa(code)).Inc()
+		}
+		return resp, err
+	}
+}
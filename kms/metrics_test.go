@@ -0,0 +1,124 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package kms_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/minio/kms-go/kms"
+	"github.com/minio/kms-go/kms/kmstest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	const (
+		Enclave     = kmstest.DefaultEnclave
+		Unreachable = "127.0.0.1:1"
+	)
+	var (
+		ctx     = context.Background()
+		metrics = kms.NewMetrics()
+		rootCAs = x509.NewCertPool()
+	)
+	rootCAs.AddCert(srv.Certificate())
+
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(metrics); err != nil {
+		t.Fatalf("Failed to register metrics: %v", err)
+	}
+	if err := srv.Client().CreateKey(ctx, Enclave, &kms.CreateKeyRequest{Name: "my-key"}); err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+
+	// The LB selects either host for the first request of a new client.
+	// Hence, create new clients until one request has been retried.
+	var (
+		requests int
+		clients  []*kms.Client
+	)
+	for retried := false; !retried && requests < 32; requests++ {
+		client, err := kms.NewClient(&kms.Config{
+			Endpoints: []string{Unreachable, srv.Host()},
+			APIKey:    srv.APIKey,
+			TLS:       &tls.Config{RootCAs: rootCAs},
+			Metrics:   metrics,
+		})
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		clients = append(clients, client)
+
+		if _, err = client.KeyStatus(ctx, Enclave, &kms.KeyStatusRequest{Name: "my-key"}, &kms.KeyStatusRequest{Name: "my-key"}); err != nil {
+			t.Fatalf("Failed to fetch key status: %v", err)
+		}
+		retried = testutil.CollectAndCount(metrics, "kms_client_retries_total") > 0
+	}
+
+	const Labels = `command="KEY:STATUS",enclave="` + Enclave + `"`
+	expected := `
+# HELP kms_client_excluded_hosts Hosts currently excluded since a request to them failed.
+# TYPE kms_client_excluded_hosts gauge
+kms_client_excluded_hosts{host="` + Unreachable + `"} 1
+# HELP kms_client_host_exclusions_total Number of times a host got excluded since a request to it failed.
+# TYPE kms_client_host_exclusions_total counter
+kms_client_host_exclusions_total{host="` + Unreachable + `"} 1
+# HELP kms_client_requests_total Number of requests sent to KMS servers.
+# TYPE kms_client_requests_total counter
+kms_client_requests_total{` + Labels + `,host="` + srv.Host() + `"} ` + strconv.Itoa(requests) + `
+# HELP kms_client_retries_total Number of requests retried with another host.
+# TYPE kms_client_retries_total counter
+kms_client_retries_total{host="` + srv.Host() + `"} 1
+`
+	err := testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"kms_client_excluded_hosts", "kms_client_host_exclusions_total", "kms_client_requests_total", "kms_client_retries_total",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := testutil.CollectAndCount(metrics, "kms_client_batch_size"); n != 1 {
+		t.Fatalf("Invalid number of batch size histograms: got %d - want %d", n, 1)
+	}
+
+	// Closed clients must no longer export their excluded hosts.
+	for _, client := range clients {
+		client.Close()
+	}
+	if n := testutil.CollectAndCount(metrics, "kms_client_excluded_hosts"); n != 0 {
+		t.Fatalf("Invalid number of excluded hosts after closing all clients: got %d - want %d", n, 0)
+	}
+
+	client, err := kms.NewClient(&kms.Config{
+		Endpoints: []string{srv.Host()},
+		APIKey:    srv.APIKey,
+		TLS:       &tls.Config{RootCAs: rootCAs},
+		Metrics:   metrics,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if _, err = client.KeyStatus(ctx, Enclave, &kms.KeyStatusRequest{Name: "other-key"}); !errors.Is(err, kms.ErrKeyNotFound) {
+		t.Fatalf("Fetching a non-existing key should have failed: got '%v' - want '%v'", err, kms.ErrKeyNotFound)
+	}
+	expected = `
+# HELP kms_client_errors_total Number of failed requests by error code.
+# TYPE kms_client_errors_total counter
+kms_client_errors_total{code="404",command="KEY:STATUS"} 1
+`
+	if err = testutil.GatherAndCompare(registry, strings.NewReader(expected), "kms_client_errors_total"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,622 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package kms
+
+import (
+	"bytes"
+	"maps"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/minio/kms-go/kms/cmds"
+)
+
+// Policy defines which commands an identity may execute on
+// which resources, like keys or policies, within an enclave.
+//
+// A Policy contains RuleSets of allow and deny rules for each
+// command. A command is allowed for a resource if the resource
+// matches at least one pattern of the command's allow RuleSet
+// and no pattern of its deny RuleSet. Deny rules take precedence
+// over allow rules. Commands without allow rules are denied.
+//
+// A pattern ending with '*' matches all resources starting with
+// the pattern's prefix. For example, "my-key*" matches "my-key"
+// and "my-key-1". Any other pattern only matches resources equal
+// to it.
+//
+// Each pattern is associated with a Rule that may restrict when
+// the pattern applies. For example, a Rule may restrict an allow
+// pattern to requests from certain IP networks. Conditional deny
+// patterns only deny requests for which their Rule applies.
+//
+// A Policy is evaluated the same way a KMS server evaluates the
+// policy assigned to an identity without admin privileges. Hence,
+// it can be used to check permissions before creating or changing
+// a policy.
+type Policy struct {
+	// Allow is the set of allow rules.
+	Allow map[cmds.Command]RuleSet
+
+	// Deny is the set of deny rules.
+	Deny map[cmds.Command]RuleSet
+}
+
+// IsAllowed reports whether the policy allows executing cmd
+// on the given resource for any request, regardless of any Rule
+// conditions. Hence, a resource matching any deny pattern or only
+// conditional allow patterns is not allowed. Use Verify to check
+// whether the policy allows a particular request.
+func (p *Policy) IsAllowed(cmd cmds.Command, resource string) bool {
+	if p.Deny[cmd].Match(resource) {
+		return false
+	}
+	for pattern, rule := range p.Allow[cmd] {
+		if rule.IsEmpty() && match(pattern, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// Verify checks whether the policy allows executing cmd on the given
+// resource for the request req. It returns ErrPermission if a deny
+// pattern matching the resource applies to req or if no allow pattern
+// matching the resource applies to req.
+//
+// Verify fails closed for requests with unknown attributes, like an
+// invalid SourceIP or a zero Time. Deny rules with conditions on such
+// attributes apply while allow rules with such conditions don't. A
+// nil req is equal to an AccessRequest with no known attributes.
+func (p *Policy) Verify(cmd cmds.Command, resource string, req *AccessRequest) error {
+	for pattern, rule := range p.Deny[cmd] {
+		if match(pattern, resource) && rule.applies(req, true) {
+			return ErrPermission
+		}
+	}
+	for pattern, rule := range p.Allow[cmd] {
+		if match(pattern, resource) && rule.Applies(req) {
+			return nil
+		}
+	}
+	return ErrPermission
+}
+
+// IsSubset reports whether the policy p is a subset of o. If it
+// is, then any request to execute a command on a resource allowed
+// by p is also allowed by o. Hence, p grants no more permissions
+// than o.
+//
+// Two policies, A and B, are equivalent, but not necessarily
+// equal, if:
+//
+//	A.IsSubset(B) && B.IsSubset(A)
+//
+// For policies with conditional Rules, IsSubset is conservative.
+// It may report false if p is a subset of o only due to some
+// combination of Rule conditions.
+func (p *Policy) IsSubset(o *Policy) bool {
+	for cmd, allow := range p.Allow {
+		for _, s := range witnesses(allow, p.Deny[cmd], o.Allow[cmd], o.Deny[cmd]) {
+			pAllow, pDeny := matching(allow, s), matching(p.Deny[cmd], s)
+			oAllow, oDeny := matching(o.Allow[cmd], s), matching(o.Deny[cmd], s)
+
+			// Ignore any allow rule of p that never applies since
+			// one of p's deny rules applies whenever it applies.
+			pAllow = slices.DeleteFunc(pAllow, func(a Rule) bool {
+				return slices.ContainsFunc(pDeny, func(d Rule) bool { return ruleCovers(&d, &a) })
+			})
+			if len(pAllow) == 0 {
+				continue
+			}
+
+			for _, a := range pAllow {
+				if !slices.ContainsFunc(oAllow, func(b Rule) bool { return ruleCovers(&b, &a) }) {
+					return false
+				}
+			}
+			for _, d := range oDeny {
+				if !slices.ContainsFunc(pDeny, func(b Rule) bool { return ruleCovers(&b, &d) }) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// IsEquivalent reports whether the policies p and o allow
+// the same commands on the same resources.
+func (p *Policy) IsEquivalent(o *Policy) bool {
+	return p.IsSubset(o) && o.IsSubset(p)
+}
+
+// Intersect returns a normalized policy that allows a command on
+// a resource if and only if both policies, p and o, allow it.
+//
+// If p and o contain the same pattern with different conditional
+// Rules, the intersection may not be expressible as a policy. In
+// such cases, Intersect returns a policy that allows less than
+// the intersection.
+func (p *Policy) Intersect(o *Policy) *Policy {
+	policy := &Policy{
+		Allow: map[cmds.Command]RuleSet{},
+		Deny:  map[cmds.Command]RuleSet{},
+	}
+	for cmd, allow := range p.Allow {
+		other, ok := o.Allow[cmd]
+		if !ok {
+			continue
+		}
+
+		set := RuleSet{}
+		for _, a := range slices.Sorted(maps.Keys(allow)) {
+			for _, b := range slices.Sorted(maps.Keys(other)) {
+				pattern, ok := intersect(a, b)
+				if !ok {
+					continue
+				}
+				rule, ok := ruleAnd(allow[a], other[b])
+				if !ok {
+					continue
+				}
+
+				// Keep the rule that applies whenever the other one
+				// applies. If there is no such rule, keeping either
+				// one allows less than the intersection.
+				if r, ok := set[pattern]; !ok || ruleCovers(&rule, &r) {
+					set[pattern] = rule
+				}
+			}
+		}
+		policy.Allow[cmd] = set
+
+		deny := RuleSet{}
+		maps.Copy(deny, p.Deny[cmd])
+		for pattern, rule := range o.Deny[cmd] {
+			if r, ok := deny[pattern]; ok {
+				rule, _ = ruleOr(r, rule)
+			}
+			deny[pattern] = rule
+		}
+		policy.Deny[cmd] = deny
+	}
+	return policy.Normalize()
+}
+
+// Union returns a normalized policy that allows a command on a
+// resource if p or o allows it. It reports whether the returned
+// policy is exactly the union of p and o.
+//
+// Since deny rules take precedence over allow rules, not every
+// union can be expressed as a single policy. For example, if p
+// allows "my-key*" but denies "my-key-1*" while o allows
+// "my-key-10", the union allows "my-key-10" but no other key
+// starting with "my-key-1". In such cases, Union keeps only
+// those deny rules that don't deny anything the other policy
+// allows, and returns a policy that allows more than p and o
+// combined. The same applies if p and o contain the same pattern
+// with different conditional Rules.
+func (p *Policy) Union(o *Policy) (*Policy, bool) {
+	policy := &Policy{
+		Allow: map[cmds.Command]RuleSet{},
+		Deny:  map[cmds.Command]RuleSet{},
+	}
+	// For commands with conditional rules, exactness cannot be
+	// verified by evaluating resources. Instead, the union of such
+	// a command is inexact if any rule had to be approximated.
+	conditional, inexact := map[cmds.Command]bool{}, map[cmds.Command]bool{}
+	for _, cmd := range slices.Concat(slices.Collect(maps.Keys(p.Allow)), slices.Collect(maps.Keys(o.Allow))) {
+		if _, ok := policy.Allow[cmd]; ok {
+			continue
+		}
+		conditional[cmd] = hasConditions(p.Allow[cmd], p.Deny[cmd], o.Allow[cmd], o.Deny[cmd])
+
+		allow := RuleSet{}
+		maps.Copy(allow, p.Allow[cmd])
+		for pattern, rule := range o.Allow[cmd] {
+			if r, ok := allow[pattern]; ok {
+				var exact bool
+				if rule, exact = ruleOr(r, rule); !exact {
+					inexact[cmd] = true
+				}
+			}
+			allow[pattern] = rule
+		}
+		policy.Allow[cmd] = allow
+
+		// A deny rule of one policy is kept if the other
+		// policy does not allow anything it denies. If both
+		// policies deny something, it is denied as well.
+		deny := RuleSet{}
+		add := func(pattern string, rule Rule) {
+			// Keep the rule that applies whenever the other one
+			// applies. If there is no such rule, keeping either
+			// one denies less than both.
+			if r, ok := deny[pattern]; ok && !ruleCovers(&rule, &r) {
+				if !ruleCovers(&r, &rule) {
+					inexact[cmd] = true
+				}
+				return
+			}
+			deny[pattern] = rule
+		}
+		for _, d := range []struct{ Policy, Other *Policy }{{p, o}, {o, p}} {
+			for _, pattern := range slices.Sorted(maps.Keys(d.Policy.Deny[cmd])) {
+				rule := d.Policy.Deny[cmd][pattern]
+				if !d.Other.allowsAny(cmd, pattern) {
+					add(pattern, rule)
+				} else {
+					inexact[cmd] = true
+				}
+				for other, otherRule := range d.Other.Deny[cmd] {
+					if s, ok := intersect(pattern, other); ok {
+						if r, ok := ruleAnd(rule, otherRule); ok {
+							add(s, r)
+						}
+					}
+				}
+			}
+		}
+		policy.Deny[cmd] = deny
+	}
+	policy = policy.Normalize()
+
+	for cmd, allow := range policy.Allow {
+		if conditional[cmd] {
+			if inexact[cmd] {
+				return policy, false
+			}
+			continue
+		}
+		for _, s := range witnesses(allow, policy.Deny[cmd], p.Allow[cmd], p.Deny[cmd], o.Allow[cmd], o.Deny[cmd]) {
+			if policy.IsAllowed(cmd, s) && !p.IsAllowed(cmd, s) && !o.IsAllowed(cmd, s) {
+				return policy, false
+			}
+		}
+	}
+	return policy, true
+}
+
+// Normalize returns an equivalent policy without redundant rules.
+//
+// It removes patterns shadowed by broader patterns of the same
+// RuleSet. For example, "my-key-1*" is shadowed by "my-key*" unless
+// the Rule of "my-key*" has conditions that the Rule of "my-key-1*"
+// doesn't have. It also removes allow patterns that are shadowed by
+// a deny pattern, deny patterns that don't deny anything allowed and
+// commands that are not allowed for any resource.
+func (p *Policy) Normalize() *Policy {
+	policy := &Policy{
+		Allow: make(map[cmds.Command]RuleSet, len(p.Allow)),
+		Deny:  make(map[cmds.Command]RuleSet, len(p.Deny)),
+	}
+	for cmd, set := range p.Allow {
+		deny := unshadowed(p.Deny[cmd])
+
+		allow := unshadowed(set)
+		maps.DeleteFunc(allow, func(pattern string, rule Rule) bool {
+			for d, r := range deny {
+				if covers(d, pattern) && ruleCovers(&r, &rule) {
+					return true
+				}
+			}
+			return false
+		})
+		if len(allow) == 0 {
+			continue
+		}
+
+		maps.DeleteFunc(deny, func(pattern string, _ Rule) bool {
+			for a := range allow {
+				if _, ok := intersect(pattern, a); ok {
+					return false
+				}
+			}
+			return true
+		})
+
+		policy.Allow[cmd] = allow
+		if len(deny) > 0 {
+			policy.Deny[cmd] = deny
+		}
+	}
+	return policy
+}
+
+// allowsAny reports whether the policy may allow cmd on at
+// least one resource matching the pattern for some request.
+func (p *Policy) allowsAny(cmd cmds.Command, pattern string) bool {
+	for _, s := range witnesses(RuleSet{pattern: {}}, p.Allow[cmd], p.Deny[cmd]) {
+		if !match(pattern, s) || !p.Allow[cmd].Match(s) {
+			continue
+		}
+		if !slices.ContainsFunc(matching(p.Deny[cmd], s), func(r Rule) bool { return r.IsEmpty() }) {
+			return true
+		}
+	}
+	return false
+}
+
+// matching returns the Rules of all patterns of
+// the RuleSet that match the resource.
+func matching(set RuleSet, resource string) []Rule {
+	var rules []Rule
+	for pattern, rule := range set {
+		if match(pattern, resource) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// hasConditions reports whether any of the RuleSets
+// contains a Rule with conditions.
+func hasConditions(sets ...RuleSet) bool {
+	for _, set := range sets {
+		for _, rule := range set {
+			if !rule.IsEmpty() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// unshadowed returns a copy of the RuleSet without empty patterns
+// and patterns shadowed by other, broader patterns with weaker
+// Rules.
+func unshadowed(set RuleSet) RuleSet {
+	s := make(RuleSet, len(set))
+	for pattern, rule := range set {
+		if pattern == "" {
+			continue
+		}
+
+		var shadowed bool
+		for other, r := range set {
+			if other != pattern && other != "" && covers(other, pattern) && ruleCovers(&r, &rule) {
+				shadowed = true
+				break
+			}
+		}
+		if !shadowed {
+			s[pattern] = rule
+		}
+	}
+	return s
+}
+
+// covers reports whether the pattern a matches every resource
+// matched by the pattern b.
+func covers(a, b string) bool {
+	prefixA, isPrefixA := strings.CutSuffix(a, "*")
+	prefixB, isPrefixB := strings.CutSuffix(b, "*")
+	switch {
+	case isPrefixA:
+		return strings.HasPrefix(prefixB, prefixA)
+	case isPrefixB:
+		return false
+	default:
+		return a == b
+	}
+}
+
+// intersect returns a pattern that matches a resource if and
+// only if both patterns, a and b, match it. It reports whether
+// such a pattern exists.
+func intersect(a, b string) (string, bool) {
+	if a == "" || b == "" {
+		return "", false
+	}
+	if covers(a, b) {
+		return b, true
+	}
+	if covers(b, a) {
+		return a, true
+	}
+	return "", false
+}
+
+// ruleCovers reports whether the Rule a applies
+// to every request to which the Rule b applies.
+func ruleCovers(a, b *Rule) bool {
+	if len(a.SourceIPs) > 0 {
+		if len(b.SourceIPs) == 0 {
+			return false
+		}
+		for _, pb := range b.SourceIPs {
+			if !slices.ContainsFunc(a.SourceIPs, func(pa netip.Prefix) bool {
+				return pa.Bits() <= pb.Bits() && pa.Contains(pb.Addr())
+			}) {
+				return false
+			}
+		}
+	}
+	if !a.NotBefore.IsZero() && (b.NotBefore.IsZero() || b.NotBefore.Before(a.NotBefore)) {
+		return false
+	}
+	if !a.NotAfter.IsZero() && (b.NotAfter.IsZero() || b.NotAfter.After(a.NotAfter)) {
+		return false
+	}
+	if a.MaxBatchSize > 0 && (b.MaxBatchSize <= 0 || b.MaxBatchSize > a.MaxBatchSize) {
+		return false
+	}
+	if len(a.AssociatedData) > 0 || len(a.AssociatedDataPrefixes) > 0 {
+		if len(b.AssociatedData) == 0 && len(b.AssociatedDataPrefixes) == 0 {
+			return false
+		}
+		for _, v := range b.AssociatedData {
+			if !a.appliesToData(v) {
+				return false
+			}
+		}
+		for _, prefix := range b.AssociatedDataPrefixes {
+			if !slices.ContainsFunc(a.AssociatedDataPrefixes, func(p []byte) bool { return bytes.HasPrefix(prefix, p) }) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ruleAnd returns a Rule that applies to a request if and
+// only if both Rules, a and b, apply to it. It reports whether
+// such a Rule exists, i.e. whether a and b can apply to the
+// same request.
+func ruleAnd(a, b Rule) (Rule, bool) {
+	var r Rule
+	switch {
+	case len(a.SourceIPs) == 0:
+		r.SourceIPs = b.SourceIPs
+	case len(b.SourceIPs) == 0:
+		r.SourceIPs = a.SourceIPs
+	default:
+		for _, pa := range a.SourceIPs {
+			for _, pb := range b.SourceIPs {
+				if p, ok := intersectPrefix(pa, pb); ok && !slices.Contains(r.SourceIPs, p) {
+					r.SourceIPs = append(r.SourceIPs, p)
+				}
+			}
+		}
+		if len(r.SourceIPs) == 0 {
+			return Rule{}, false
+		}
+	}
+
+	r.NotBefore, r.NotAfter = a.NotBefore, a.NotAfter
+	if b.NotBefore.After(r.NotBefore) {
+		r.NotBefore = b.NotBefore
+	}
+	if !b.NotAfter.IsZero() && (r.NotAfter.IsZero() || b.NotAfter.Before(r.NotAfter)) {
+		r.NotAfter = b.NotAfter
+	}
+	if !r.NotBefore.IsZero() && !r.NotAfter.IsZero() && r.NotAfter.Before(r.NotBefore) {
+		return Rule{}, false
+	}
+
+	r.MaxBatchSize = a.MaxBatchSize
+	if b.MaxBatchSize > 0 && (r.MaxBatchSize <= 0 || b.MaxBatchSize < r.MaxBatchSize) {
+		r.MaxBatchSize = b.MaxBatchSize
+	}
+
+	hasDataA := len(a.AssociatedData) > 0 || len(a.AssociatedDataPrefixes) > 0
+	hasDataB := len(b.AssociatedData) > 0 || len(b.AssociatedDataPrefixes) > 0
+	switch {
+	case !hasDataA:
+		r.AssociatedData, r.AssociatedDataPrefixes = b.AssociatedData, b.AssociatedDataPrefixes
+	case !hasDataB:
+		r.AssociatedData, r.AssociatedDataPrefixes = a.AssociatedData, a.AssociatedDataPrefixes
+	default:
+		add := func(v []byte) {
+			if !slices.ContainsFunc(r.AssociatedData, func(d []byte) bool { return bytes.Equal(d, v) }) {
+				r.AssociatedData = append(r.AssociatedData, v)
+			}
+		}
+		for _, v := range a.AssociatedData {
+			if b.appliesToData(v) {
+				add(v)
+			}
+		}
+		for _, v := range b.AssociatedData {
+			if a.appliesToData(v) {
+				add(v)
+			}
+		}
+		for _, pa := range a.AssociatedDataPrefixes {
+			for _, pb := range b.AssociatedDataPrefixes {
+				p := pa
+				switch {
+				case bytes.HasPrefix(pb, pa):
+					p = pb
+				case !bytes.HasPrefix(pa, pb):
+					continue
+				}
+				if !slices.ContainsFunc(r.AssociatedDataPrefixes, func(d []byte) bool { return bytes.Equal(d, p) }) {
+					r.AssociatedDataPrefixes = append(r.AssociatedDataPrefixes, p)
+				}
+			}
+		}
+		if len(r.AssociatedData) == 0 && len(r.AssociatedDataPrefixes) == 0 {
+			return Rule{}, false
+		}
+	}
+	return r, true
+}
+
+// ruleOr returns a Rule that applies to a request if a or b
+// applies to it. It reports whether the returned Rule applies
+// if and only if a or b applies. Otherwise, the returned Rule
+// applies to more requests.
+func ruleOr(a, b Rule) (Rule, bool) {
+	if ruleCovers(&a, &b) {
+		return a, true
+	}
+	if ruleCovers(&b, &a) {
+		return b, true
+	}
+	return Rule{}, false
+}
+
+// intersectPrefix returns the IP network containing all IP
+// addresses within both networks, a and b. It reports whether
+// such a network exists.
+func intersectPrefix(a, b netip.Prefix) (netip.Prefix, bool) {
+	if a.Bits() <= b.Bits() && a.Contains(b.Addr()) {
+		return b, true
+	}
+	if b.Bits() <= a.Bits() && b.Contains(a.Addr()) {
+		return a, true
+	}
+	return netip.Prefix{}, false
+}
+
+// witnesses returns a finite set of resources that represents
+// all resources with respect to the patterns of the RuleSets.
+//
+// Whether a resource matches a pattern only depends on which
+// pattern literals, i.e. exact patterns or prefixes of patterns
+// ending with '*', are prefixes of or equal to the resource.
+// For any resource, the returned set contains a resource that
+// matches exactly the same patterns: either the longest prefix
+// of the resource that is a prefix of any literal, or such a
+// prefix followed by a byte that no literal continues with.
+//
+// Hence, two policies that behave the same for all returned
+// resources behave the same for any resource.
+func witnesses(sets ...RuleSet) []string {
+	var literals []string
+	for _, set := range sets {
+		for pattern := range set {
+			if pattern != "" {
+				literals = append(literals, strings.TrimSuffix(pattern, "*"))
+			}
+		}
+	}
+
+	prefixes := map[string]struct{}{"": {}}
+	for _, l := range literals {
+		for i := 1; i <= len(l); i++ {
+			prefixes[l[:i]] = struct{}{}
+		}
+	}
+
+	resources := make([]string, 0, 2*len(prefixes))
+	for prefix := range prefixes {
+		resources = append(resources, prefix)
+
+		var next [256]bool
+		for _, l := range literals {
+			if len(l) > len(prefix) && strings.HasPrefix(l, prefix) {
+				next[l[len(prefix)]] = true
+			}
+		}
+		if i := slices.Index(next[:], false); i >= 0 {
+			resources = append(resources, prefix+string([]byte{byte(i)}))
+		}
+	}
+	return resources
+}
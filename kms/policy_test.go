@@ -0,0 +1,482 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package kms_test
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/netip"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/minio/kms-go/kms"
+	"github.com/minio/kms-go/kms/cmds"
+)
+
+func TestPolicy_IsAllowed(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range readPolicyTests(t, "testdata/policies.json") {
+		for _, c := range test.Tests {
+			if allowed := test.Policy.IsAllowed(c.Command, c.Resource); allowed != c.Allowed {
+				t.Fatalf("Test %d: '%v' on '%s': got '%v' - want '%v'", i, c.Command, c.Resource, allowed, c.Allowed)
+			}
+		}
+	}
+}
+
+func TestPolicy_Verify(t *testing.T) {
+	t.Parallel()
+
+	policy := kms.Policy{
+		Allow: map[cmds.Command]kms.RuleSet{
+			cmds.KeyStatus: {"*": kms.Rule{}},
+		},
+		Deny: map[cmds.Command]kms.RuleSet{
+			cmds.KeyStatus: {
+				"my-key":    kms.Rule{SourceIPs: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}},
+				"other-key": kms.Rule{NotBefore: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+	}
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i, test := range []struct {
+		Resource string
+		Request  *kms.AccessRequest
+		Allowed  bool
+	}{
+		{Resource: "my-key", Request: &kms.AccessRequest{SourceIP: netip.MustParseAddr("192.168.1.1")}, Allowed: true},     // 0
+		{Resource: "my-key", Request: &kms.AccessRequest{SourceIP: netip.MustParseAddr("10.1.2.3")}, Allowed: false},       // 1
+		{Resource: "my-key", Request: &kms.AccessRequest{}, Allowed: false},                                                // 2
+		{Resource: "my-key", Request: nil, Allowed: false},                                                                 // 3
+		{Resource: "other-key", Request: &kms.AccessRequest{Time: now.AddDate(-1, 0, 0)}, Allowed: true},                   // 4
+		{Resource: "other-key", Request: &kms.AccessRequest{Time: now}, Allowed: false},                                    // 5
+		{Resource: "other-key", Request: &kms.AccessRequest{SourceIP: netip.MustParseAddr("192.168.1.1")}, Allowed: false}, // 6
+		{Resource: "another-key", Request: nil, Allowed: true},                                                             // 7
+	} {
+		err := policy.Verify(cmds.KeyStatus, test.Resource, test.Request)
+		if allowed := err == nil; allowed != test.Allowed {
+			t.Fatalf("Test %d: '%s': got '%v' - want '%v'", i, test.Resource, allowed, test.Allowed)
+		}
+	}
+}
+
+// policyTest is a policy test vector. The same vectors
+// are used to test the policy evaluation of kmstest.Server.
+type policyTest struct {
+	Policy kms.Policy
+	Tests  []struct {
+		Command  cmds.Command
+		Resource string
+		Allowed  bool
+	}
+}
+
+func readPolicyTests(t *testing.T, filename string) []policyTest {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read policy tests: %v", err)
+	}
+
+	var tests []policyTest
+	if err = json.Unmarshal(b, &tests); err != nil {
+		t.Fatalf("Failed to parse policy tests: %v", err)
+	}
+	if len(tests) == 0 {
+		t.Fatalf("No policy tests found in '%s'", filename)
+	}
+	return tests
+}
+
+func TestPolicy_IsSubset(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range policySubsetTests {
+		if subset := test.A.IsSubset(test.B); subset != test.Subset {
+			t.Fatalf("Test %d: got '%v' - want '%v'", i, subset, test.Subset)
+		}
+		if equivalent := test.A.IsEquivalent(test.B); equivalent != test.Equivalent {
+			t.Fatalf("Test %d: got equivalent '%v' - want '%v'", i, equivalent, test.Equivalent)
+		}
+	}
+}
+
+var policySubsetTests = []struct {
+	A, B       *kms.Policy
+	Subset     bool
+	Equivalent bool
+}{
+	{ // 0
+		A:          &kms.Policy{},
+		B:          &kms.Policy{},
+		Subset:     true,
+		Equivalent: true,
+	},
+	{ // 1
+		A: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"my-key": {}}},
+		},
+		B: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"my-key*": {}}},
+		},
+		Subset: true,
+	},
+	{ // 2
+		A: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"my-key*": {}}},
+		},
+		B: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"my-key": {}}},
+		},
+		Subset: false,
+	},
+	{ // 3
+		A: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"my-key*": {}}},
+		},
+		B: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"my-key*": {}}},
+			Deny:  map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"my-key-1": {}}},
+		},
+		Subset: false,
+	},
+	{ // 4
+		A: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"my-key*": {}}},
+			Deny:  map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"my-key-1*": {}}},
+		},
+		B: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"my-key*": {}}},
+			Deny:  map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"my-key-1": {}}},
+		},
+		Subset: true,
+	},
+	{ // 5
+		A: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"my-key*": {}}},
+		},
+		B: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyGenerate: {"my-key*": {}}},
+		},
+		Subset: false,
+	},
+	{ // 6
+		A: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"my-key*": {}, "my-key-1": {}}},
+			Deny:  map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"other-key": {}}},
+		},
+		B: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"my-key*": {}}},
+		},
+		Subset:     true,
+		Equivalent: true,
+	},
+	{ // 7
+		A: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"my-key": {}, "my-key*": {}}},
+			Deny:  map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"my-key*": {}}},
+		},
+		B:          &kms.Policy{},
+		Subset:     true,
+		Equivalent: true,
+	},
+}
+
+func TestPolicy_Normalize(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range normalizePolicyTests {
+		p := test.Policy.Normalize()
+		if !reflect.DeepEqual(p, test.Normalized) {
+			t.Fatalf("Test %d: got '%v' - want '%v'", i, p, test.Normalized)
+		}
+		if !p.IsEquivalent(test.Policy) {
+			t.Fatalf("Test %d: normalized policy is not equivalent", i)
+		}
+	}
+}
+
+var normalizePolicyTests = []struct {
+	Policy     *kms.Policy
+	Normalized *kms.Policy
+}{
+	{ // 0
+		Policy: &kms.Policy{},
+		Normalized: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{},
+			Deny:  map[cmds.Command]kms.RuleSet{},
+		},
+	},
+	{ // 1
+		Policy: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{
+				cmds.KeyStatus:   {"key-*": {}, "key-a*": {}, "key-b": {}, "other": {}},
+				cmds.KeyGenerate: {"key-a": {}},
+				cmds.KeyDelete:   {},
+			},
+			Deny: map[cmds.Command]kms.RuleSet{
+				cmds.KeyStatus:   {"key-c*": {}, "key-c1": {}, "my-key": {}},
+				cmds.KeyGenerate: {"key-*": {}},
+				cmds.KeyList:     {"*": {}},
+			},
+		},
+		Normalized: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{
+				cmds.KeyStatus: {"key-*": {}, "other": {}},
+			},
+			Deny: map[cmds.Command]kms.RuleSet{
+				cmds.KeyStatus: {"key-c*": {}},
+			},
+		},
+	},
+}
+
+func TestPolicy_Intersect(t *testing.T) {
+	t.Parallel()
+
+	a := &kms.Policy{
+		Allow: map[cmds.Command]kms.RuleSet{
+			cmds.KeyStatus:   {"key-*": {}},
+			cmds.KeyGenerate: {"key-a*": {}, "other": {}},
+		},
+		Deny: map[cmds.Command]kms.RuleSet{
+			cmds.KeyStatus: {"key-a1": {}},
+		},
+	}
+	b := &kms.Policy{
+		Allow: map[cmds.Command]kms.RuleSet{
+			cmds.KeyStatus:   {"key-a*": {}, "key-b": {}},
+			cmds.KeyGenerate: {"key-*": {}},
+			cmds.KeyDelete:   {"*": {}},
+		},
+	}
+	want := &kms.Policy{
+		Allow: map[cmds.Command]kms.RuleSet{
+			cmds.KeyStatus:   {"key-a*": {}, "key-b": {}},
+			cmds.KeyGenerate: {"key-a*": {}},
+		},
+		Deny: map[cmds.Command]kms.RuleSet{
+			cmds.KeyStatus: {"key-a1": {}},
+		},
+	}
+	if p := a.Intersect(b); !reflect.DeepEqual(p, want) {
+		t.Fatalf("Invalid intersection: got '%v' - want '%v'", p, want)
+	}
+}
+
+func TestPolicy_Union(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range unionPolicyTests {
+		p, exact := test.A.Union(test.B)
+		if !reflect.DeepEqual(p, test.Union) {
+			t.Fatalf("Test %d: got '%v' - want '%v'", i, p, test.Union)
+		}
+		if exact != test.Exact {
+			t.Fatalf("Test %d: got exact '%v' - want '%v'", i, exact, test.Exact)
+		}
+		if !test.A.IsSubset(p) || !test.B.IsSubset(p) {
+			t.Fatalf("Test %d: policies are not a subset of their union", i)
+		}
+	}
+}
+
+var unionPolicyTests = []struct {
+	A, B  *kms.Policy
+	Union *kms.Policy
+	Exact bool
+}{
+	{ // 0
+		A: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"key-a*": {}}},
+			Deny:  map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"key-a1": {}}},
+		},
+		B: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"key-b*": {}}, cmds.KeyGenerate: {"*": {}}},
+		},
+		Union: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"key-a*": {}, "key-b*": {}}, cmds.KeyGenerate: {"*": {}}},
+			Deny:  map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"key-a1": {}}},
+		},
+		Exact: true,
+	},
+	{ // 1
+		A: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"key-*": {}}},
+			Deny:  map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"key-a*": {}}},
+		},
+		B: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"key-a*": {}}},
+			Deny:  map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"key-a1*": {}}},
+		},
+		Union: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"key-*": {}}},
+			Deny:  map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"key-a1*": {}}},
+		},
+		Exact: true,
+	},
+	{ // 2
+		A: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"my-key*": {}}},
+			Deny:  map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"my-key-1*": {}}},
+		},
+		B: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"my-key-10": {}}},
+		},
+		Union: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyStatus: {"my-key*": {}}},
+			Deny:  map[cmds.Command]kms.RuleSet{},
+		},
+		Exact: false,
+	},
+}
+
+func TestPolicy_Algebra(t *testing.T) {
+	t.Parallel()
+
+	// Compare the policy algebra against evaluating random
+	// policies for all resources up to some length.
+	var (
+		random    = rand.New(rand.NewSource(1))
+		patterns  = []string{"*", "a", "a*", "ab", "ab*", "abc", "b*", "ba", "ba*"}
+		resources = []string{""}
+	)
+	for i := 0; i < len(resources) && len(resources[i]) < 4; i++ {
+		for _, c := range "abc" {
+			resources = append(resources, resources[i]+string(c))
+		}
+	}
+	randomPolicy := func() *kms.Policy {
+		set := func() kms.RuleSet {
+			s := kms.RuleSet{}
+			for n := random.Intn(3); n > 0; n-- {
+				s[patterns[random.Intn(len(patterns))]] = kms.Rule{}
+			}
+			return s
+		}
+		return &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyStatus: set()},
+			Deny:  map[cmds.Command]kms.RuleSet{cmds.KeyStatus: set()},
+		}
+	}
+
+	for i := 0; i < 1000; i++ {
+		a, b := randomPolicy(), randomPolicy()
+		union, exact := a.Union(b)
+		intersection := a.Intersect(b)
+		normalized := a.Normalize()
+
+		subset, isExact := true, true
+		for _, r := range resources {
+			allowedA, allowedB := a.IsAllowed(cmds.KeyStatus, r), b.IsAllowed(cmds.KeyStatus, r)
+			if allowedA && !allowedB {
+				subset = false
+			}
+			if union.IsAllowed(cmds.KeyStatus, r) != (allowedA || allowedB) {
+				isExact = false
+			}
+			if (allowedA || allowedB) && !union.IsAllowed(cmds.KeyStatus, r) {
+				t.Fatalf("Test %d: union denies '%s': got '%v' - '%v'", i, r, union, [2]*kms.Policy{a, b})
+			}
+			if intersection.IsAllowed(cmds.KeyStatus, r) != (allowedA && allowedB) {
+				t.Fatalf("Test %d: invalid intersection for '%s': got '%v' - '%v'", i, r, intersection, [2]*kms.Policy{a, b})
+			}
+			if normalized.IsAllowed(cmds.KeyStatus, r) != allowedA {
+				t.Fatalf("Test %d: invalid normalization for '%s': got '%v' - '%v'", i, r, normalized, a)
+			}
+		}
+		if a.IsSubset(b) != subset {
+			t.Fatalf("Test %d: got subset '%v' - want '%v': '%v'", i, !subset, subset, [2]*kms.Policy{a, b})
+		}
+		if exact != isExact {
+			t.Fatalf("Test %d: got exact '%v' - want '%v': '%v'", i, exact, isExact, [2]*kms.Policy{a, b})
+		}
+	}
+}
+
+func TestPolicy_AlgebraConditions(t *testing.T) {
+	t.Parallel()
+
+	// Compare the policy algebra against verifying requests to
+	// random policies with conditional rules. For conditional
+	// rules, the algebra may be conservative but must be sound.
+	var (
+		random    = rand.New(rand.NewSource(1))
+		patterns  = []string{"*", "a", "a*", "ab", "ab*", "b*"}
+		resources = []string{"", "a", "ab", "abc", "b", "ba", "c"}
+		rules     = []kms.Rule{
+			{},
+			{SourceIPs: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}},
+			{SourceIPs: []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16")}},
+			{SourceIPs: []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16"), netip.MustParsePrefix("192.168.0.0/16")}},
+			{MaxBatchSize: 1},
+			{MaxBatchSize: 2, AssociatedDataPrefixes: [][]byte{[]byte("x")}},
+			{AssociatedData: [][]byte{[]byte("xy"), []byte("z")}},
+			{AssociatedDataPrefixes: [][]byte{[]byte("xy")}},
+		}
+		requests []kms.AccessRequest
+	)
+	for _, ip := range []string{"10.1.2.3", "10.2.3.4", "192.168.1.1", "172.16.1.1"} {
+		for _, n := range []int{1, 2, 3} {
+			for _, data := range []string{"", "x", "xy", "xyz", "z"} {
+				requests = append(requests, kms.AccessRequest{
+					SourceIP:       netip.MustParseAddr(ip),
+					BatchSize:      n,
+					AssociatedData: []byte(data),
+				})
+			}
+		}
+	}
+	randomPolicy := func() *kms.Policy {
+		set := func() kms.RuleSet {
+			s := kms.RuleSet{}
+			for n := random.Intn(3); n > 0; n-- {
+				s[patterns[random.Intn(len(patterns))]] = rules[random.Intn(len(rules))]
+			}
+			return s
+		}
+		return &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{cmds.KeyStatus: set()},
+			Deny:  map[cmds.Command]kms.RuleSet{cmds.KeyStatus: set()},
+		}
+	}
+
+	for i := 0; i < 1000; i++ {
+		a, b := randomPolicy(), randomPolicy()
+		union, exact := a.Union(b)
+		intersection := a.Intersect(b)
+		normalized := a.Normalize()
+
+		subset := true
+		for _, r := range resources {
+			for _, req := range requests {
+				allowedA := a.Verify(cmds.KeyStatus, r, &req) == nil
+				allowedB := b.Verify(cmds.KeyStatus, r, &req) == nil
+				if allowedA && !allowedB {
+					subset = false
+				}
+
+				allowed := union.Verify(cmds.KeyStatus, r, &req) == nil
+				if (allowedA || allowedB) && !allowed {
+					t.Fatalf("Test %d: union denies '%s' for '%+v': got '%v' - '%v'", i, r, req, union, [2]*kms.Policy{a, b})
+				}
+				if exact && allowed != (allowedA || allowedB) {
+					t.Fatalf("Test %d: inexact union for '%s' for '%+v': got '%v' - '%v'", i, r, req, union, [2]*kms.Policy{a, b})
+				}
+				if allowed = intersection.Verify(cmds.KeyStatus, r, &req) == nil; allowed && !(allowedA && allowedB) {
+					t.Fatalf("Test %d: invalid intersection for '%s' for '%+v': got '%v' - '%v'", i, r, req, intersection, [2]*kms.Policy{a, b})
+				}
+				if allowed = normalized.Verify(cmds.KeyStatus, r, &req) == nil; allowed != allowedA {
+					t.Fatalf("Test %d: invalid normalization for '%s' for '%+v': got '%v' - '%v'", i, r, req, normalized, a)
+				}
+			}
+		}
+		if a.IsSubset(b) && !subset {
+			t.Fatalf("Test %d: got subset 'true' - want 'false': '%v'", i, [2]*kms.Policy{a, b})
+		}
+	}
+}
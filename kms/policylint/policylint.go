@@ -0,0 +1,501 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+// Package policylint analyzes KMS policies for rules that are
+// likely mistakes, like rules that can never apply or overly
+// broad grants.
+//
+// Each Finding has a Severity. For example, a CI pipeline may
+// reject policies with findings of severity Error, or Warning,
+// before creating them on a KMS server:
+//
+//	findings := policylint.Check(&kms.Policy{Allow: req.Allow, Deny: req.Deny})
+//	if policylint.MaxSeverity(findings) >= policylint.Warning {
+//		// Reject policy
+//	}
+//
+// Policies are only evaluated for identities with the User
+// privilege. Identities with the Admin or SysAdmin privilege
+// are not restricted by any policy. Hence, Check assumes that
+// a policy is assigned to User identities.
+package policylint
+
+import (
+	"cmp"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/minio/kms-go/kms"
+	"github.com/minio/kms-go/kms/cmds"
+)
+
+// Severity describes how likely a Finding is a mistake.
+type Severity int
+
+// All severities in ascending order.
+const (
+	// Info indicates that a policy contains redundant or
+	// unusual rules that are most likely not a mistake.
+	Info Severity = iota + 1
+
+	// Warning indicates that a policy contains rules that
+	// may not behave as intended or grant more permissions
+	// than necessary.
+	Warning
+
+	// Error indicates that a policy contains rules that
+	// are invalid or can never apply.
+	Error
+)
+
+// String returns the string representation of the Severity.
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	default:
+		return "Severity(" + strconv.Itoa(int(s)) + ")"
+	}
+}
+
+// MarshalText returns the Severity's text representation.
+func (s Severity) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+// All checks performed by Check.
+const (
+	// CheckUnknownCommand reports rules for commands
+	// that don't exist.
+	CheckUnknownCommand = "unknown-command"
+
+	// CheckInvalidRule reports rules with invalid
+	// conditions. Refer to kms.Rule.Validate.
+	//
+	// For policies checked by CheckJSON, the remaining
+	// rules of a command with invalid rules are skipped.
+	CheckInvalidRule = "invalid-rule"
+
+	// CheckClusterCommand reports rules for cluster-level
+	// commands. Such commands require the SysAdmin privilege
+	// and are never allowed by a policy.
+	CheckClusterCommand = "cluster-command"
+
+	// CheckShadowedAllow reports allow patterns that are
+	// shadowed by deny patterns and never allow anything.
+	CheckShadowedAllow = "shadowed-allow"
+
+	// CheckUnusedDeny reports deny patterns that don't deny
+	// anything allowed by an allow pattern.
+	CheckUnusedDeny = "unused-deny"
+
+	// CheckRedundantPattern reports patterns that are redundant
+	// since another pattern of the same RuleSet matches every
+	// resource and request they match.
+	CheckRedundantPattern = "redundant-pattern"
+
+	// CheckOverlappingPattern reports patterns that match only
+	// resources also matched by another pattern of the same
+	// RuleSet, but with different rule conditions.
+	CheckOverlappingPattern = "overlapping-pattern"
+
+	// CheckBroadWrite reports allow patterns that grant a
+	// command changing state, like KEY:DELETE, on all
+	// resources.
+	CheckBroadWrite = "broad-write"
+
+	// CheckNoAllow reports policies that don't allow
+	// anything.
+	CheckNoAllow = "no-allow"
+)
+
+// Finding is a potential mistake within a policy.
+type Finding struct {
+	// Severity describes how likely the Finding is a mistake.
+	Severity Severity `json:"severity"`
+
+	// Check is the check that reported the Finding.
+	Check string `json:"check"`
+
+	// Command is the command the Finding refers to. It is
+	// empty if the Finding refers to the entire policy.
+	Command string `json:"command,omitempty"`
+
+	// Pattern is the pattern the Finding refers to, if any.
+	Pattern string `json:"pattern,omitempty"`
+
+	// Deny indicates whether Pattern is a deny pattern.
+	Deny bool `json:"deny,omitempty"`
+
+	// Message describes the Finding.
+	Message string `json:"message"`
+}
+
+// String returns a human-readable representation of the Finding.
+func (f Finding) String() string {
+	var b strings.Builder
+	b.WriteString(f.Severity.String())
+	b.WriteString(": ")
+	if f.Command != "" {
+		b.WriteString(f.Command)
+		if f.Pattern != "" {
+			if f.Deny {
+				b.WriteString(" deny '")
+			} else {
+				b.WriteString(" allow '")
+			}
+			b.WriteString(f.Pattern)
+			b.WriteByte('\'')
+		}
+		b.WriteString(": ")
+	}
+	b.WriteString(f.Message)
+	b.WriteString(" [")
+	b.WriteString(f.Check)
+	b.WriteByte(']')
+	return b.String()
+}
+
+// MaxSeverity returns the highest severity of all findings,
+// or 0 if there are no findings.
+func MaxSeverity(findings []Finding) Severity {
+	var s Severity
+	for _, f := range findings {
+		if f.Severity > s {
+			s = f.Severity
+		}
+	}
+	return s
+}
+
+// Check analyzes the policy and returns its findings sorted
+// by severity, starting with the highest severity, command
+// and pattern.
+func Check(p *kms.Policy) []Finding {
+	findings := checkCommands(p)
+	if !allowsAny(p) {
+		findings = append(findings, noAllow())
+	}
+
+	sortFindings(findings)
+	return findings
+}
+
+// CheckRequest analyzes the policy of a CreatePolicyRequest.
+// Refer to Check.
+func CheckRequest(req *kms.CreatePolicyRequest) []Finding {
+	return Check(&kms.Policy{Allow: req.Allow, Deny: req.Deny})
+}
+
+// CheckResponse analyzes the policy of a PolicyResponse.
+// Refer to Check.
+func CheckResponse(resp *kms.PolicyResponse) []Finding {
+	return Check(&kms.Policy{Allow: resp.Allow, Deny: resp.Deny})
+}
+
+// CheckJSON analyzes the JSON representation of a policy, like
+// a CreatePolicyRequest. In contrast to decoding the policy as
+// kms.Policy, unknown command names and invalid rules are reported
+// as findings instead of errors. It returns an error if b is not
+// a valid JSON policy otherwise.
+func CheckJSON(b []byte) ([]Finding, error) {
+	var v struct {
+		Allow map[string]json.RawMessage `json:"allow"`
+		Deny  map[string]json.RawMessage `json:"deny"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+
+	var (
+		findings []Finding
+		skipped  bool // Whether allow rules have been skipped due to errors
+		policy   = &kms.Policy{
+			Allow: make(map[cmds.Command]kms.RuleSet, len(v.Allow)),
+			Deny:  make(map[cmds.Command]kms.RuleSet, len(v.Deny)),
+		}
+	)
+	for _, d := range []struct {
+		Sets  map[string]json.RawMessage
+		Rules map[cmds.Command]kms.RuleSet
+		Deny  bool
+	}{{v.Allow, policy.Allow, false}, {v.Deny, policy.Deny, true}} {
+		for name, raw := range d.Sets {
+			cmd, err := cmds.Parse(name)
+			if err != nil {
+				findings = append(findings, Finding{
+					Severity: Error,
+					Check:    CheckUnknownCommand,
+					Command:  name,
+					Deny:     d.Deny,
+					Message:  "unknown command",
+				})
+				skipped = skipped || !d.Deny
+				continue
+			}
+
+			set, invalid, err := decodeRuleSet(raw)
+			if err != nil {
+				return nil, err
+			}
+			for _, pattern := range slices.Sorted(maps.Keys(invalid)) {
+				findings = append(findings, Finding{
+					Severity: Error,
+					Check:    CheckInvalidRule,
+					Command:  name,
+					Pattern:  pattern,
+					Deny:     d.Deny,
+					Message:  invalid[pattern].Error(),
+				})
+			}
+			if len(invalid) > 0 {
+				skipped = skipped || !d.Deny
+				continue
+			}
+			d.Rules[cmd] = set
+		}
+	}
+
+	findings = append(findings, checkCommands(policy)...)
+	if !skipped && !allowsAny(policy) {
+		findings = append(findings, noAllow())
+	}
+	sortFindings(findings)
+	return findings, nil
+}
+
+// decodeRuleSet decodes the JSON representation of a RuleSet.
+// If the RuleSet contains invalid rules, it returns the error
+// of each invalid rule's pattern instead.
+func decodeRuleSet(b []byte) (kms.RuleSet, map[string]error, error) {
+	var set kms.RuleSet
+	err := json.Unmarshal(b, &set)
+	if err == nil {
+		return set, nil, nil
+	}
+
+	var rules map[string]json.RawMessage
+	if json.Unmarshal(b, &rules) != nil {
+		return nil, nil, err
+	}
+	invalid := map[string]error{}
+	for pattern, rule := range rules {
+		if e := json.Unmarshal(rule, new(kms.Rule)); e != nil {
+			invalid[pattern] = e
+		}
+	}
+	if len(invalid) == 0 {
+		return nil, nil, err
+	}
+	return nil, invalid, nil
+}
+
+// checkCommands returns the findings of the rules of all
+// commands of the policy.
+func checkCommands(p *kms.Policy) []Finding {
+	var findings []Finding
+	for _, cmd := range commands(p) {
+		findings = append(findings, checkCommand(p, cmd)...)
+	}
+	return findings
+}
+
+// allowsAny reports whether the policy contains at least
+// one allow pattern.
+func allowsAny(p *kms.Policy) bool {
+	return slices.ContainsFunc(slices.Collect(maps.Values(p.Allow)), func(set kms.RuleSet) bool {
+		return len(set) > 0
+	})
+}
+
+// noAllow returns the Finding of a policy that does not
+// allow anything.
+func noAllow() Finding {
+	return Finding{
+		Severity: Warning,
+		Check:    CheckNoAllow,
+		Message:  "policy does not allow any command",
+	}
+}
+
+// checkCommand returns the findings of the rules for cmd.
+func checkCommand(p *kms.Policy, cmd cmds.Command) []Finding {
+	allow, deny := p.Allow[cmd], p.Deny[cmd]
+
+	name := cmd.String()
+	if _, err := cmd.MarshalText(); err != nil {
+		return []Finding{{
+			Severity: Error,
+			Check:    CheckUnknownCommand,
+			Command:  name,
+			Message:  "unknown command",
+		}}
+	}
+	if cmd.IsCluster() {
+		return []Finding{{
+			Severity: Error,
+			Check:    CheckClusterCommand,
+			Command:  name,
+			Message:  "cluster-level commands require the SysAdmin privilege and are never allowed by a policy",
+		}}
+	}
+
+	var findings []Finding
+	for _, d := range []struct {
+		Set  kms.RuleSet
+		Deny bool
+	}{{allow, false}, {deny, true}} {
+		for pattern, rule := range d.Set {
+			if err := rule.Validate(); err != nil {
+				findings = append(findings, Finding{
+					Severity: Error,
+					Check:    CheckInvalidRule,
+					Command:  name,
+					Pattern:  pattern,
+					Deny:     d.Deny,
+					Message:  err.Error(),
+				})
+			}
+		}
+		findings = append(findings, checkRuleSet(cmd, d.Set, d.Deny)...)
+	}
+	if MaxSeverity(findings) >= Error {
+		return findings
+	}
+
+	for pattern, rule := range allow {
+		if allowsNothing(cmd, pattern, rule, deny) {
+			findings = append(findings, Finding{
+				Severity: Warning,
+				Check:    CheckShadowedAllow,
+				Command:  name,
+				Pattern:  pattern,
+				Message:  "allow pattern is shadowed by deny patterns and never allows anything",
+			})
+			continue
+		}
+		if cmd.IsWrite() && pattern == "*" {
+			severity := Warning
+			if !rule.IsEmpty() {
+				severity = Info
+			}
+			findings = append(findings, Finding{
+				Severity: severity,
+				Check:    CheckBroadWrite,
+				Command:  name,
+				Pattern:  pattern,
+				Message:  "command changes state and is allowed on all resources",
+			})
+		}
+	}
+	for pattern := range deny {
+		if !slices.ContainsFunc(slices.Collect(maps.Keys(allow)), func(a string) bool {
+			return covers(cmd, a, kms.Rule{}, pattern, kms.Rule{}) || covers(cmd, pattern, kms.Rule{}, a, kms.Rule{})
+		}) {
+			findings = append(findings, Finding{
+				Severity: Info,
+				Check:    CheckUnusedDeny,
+				Command:  name,
+				Pattern:  pattern,
+				Deny:     true,
+				Message:  "deny pattern does not deny anything allowed",
+			})
+		}
+	}
+	return findings
+}
+
+// checkRuleSet reports redundant and overlapping patterns
+// within the RuleSet.
+func checkRuleSet(cmd cmds.Command, set kms.RuleSet, deny bool) []Finding {
+	var findings []Finding
+	for _, pattern := range slices.Sorted(maps.Keys(set)) {
+		var (
+			covering    string
+			overlapping string
+		)
+		for _, other := range slices.Sorted(maps.Keys(set)) {
+			if other == pattern || !covers(cmd, other, kms.Rule{}, pattern, kms.Rule{}) {
+				continue
+			}
+			if covers(cmd, other, set[other], pattern, set[pattern]) {
+				covering = other
+				break
+			}
+			if overlapping == "" {
+				overlapping = other
+			}
+		}
+
+		switch {
+		case covering != "":
+			findings = append(findings, Finding{
+				Severity: Info,
+				Check:    CheckRedundantPattern,
+				Command:  cmd.String(),
+				Pattern:  pattern,
+				Deny:     deny,
+				Message:  "pattern is already covered by the broader pattern '" + covering + "'",
+			})
+		case overlapping != "":
+			findings = append(findings, Finding{
+				Severity: Info,
+				Check:    CheckOverlappingPattern,
+				Command:  cmd.String(),
+				Pattern:  pattern,
+				Deny:     deny,
+				Message:  "pattern overlaps with pattern '" + overlapping + "' with different rule conditions",
+			})
+		}
+	}
+	return findings
+}
+
+// covers reports whether the pattern a with Rule ra matches
+// every resource and request matched by the pattern b with
+// Rule rb.
+func covers(cmd cmds.Command, a string, ra kms.Rule, b string, rb kms.Rule) bool {
+	pa := &kms.Policy{Allow: map[cmds.Command]kms.RuleSet{cmd: {a: ra}}}
+	pb := &kms.Policy{Allow: map[cmds.Command]kms.RuleSet{cmd: {b: rb}}}
+	return pb.IsSubset(pa)
+}
+
+// allowsNothing reports whether the allow pattern with the
+// given Rule never allows anything due to the deny RuleSet.
+func allowsNothing(cmd cmds.Command, pattern string, rule kms.Rule, deny kms.RuleSet) bool {
+	p := &kms.Policy{
+		Allow: map[cmds.Command]kms.RuleSet{cmd: {pattern: rule}},
+		Deny:  map[cmds.Command]kms.RuleSet{cmd: deny},
+	}
+	return p.IsSubset(&kms.Policy{})
+}
+
+// commands returns all commands of the policy in ascending order.
+func commands(p *kms.Policy) []cmds.Command {
+	c := slices.Concat(slices.Collect(maps.Keys(p.Allow)), slices.Collect(maps.Keys(p.Deny)))
+	slices.Sort(c)
+	return slices.Compact(c)
+}
+
+// sortFindings sorts the findings by severity, starting
+// with the highest severity, command and pattern.
+func sortFindings(findings []Finding) {
+	slices.SortStableFunc(findings, func(a, b Finding) int {
+		if n := cmp.Compare(b.Severity, a.Severity); n != 0 {
+			return n
+		}
+		if n := cmp.Compare(a.Command, b.Command); n != 0 {
+			return n
+		}
+		if n := cmp.Compare(a.Pattern, b.Pattern); n != 0 {
+			return n
+		}
+		return cmp.Compare(a.Check, b.Check)
+	})
+}
@@ -0,0 +1,214 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package policylint_test
+
+import (
+	"net/netip"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/minio/kms-go/kms"
+	"github.com/minio/kms-go/kms/cmds"
+	"github.com/minio/kms-go/kms/policylint"
+)
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range checkTests {
+		findings := policylint.Check(test.Policy)
+		if len(findings) != len(test.Findings) {
+			t.Fatalf("Test %d: got %d findings - want %d: %v", i, len(findings), len(test.Findings), findings)
+		}
+		for j, f := range findings {
+			want := test.Findings[j]
+			if f.Severity != want.Severity || f.Check != want.Check || f.Command != want.Command || f.Pattern != want.Pattern || f.Deny != want.Deny {
+				t.Fatalf("Test %d: finding %d: got '%v' - want '%v'", i, j, f, want)
+			}
+		}
+		if s := policylint.MaxSeverity(findings); s != test.MaxSeverity {
+			t.Fatalf("Test %d: got max severity '%v' - want '%v'", i, s, test.MaxSeverity)
+		}
+	}
+}
+
+func TestCheckJSON(t *testing.T) {
+	t.Parallel()
+
+	findings, err := policylint.CheckJSON([]byte(`{
+		"allow": {
+			"KEY:STATUS": ["my-key*"],
+			"KEY:RENAME": ["my-key*"]
+		},
+		"deny": {
+			"KEY:STATUS": "my-key-1*"
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to check policy: %v", err)
+	}
+	want := policylint.Finding{Severity: policylint.Error, Check: policylint.CheckUnknownCommand, Command: "KEY:RENAME"}
+	if len(findings) != 1 || findings[0].Check != want.Check || findings[0].Command != want.Command || findings[0].Severity != want.Severity {
+		t.Fatalf("Invalid findings: got '%v' - want '%v'", findings, []policylint.Finding{want})
+	}
+
+	findings, err = policylint.CheckJSON([]byte(`{"allow": {"KEY:STATUS": {"my-key": {"max_batch_size": -1}, "other-key": {}}}}`))
+	if err != nil {
+		t.Fatalf("Failed to check policy: %v", err)
+	}
+	want = policylint.Finding{Severity: policylint.Error, Check: policylint.CheckInvalidRule, Command: "KEY:STATUS", Pattern: "my-key"}
+	if len(findings) != 1 || findings[0].Check != want.Check || findings[0].Command != want.Command || findings[0].Pattern != want.Pattern {
+		t.Fatalf("Invalid findings: got '%v' - want '%v'", findings, []policylint.Finding{want})
+	}
+
+	// Policies with only unknown commands allow nothing but
+	// must only be reported as unknown commands.
+	findings, err = policylint.CheckJSON([]byte(`{"allow": {"KEY:RENAME": "my-key"}}`))
+	if err != nil {
+		t.Fatalf("Failed to check policy: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Check != policylint.CheckUnknownCommand {
+		t.Fatalf("Invalid findings: got '%v' - want one '%s' finding", findings, policylint.CheckUnknownCommand)
+	}
+
+	if _, err = policylint.CheckJSON([]byte(`{"allow": {"KEY:STATUS": 42}}`)); err == nil {
+		t.Fatal("Checking a policy with an invalid rule set should have failed")
+	}
+}
+
+func TestFinding_String(t *testing.T) {
+	t.Parallel()
+
+	f := policylint.Finding{
+		Severity: policylint.Warning,
+		Check:    policylint.CheckBroadWrite,
+		Command:  "KEY:DELETE",
+		Pattern:  "*",
+		Message:  "command changes state and is allowed on all resources",
+	}
+	const want = "warning: KEY:DELETE allow '*': command changes state and is allowed on all resources [broad-write]"
+	if s := f.String(); s != want {
+		t.Fatalf("got '%s' - want '%s'", s, want)
+	}
+}
+
+var checkTests = []struct {
+	Policy      *kms.Policy
+	Findings    []policylint.Finding
+	MaxSeverity policylint.Severity
+}{
+	{ // 0
+		Policy: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{
+				cmds.KeyStatus:  {"my-key*": {}},
+				cmds.KeyEncrypt: {"my-key": {}},
+			},
+		},
+	},
+	{ // 1
+		Policy: &kms.Policy{},
+		Findings: []policylint.Finding{
+			{Severity: policylint.Warning, Check: policylint.CheckNoAllow},
+		},
+		MaxSeverity: policylint.Warning,
+	},
+	{ // 2
+		Policy: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{
+				cmds.ClusterAddNode: {"*": {}},
+				cmds.KeyStatus:      {"*": {}},
+				cmds.Command(999):   {"*": {}},
+			},
+		},
+		Findings: []policylint.Finding{
+			{Severity: policylint.Error, Check: policylint.CheckUnknownCommand, Command: "!INVALID:999"},
+			{Severity: policylint.Error, Check: policylint.CheckClusterCommand, Command: "CLUSTER:ADDNODE"},
+		},
+		MaxSeverity: policylint.Error,
+	},
+	{ // 3
+		Policy: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{
+				cmds.KeyStatus: {"my-key-1": {}, "my-key*": {}},
+			},
+			Deny: map[cmds.Command]kms.RuleSet{
+				cmds.KeyStatus: {"my-key-1*": {}, "other-key": {}},
+			},
+		},
+		Findings: []policylint.Finding{
+			{Severity: policylint.Warning, Check: policylint.CheckShadowedAllow, Command: "KEY:STATUS", Pattern: "my-key-1"},
+			{Severity: policylint.Info, Check: policylint.CheckRedundantPattern, Command: "KEY:STATUS", Pattern: "my-key-1"},
+			{Severity: policylint.Info, Check: policylint.CheckUnusedDeny, Command: "KEY:STATUS", Pattern: "other-key", Deny: true},
+		},
+		MaxSeverity: policylint.Warning,
+	},
+	{ // 4
+		Policy: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{
+				cmds.KeyDelete: {"*": {}},
+				cmds.KeyCreate: {"*": {SourceIPs: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}}},
+				cmds.KeyStatus: {"*": {}},
+			},
+		},
+		Findings: []policylint.Finding{
+			{Severity: policylint.Warning, Check: policylint.CheckBroadWrite, Command: "KEY:DELETE", Pattern: "*"},
+			{Severity: policylint.Info, Check: policylint.CheckBroadWrite, Command: "KEY:CREATE", Pattern: "*"},
+		},
+		MaxSeverity: policylint.Warning,
+	},
+	{ // 5
+		Policy: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{
+				cmds.KeyDecrypt: {
+					"my-key*":  {SourceIPs: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}},
+					"my-key-1": {SourceIPs: []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16")}},
+					"my-key-2": {MaxBatchSize: 1},
+				},
+			},
+			Deny: map[cmds.Command]kms.RuleSet{
+				cmds.KeyDecrypt: {"my-key-1": {SourceIPs: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}}},
+			},
+		},
+		Findings: []policylint.Finding{
+			{Severity: policylint.Warning, Check: policylint.CheckShadowedAllow, Command: "KEY:DECRYPT", Pattern: "my-key-1"},
+			{Severity: policylint.Info, Check: policylint.CheckRedundantPattern, Command: "KEY:DECRYPT", Pattern: "my-key-1"},
+			{Severity: policylint.Info, Check: policylint.CheckOverlappingPattern, Command: "KEY:DECRYPT", Pattern: "my-key-2"},
+		},
+		MaxSeverity: policylint.Warning,
+	},
+	{ // 6
+		Policy: &kms.Policy{
+			Allow: map[cmds.Command]kms.RuleSet{
+				cmds.KeyStatus: {"my-key": {
+					NotBefore: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+					NotAfter:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				}},
+			},
+		},
+		Findings: []policylint.Finding{
+			{Severity: policylint.Error, Check: policylint.CheckInvalidRule, Command: "KEY:STATUS", Pattern: "my-key"},
+		},
+		MaxSeverity: policylint.Error,
+	},
+}
+
+func TestCheckRequest(t *testing.T) {
+	t.Parallel()
+
+	findings := policylint.CheckRequest(&kms.CreatePolicyRequest{
+		Name: "my-policy",
+		Allow: map[cmds.Command]kms.RuleSet{
+			cmds.EnclaveCreate: {"*": {}},
+		},
+	})
+	checks := make([]string, 0, len(findings))
+	for _, f := range findings {
+		checks = append(checks, f.Check)
+	}
+	if want := []string{policylint.CheckClusterCommand}; !slices.Equal(checks, want) {
+		t.Fatalf("Invalid findings: got '%v' - want '%v'", checks, want)
+	}
+}
@@ -13,6 +13,7 @@ package protobuf
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -28,6 +29,26 @@ type Rule struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	// SourceIPs is a list of IP networks in CIDR notation. If not
+	// empty, the rule only applies to requests sent from an IP
+	// address within one of these networks.
+	SourceIPs []string `protobuf:"bytes,1,rep,name=SourceIPs,json=source_ips,proto3" json:"SourceIPs,omitempty"`
+	// NotBefore, if set, restricts the rule to requests received
+	// at or after this point in time.
+	NotBefore *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=NotBefore,json=not_before,proto3" json:"NotBefore,omitempty"`
+	// NotAfter, if set, restricts the rule to requests received
+	// at or before this point in time.
+	NotAfter *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=NotAfter,json=not_after,proto3" json:"NotAfter,omitempty"`
+	// MaxBatchSize, if not zero, restricts the rule to requests
+	// containing at most this many commands.
+	MaxBatchSize uint32 `protobuf:"varint,4,opt,name=MaxBatchSize,json=max_batch_size,proto3" json:"MaxBatchSize,omitempty"`
+	// AssociatedData and AssociatedDataPrefixes, if not empty,
+	// restrict the rule to commands with associated data that
+	// is equal to one of the values or starts with one of the
+	// prefixes.
+	AssociatedData         [][]byte `protobuf:"bytes,5,rep,name=AssociatedData,json=associated_data,proto3" json:"AssociatedData,omitempty"`
+	AssociatedDataPrefixes [][]byte `protobuf:"bytes,6,rep,name=AssociatedDataPrefixes,json=associated_data_prefixes,proto3" json:"AssociatedDataPrefixes,omitempty"`
 }
 
 func (x *Rule) Reset() {
@@ -62,6 +83,48 @@ func (*Rule) Descriptor() ([]byte, []int) {
 	return file_rule_proto_rawDescGZIP(), []int{0}
 }
 
+func (x *Rule) GetSourceIPs() []string {
+	if x != nil {
+		return x.SourceIPs
+	}
+	return nil
+}
+
+func (x *Rule) GetNotBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NotBefore
+	}
+	return nil
+}
+
+func (x *Rule) GetNotAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NotAfter
+	}
+	return nil
+}
+
+func (x *Rule) GetMaxBatchSize() uint32 {
+	if x != nil {
+		return x.MaxBatchSize
+	}
+	return 0
+}
+
+func (x *Rule) GetAssociatedData() [][]byte {
+	if x != nil {
+		return x.AssociatedData
+	}
+	return nil
+}
+
+func (x *Rule) GetAssociatedDataPrefixes() [][]byte {
+	if x != nil {
+		return x.AssociatedDataPrefixes
+	}
+	return nil
+}
+
 type RuleSet struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -113,17 +176,37 @@ var File_rule_proto protoreflect.FileDescriptor
 
 var file_rule_proto_rawDesc = []byte{
 	0x0a, 0x0a, 0x72, 0x75, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x09, 0x6d, 0x69,
-	0x6e, 0x69, 0x6f, 0x2e, 0x6b, 0x6d, 0x73, 0x22, 0x06, 0x0a, 0x04, 0x52, 0x75, 0x6c, 0x65, 0x22,
-	0x89, 0x01, 0x0a, 0x07, 0x52, 0x75, 0x6c, 0x65, 0x53, 0x65, 0x74, 0x12, 0x33, 0x0a, 0x05, 0x52,
-	0x75, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x6d, 0x69, 0x6e,
-	0x69, 0x6f, 0x2e, 0x6b, 0x6d, 0x73, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x53, 0x65, 0x74, 0x2e, 0x52,
-	0x75, 0x6c, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x05, 0x72, 0x75, 0x6c, 0x65, 0x73,
-	0x1a, 0x49, 0x0a, 0x0a, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10,
-	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79,
-	0x12, 0x25, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x0f, 0x2e, 0x6d, 0x69, 0x6e, 0x69, 0x6f, 0x2e, 0x6b, 0x6d, 0x73, 0x2e, 0x52, 0x75, 0x6c, 0x65,
-	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x42, 0x0b, 0x5a, 0x09, 0x2f,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x6e, 0x69, 0x6f, 0x2e, 0x6b, 0x6d, 0x73, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xa2, 0x02, 0x0a, 0x04, 0x52, 0x75, 0x6c,
+	0x65, 0x12, 0x1d, 0x0a, 0x09, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x49, 0x50, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x69, 0x70, 0x73,
+	0x12, 0x39, 0x0a, 0x09, 0x4e, 0x6f, 0x74, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x0a, 0x6e, 0x6f, 0x74, 0x5f, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x12, 0x37, 0x0a, 0x08, 0x4e,
+	0x6f, 0x74, 0x41, 0x66, 0x74, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x6e, 0x6f, 0x74, 0x5f, 0x61,
+	0x66, 0x74, 0x65, 0x72, 0x12, 0x24, 0x0a, 0x0c, 0x4d, 0x61, 0x78, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x53, 0x69, 0x7a, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0e, 0x6d, 0x61, 0x78, 0x5f,
+	0x62, 0x61, 0x74, 0x63, 0x68, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x27, 0x0a, 0x0e, 0x41, 0x73,
+	0x73, 0x6f, 0x63, 0x69, 0x61, 0x74, 0x65, 0x64, 0x44, 0x61, 0x74, 0x61, 0x18, 0x05, 0x20, 0x03,
+	0x28, 0x0c, 0x52, 0x0f, 0x61, 0x73, 0x73, 0x6f, 0x63, 0x69, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x64,
+	0x61, 0x74, 0x61, 0x12, 0x38, 0x0a, 0x16, 0x41, 0x73, 0x73, 0x6f, 0x63, 0x69, 0x61, 0x74, 0x65,
+	0x64, 0x44, 0x61, 0x74, 0x61, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x65, 0x73, 0x18, 0x06, 0x20,
+	0x03, 0x28, 0x0c, 0x52, 0x18, 0x61, 0x73, 0x73, 0x6f, 0x63, 0x69, 0x61, 0x74, 0x65, 0x64, 0x5f,
+	0x64, 0x61, 0x74, 0x61, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x65, 0x73, 0x22, 0x89, 0x01,
+	0x0a, 0x07, 0x52, 0x75, 0x6c, 0x65, 0x53, 0x65, 0x74, 0x12, 0x33, 0x0a, 0x05, 0x52, 0x75, 0x6c,
+	0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x6d, 0x69, 0x6e, 0x69, 0x6f,
+	0x2e, 0x6b, 0x6d, 0x73, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x53, 0x65, 0x74, 0x2e, 0x52, 0x75, 0x6c,
+	0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x05, 0x72, 0x75, 0x6c, 0x65, 0x73, 0x1a, 0x49,
+	0x0a, 0x0a, 0x52, 0x75, 0x6c, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x25,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e,
+	0x6d, 0x69, 0x6e, 0x69, 0x6f, 0x2e, 0x6b, 0x6d, 0x73, 0x2e, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x42, 0x0b, 0x5a, 0x09, 0x2f, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -140,18 +223,21 @@ func file_rule_proto_rawDescGZIP() []byte {
 
 var file_rule_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
 var file_rule_proto_goTypes = []interface{}{
-	(*Rule)(nil),    // 0: minio.kms.Rule
-	(*RuleSet)(nil), // 1: minio.kms.RuleSet
-	nil,             // 2: minio.kms.RuleSet.RulesEntry
+	(*Rule)(nil),                  // 0: minio.kms.Rule
+	(*RuleSet)(nil),               // 1: minio.kms.RuleSet
+	nil,                           // 2: minio.kms.RuleSet.RulesEntry
+	(*timestamppb.Timestamp)(nil), // 3: google.protobuf.Timestamp
 }
 var file_rule_proto_depIdxs = []int32{
-	2, // 0: minio.kms.RuleSet.Rules:type_name -> minio.kms.RuleSet.RulesEntry
-	0, // 1: minio.kms.RuleSet.RulesEntry.value:type_name -> minio.kms.Rule
-	2, // [2:2] is the sub-list for method output_type
-	2, // [2:2] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	3, // 0: minio.kms.Rule.NotBefore:type_name -> google.protobuf.Timestamp
+	3, // 1: minio.kms.Rule.NotAfter:type_name -> google.protobuf.Timestamp
+	2, // 2: minio.kms.RuleSet.Rules:type_name -> minio.kms.RuleSet.RulesEntry
+	0, // 3: minio.kms.RuleSet.RulesEntry.value:type_name -> minio.kms.Rule
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_rule_proto_init() }
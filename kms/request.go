@@ -42,6 +42,19 @@ type Request struct {
 	// multiple encoded commands to be executed by
 	// the KMS.
 	Body []byte
+
+	// Partial indicates whether the KMS should execute
+	// all commands within the request body even if some
+	// of them fail. If true, the response contains a
+	// response or a cmds.Error for each command.
+	// Otherwise, the entire request fails if one of its
+	// commands fails.
+	//
+	// KMS servers that don't support partial results
+	// ignore Partial. Hence, the entire request fails
+	// if one of its commands fails, even if Partial is
+	// true.
+	Partial bool
 }
 
 // ListRequest contains generic options for listing elements,
@@ -581,6 +594,40 @@ func (r *KeyStatusRequest) UnmarshalPB(v *pb.KeyStatusRequest) error {
 	return nil
 }
 
+// ListKeyVersionsRequest contains options for listing
+// all versions of a key ring.
+type ListKeyVersionsRequest struct {
+	// Name is the name of the key ring.
+	Name string
+
+	// ContinueAt specifies a key version from where to
+	// continue listing. If <= 0, the listing starts at
+	// the first, oldest, key version.
+	ContinueAt int
+
+	// Limit limits the number of key versions returned
+	// by a list operation. If <= 0, no limit is specified
+	// and the server limits listing results to a
+	// reasonable max. size.
+	Limit int
+}
+
+// MarshalPB converts the ListKeyVersionsRequest into its protobuf representation.
+func (r *ListKeyVersionsRequest) MarshalPB(v *pb.ListKeyVersionsRequest) error {
+	v.Name = r.Name
+	v.ContinueAt = uint32(max(r.ContinueAt, 0))
+	v.Limit = uint32(max(r.Limit, 0))
+	return nil
+}
+
+// UnmarshalPB initializes the ListKeyVersionsRequest from its protobuf representation.
+func (r *ListKeyVersionsRequest) UnmarshalPB(v *pb.ListKeyVersionsRequest) error {
+	r.Name = v.Name
+	r.ContinueAt = int(v.ContinueAt)
+	r.Limit = int(v.Limit)
+	return nil
+}
+
 // EncryptRequest contains a plaintext message that should be encrypted and
 // associated data that is crypto. bound to the resulting ciphertext.
 type EncryptRequest struct {
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"time"
 
+	"aead.dev/mem"
 	"aead.dev/mtls"
 	"github.com/minio/kms-go/kms/cmds"
 	"github.com/minio/kms-go/kms/internal/headers"
@@ -52,6 +53,66 @@ func decodeResponse[M, S any, P pb.Pointer[M], T interface {
 	return responses, nil
 }
 
+// decodeResults decodes a multi-command response that may
+// contain a cmds.Error instead of a response for each command.
+// It returns an error if the response does not contain exactly
+// n results.
+func decodeResults[M, S any, P pb.Pointer[M], T interface {
+	pb.Unmarshaler[P]
+	*S
+}](r *http.Response, c cmds.Command, n int) ([]Result[T], error) {
+	if r.ContentLength < 0 {
+		return nil, &HostError{
+			Host: r.Request.Host,
+			Err:  Error{http.StatusLengthRequired, "request content length is negative"},
+		}
+	}
+
+	p := pool.Get(int(r.ContentLength))
+	defer pool.Put(p)
+
+	buf := (*p)[:r.ContentLength]
+	if _, err := io.ReadFull(r.Body, buf); err != nil {
+		return nil, hostError(r.Request.Host, err)
+	}
+
+	results := make([]Result[T], 0, n)
+	for len(buf) > 0 {
+		if len(results) == n {
+			return nil, &HostError{
+				Host: r.Request.Host,
+				Err:  errors.New("kms: response contains more results than requests"),
+			}
+		}
+
+		var (
+			e   Error
+			ok  bool
+			err error
+		)
+		if buf, e, ok, err = decodeError(buf); err != nil {
+			return nil, hostError(r.Request.Host, err)
+		}
+		if ok {
+			results = append(results, Result[T]{Err: hostError(r.Request.Host, e)})
+			continue
+		}
+
+		var s S
+		if buf, err = cmds.Decode[M, P, T](buf, c, &s); err != nil {
+			return nil, hostError(r.Request.Host, err)
+		}
+		results = append(results, Result[T]{Response: &s})
+	}
+	if len(results) != n {
+		return nil, &HostError{
+			Host: r.Request.Host,
+			Err:  errors.New("kms: response contains fewer results than requests"),
+		}
+	}
+	return results, nil
+}
+
 func decodeResponseMessage(r *http.Response, c cmds.Command, v proto.Message) error {
 	if r.ContentLength < 0 {
 		return &HostError{
@@ -116,6 +177,14 @@ func readProtoResponse(r *http.Response, v proto.Message) error {
 	return protojson.Unmarshal(buf, v)
 }
 
+// Result is the result of a single command within a request
+// that may partially fail. Either the Response or the Err is
+// set. Err is of type *HostError.
+type Result[T any] struct {
+	Response T
+	Err      error
+}
+
 // A Page contains the next items of type T from a paginated listing.
 // It's ContinueAt pointer refers to the next page, if any.
 type Page[T any] struct {
@@ -330,6 +399,41 @@ func (s *ServerStatusResponse) UnmarshalPB(v *pb.ServerStatusResponse) error {
 	return nil
 }
 
+// APIResponse describes an API endpoint of a KMS server.
+type APIResponse struct {
+	// Method is the HTTP method of the API. For example, "POST".
+	Method string
+
+	// Path is the API path. For example, "/v1/kms/".
+	Path string
+
+	// MaxBody is the max. size of request bodies accepted by the API.
+	// Requests with larger bodies are rejected by the KMS server.
+	MaxBody mem.Size
+
+	// Timeout is the amount of time after which requests to the API
+	// time out.
+	Timeout time.Duration
+}
+
+// MarshalPB converts the APIResponse into its protobuf representation.
+func (r *APIResponse) MarshalPB(v *pb.APIResponse) error {
+	v.Method = r.Method
+	v.Path = r.Path
+	v.MaxBody = uint64(max(r.MaxBody, 0))
+	v.Timeout = pb.Duration(r.Timeout)
+	return nil
+}
+
+// UnmarshalPB initializes the APIResponse from its protobuf representation.
+func (r *APIResponse) UnmarshalPB(v *pb.APIResponse) error {
+	r.Method = v.Method
+	r.Path = v.Path
+	r.MaxBody = mem.Size(v.MaxBody)
+	r.Timeout = v.Timeout.AsDuration()
+	return nil
+}
+
 // ProfileStatusResponse contains profiling status information about
 // KMS server.
 type ProfileStatusResponse struct {
@@ -0,0 +1,487 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+// Package rewrap implements a pipeline for re-encrypting stored
+// ciphertexts with the latest version of a KMS key.
+//
+// After rotating a key, ciphertexts produced by older key versions
+// have to be re-encrypted before these key versions can be deleted.
+// Run reads stored records from a Source, decrypts and re-encrypts
+// them in batches and passes the new ciphertexts to a Sink, which
+// is responsible for storing them.
+//
+// Run reports its progress, including a checkpoint, after every
+// batch. A Source that starts right after the checkpoint allows
+// resuming an interrupted run.
+package rewrap
+
+import (
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+	"sync"
+
+	"github.com/minio/kms-go/kms"
+)
+
+// Default limits used by Run.
+const (
+	DefaultBatchSize   = 100
+	DefaultConcurrency = 4
+)
+
+// Record is a stored ciphertext.
+type Record struct {
+	// Cursor identifies the record's position within the
+	// Source. Run reports the cursor of the last record
+	// that has been processed, together with all records
+	// before it, as checkpoint.
+	Cursor string
+
+	// Version is the key version used to produce the
+	// ciphertext.
+	Version int
+
+	// Ciphertext is the stored ciphertext.
+	Ciphertext []byte
+
+	// AssociatedData is the associated data bound to
+	// the ciphertext.
+	AssociatedData []byte
+}
+
+// Source is an iterator over stored records. Next returns
+// io.EOF once there are no more records.
+type Source interface {
+	Next(context.Context) (Record, error)
+}
+
+// Sink stores the re-encrypted ciphertext of the record. It
+// is called concurrently by multiple goroutines. If it returns
+// an error, Run stops and returns this error.
+type Sink func(ctx context.Context, record Record, resp *kms.EncryptResponse) error
+
+// Config is a structure containing configuration options
+// for re-encrypting ciphertexts.
+type Config struct {
+	// Client is the KMS client used to decrypt and encrypt
+	// ciphertexts.
+	Client *kms.Client
+
+	// Enclave is the enclave containing the key.
+	Enclave string
+
+	// Name is the name of the key used to produce the
+	// ciphertexts.
+	Name string
+
+	// Version is the key version used for re-encrypting
+	// ciphertexts. Records with this or a newer version
+	// are not re-encrypted. If zero, the latest key
+	// version is used.
+	Version int
+
+	// BatchSize is the max. number of records decrypted and
+	// encrypted with a single request. If zero,
+	// DefaultBatchSize is used.
+	BatchSize int
+
+	// Concurrency is the max. number of batches processed
+	// concurrently. If zero, DefaultConcurrency is used.
+	Concurrency int
+
+	// ContinueOnError controls whether Run continues when a
+	// single record cannot be re-encrypted, for example since
+	// its ciphertext is not authentic. Such records are counted
+	// as failed and remain on their key version. By default,
+	// Run stops and returns the error.
+	//
+	// KMS servers that don't support partial results fail a
+	// batch as a whole if a single record cannot be decrypted
+	// or encrypted. In this case, Run retries the records of
+	// the batch one by one.
+	ContinueOnError bool
+
+	// Progress, if not nil, is called after every processed
+	// batch. Calls to Progress never happen concurrently.
+	Progress func(Progress)
+}
+
+// Progress contains information about a re-encryption run.
+type Progress struct {
+	// Checkpoint is the cursor of the last record that has
+	// been processed, together with all records before it.
+	// An interrupted run can be resumed by starting the
+	// Source right after the checkpoint.
+	Checkpoint string
+
+	Read      uint64 // Number of records read from the Source
+	Rewrapped uint64 // Number of records re-encrypted and stored
+	Current   uint64 // Number of records already on the target key version
+	Failed    uint64 // Number of records that could not be re-encrypted
+
+	// OldVersions contains the number of records, per key
+	// version, that have been read but are still on an old
+	// key version, either since they are still processed or
+	// since they could not be re-encrypted.
+	OldVersions map[int]uint64
+}
+
+// Remaining returns the number of records read so far that
+// are still on an old key version.
+func (p *Progress) Remaining() uint64 {
+	var n uint64
+	for _, c := range p.OldVersions {
+		n += c
+	}
+	return n
+}
+
+// Run reads all records from src, re-encrypts records produced by
+// an old key version and passes the new ciphertexts to sink. Records
+// are decrypted and encrypted in batches, with up to conf.Concurrency
+// batches in flight.
+//
+// Run returns the final Progress and the first error encountered,
+// if any.
+func Run(ctx context.Context, conf *Config, src Source, sink Sink) (Progress, error) {
+	version := conf.Version
+	if version <= 0 {
+		stat, err := conf.Client.KeyStatus(ctx, conf.Enclave, &kms.KeyStatusRequest{Name: conf.Name})
+		if err != nil {
+			return Progress{}, err
+		}
+		if len(stat) != 1 {
+			return Progress{}, errors.New("rewrap: invalid key status response")
+		}
+		version = stat[0].Version
+	}
+	batchSize := conf.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	concurrency := conf.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	r := &runner{
+		conf:    conf,
+		version: version,
+		sink:    sink,
+		cancel:  cancel,
+		done:    map[uint64]string{},
+		progress: Progress{
+			OldVersions: map[int]uint64{},
+		},
+	}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, concurrency)
+		seq uint64
+	)
+	for eof := false; !eof; {
+		batch := make([]Record, 0, batchSize)
+		for len(batch) < batchSize {
+			record, err := src.Next(ctx)
+			if errors.Is(err, io.EOF) {
+				eof = true
+				break
+			}
+			if err != nil {
+				r.fail(err)
+				eof = true
+				break
+			}
+			batch = append(batch, record)
+		}
+		if len(batch) == 0 || r.failed() {
+			break
+		}
+		r.read(batch)
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			r.fail(ctx.Err())
+		}
+		if r.failed() {
+			break
+		}
+
+		wg.Add(1)
+		go func(seq uint64, batch []Record) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			r.process(ctx, seq, batch)
+		}(seq, batch)
+		seq++
+	}
+	wg.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.snapshot(), r.err
+}
+
+// runner holds the state of a single Run.
+type runner struct {
+	conf    *Config
+	version int
+	sink    Sink
+	cancel  context.CancelFunc
+
+	mu       sync.Mutex
+	err      error             // First error encountered
+	next     uint64            // Sequence number of the next batch to checkpoint
+	done     map[uint64]string // Completed batches not yet checkpointed
+	progress Progress
+}
+
+// read counts the records of a batch read from the Source.
+func (r *runner) read(batch []Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, record := range batch {
+		r.progress.Read++
+		if record.Version >= r.version {
+			r.progress.Current++
+		} else {
+			r.progress.OldVersions[record.Version]++
+		}
+	}
+}
+
+// process re-encrypts all records of the batch that are
+// on an old key version and passes them to the sink.
+func (r *runner) process(ctx context.Context, seq uint64, batch []Record) {
+	var (
+		records = make([]Record, 0, len(batch))
+		reqs    = make([]*kms.DecryptRequest, 0, len(batch))
+	)
+	for _, record := range batch {
+		if record.Version < r.version {
+			records = append(records, record)
+			reqs = append(reqs, &kms.DecryptRequest{
+				Name:           r.conf.Name,
+				Version:        record.Version,
+				Ciphertext:     record.Ciphertext,
+				AssociatedData: record.AssociatedData,
+			})
+		}
+	}
+
+	if len(reqs) > 0 {
+		if err := r.rewrap(ctx, records, reqs); err != nil {
+			r.fail(err)
+			return
+		}
+	}
+	r.complete(seq, batch[len(batch)-1].Cursor)
+}
+
+// rewrap decrypts and encrypts the records and passes
+// the new ciphertexts to the sink.
+func (r *runner) rewrap(ctx context.Context, records []Record, reqs []*kms.DecryptRequest) error {
+	plaintexts, err := r.decrypt(ctx, reqs)
+	if err != nil {
+		return err
+	}
+	if len(plaintexts) != len(reqs) {
+		return errors.New("rewrap: invalid number of decrypt responses")
+	}
+
+	var (
+		decrypted = make([]Record, 0, len(records))
+		encReqs   = make([]*kms.EncryptRequest, 0, len(records))
+	)
+	for i, result := range plaintexts {
+		if result.Err != nil {
+			if err = r.recordFailed(result.Err); err != nil {
+				return err
+			}
+			continue
+		}
+		decrypted = append(decrypted, records[i])
+		encReqs = append(encReqs, &kms.EncryptRequest{
+			Name:           r.conf.Name,
+			Version:        r.version,
+			Plaintext:      result.Response.Plaintext,
+			AssociatedData: records[i].AssociatedData,
+		})
+	}
+	defer func() {
+		for _, req := range encReqs {
+			clear(req.Plaintext)
+		}
+	}()
+	if len(encReqs) == 0 {
+		return nil
+	}
+
+	ciphertexts, err := r.encrypt(ctx, encReqs)
+	if err != nil {
+		return err
+	}
+	if len(ciphertexts) != len(encReqs) {
+		return errors.New("rewrap: invalid number of encrypt responses")
+	}
+	for i, result := range ciphertexts {
+		if result.Err != nil {
+			if err = r.recordFailed(result.Err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err = r.sink(ctx, decrypted[i], result.Response); err != nil {
+			return err
+		}
+
+		r.mu.Lock()
+		r.progress.Rewrapped++
+		if r.progress.OldVersions[decrypted[i].Version]--; r.progress.OldVersions[decrypted[i].Version] == 0 {
+			delete(r.progress.OldVersions, decrypted[i].Version)
+		}
+		r.mu.Unlock()
+	}
+	return nil
+}
+
+// decrypt decrypts all ciphertexts with a single batch request.
+// If the batch fails as a whole due to an invalid request, for
+// example since the KMS server does not support partial results,
+// and the run should continue on errors, it decrypts the
+// ciphertexts one by one.
+func (r *runner) decrypt(ctx context.Context, reqs []*kms.DecryptRequest) ([]kms.Result[*kms.DecryptResponse], error) {
+	results, err := r.conf.Client.DecryptBatch(ctx, r.conf.Enclave, reqs...)
+	if err == nil || len(reqs) == 1 || !r.isRecordError(err) {
+		return results, err
+	}
+
+	results = make([]kms.Result[*kms.DecryptResponse], 0, len(reqs))
+	for _, req := range reqs {
+		resp, err := r.conf.Client.Decrypt(ctx, r.conf.Enclave, req)
+		if err != nil && !r.isRecordError(err) {
+			return nil, err
+		}
+
+		result := kms.Result[*kms.DecryptResponse]{Err: err}
+		if err == nil {
+			if len(resp) != 1 {
+				return nil, errors.New("rewrap: invalid number of decrypt responses")
+			}
+			result.Response = resp[0]
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// encrypt encrypts all plaintexts with a single batch request.
+// Like decrypt, it encrypts the plaintexts one by one if the
+// batch fails as a whole due to an invalid request and the run
+// should continue on errors.
+func (r *runner) encrypt(ctx context.Context, reqs []*kms.EncryptRequest) ([]kms.Result[*kms.EncryptResponse], error) {
+	results, err := r.conf.Client.EncryptBatch(ctx, r.conf.Enclave, reqs...)
+	if err == nil || len(reqs) == 1 || !r.isRecordError(err) {
+		return results, err
+	}
+
+	results = make([]kms.Result[*kms.EncryptResponse], 0, len(reqs))
+	for _, req := range reqs {
+		resp, err := r.conf.Client.Encrypt(ctx, r.conf.Enclave, req)
+		if err != nil && !r.isRecordError(err) {
+			return nil, err
+		}
+
+		result := kms.Result[*kms.EncryptResponse]{Err: err}
+		if err == nil {
+			if len(resp) != 1 {
+				return nil, errors.New("rewrap: invalid number of encrypt responses")
+			}
+			result.Response = resp[0]
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// isRecordError reports whether err may be caused by a single
+// record, like an inauthentic ciphertext, and the run should
+// continue on such errors. Other errors, like ErrKeyNotFound
+// or network errors, apply to all records.
+func (r *runner) isRecordError(err error) bool {
+	var e kms.Error
+	return r.conf.ContinueOnError && errors.As(err, &e) && e.Code == http.StatusBadRequest
+}
+
+// recordFailed counts a record that could not be re-encrypted.
+// It returns err unless the run should continue on errors.
+func (r *runner) recordFailed(err error) error {
+	if !r.conf.ContinueOnError {
+		return err
+	}
+
+	r.mu.Lock()
+	r.progress.Failed++
+	r.mu.Unlock()
+	return nil
+}
+
+// complete marks the batch with the given sequence number
+// as processed and advances the checkpoint.
+func (r *runner) complete(seq uint64, cursor string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.done[seq] = cursor
+	for {
+		cursor, ok := r.done[r.next]
+		if !ok {
+			break
+		}
+		delete(r.done, r.next)
+		r.progress.Checkpoint = cursor
+		r.next++
+	}
+
+	if r.conf.Progress != nil && r.err == nil {
+		r.conf.Progress(r.snapshot())
+	}
+}
+
+// fail records the first error and stops the run.
+func (r *runner) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err == nil {
+		r.err = err
+		r.cancel()
+	}
+}
+
+// failed reports whether the run has been stopped.
+func (r *runner) failed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.err != nil
+}
+
+// snapshot returns a copy of the current progress. It
+// must only be called when r.mu is locked.
+func (r *runner) snapshot() Progress {
+	p := r.progress
+	p.OldVersions = maps.Clone(r.progress.OldVersions)
+	return p
+}
@@ -0,0 +1,166 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package rewrap_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/minio/kms-go/kms"
+	"github.com/minio/kms-go/kms/kmstest"
+	"github.com/minio/kms-go/kms/rewrap"
+)
+
+type sliceSource []rewrap.Record
+
+func (s *sliceSource) Next(context.Context) (rewrap.Record, error) {
+	if len(*s) == 0 {
+		return rewrap.Record{}, io.EOF
+	}
+	r := (*s)[0]
+	*s = (*s)[1:]
+	return r, nil
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	testRun(t, srv.Client())
+}
+
+func TestRun_NoPartialResults(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	// noPartial simulates a KMS server that does not support
+	// partial results and fails a batch as a whole.
+	noPartial := func(ctx context.Context, call *kms.Call, next kms.Invoker) (*http.Response, error) {
+		call.Request.Partial = false
+		return next(ctx, call)
+	}
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(srv.Certificate())
+	client, err := kms.NewClient(&kms.Config{
+		Endpoints:    []string{srv.Host()},
+		APIKey:       srv.APIKey,
+		TLS:          &tls.Config{RootCAs: rootCAs},
+		Interceptors: []kms.Interceptor{noPartial},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	testRun(t, client)
+}
+
+func testRun(t *testing.T, client *kms.Client) {
+	const (
+		Enclave = kmstest.DefaultEnclave
+		KeyName = "my-key"
+		N       = 50
+	)
+	ctx := context.Background()
+
+	var (
+		records    sliceSource
+		plaintexts = map[string][]byte{}
+	)
+	for v := 1; v <= 3; v++ {
+		if err := client.CreateKey(ctx, Enclave, &kms.CreateKeyRequest{Name: KeyName, AddVersion: v > 1}); err != nil {
+			t.Fatalf("Failed to create key version %d: %v", v, err)
+		}
+		for i := 0; i < N; i++ {
+			cursor := strconv.Itoa(len(records))
+			plaintexts[cursor] = []byte("message-" + cursor)
+
+			resp, err := client.Encrypt(ctx, Enclave, &kms.EncryptRequest{
+				Name:           KeyName,
+				Plaintext:      plaintexts[cursor],
+				AssociatedData: []byte(cursor),
+			})
+			if err != nil {
+				t.Fatalf("Failed to encrypt: %v", err)
+			}
+			records = append(records, rewrap.Record{
+				Cursor:         cursor,
+				Version:        resp[0].Version,
+				Ciphertext:     resp[0].Ciphertext,
+				AssociatedData: []byte(cursor),
+			})
+		}
+	}
+	records[7].Ciphertext[0] ^= 1 // Corrupt one record on key version 1
+
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+	conf := &rewrap.Config{
+		Client:          client,
+		Enclave:         Enclave,
+		Name:            KeyName,
+		BatchSize:       8,
+		Concurrency:     3,
+		ContinueOnError: true,
+		Progress:        func(rewrap.Progress) { calls++ },
+	}
+	sink := func(ctx context.Context, record rewrap.Record, resp *kms.EncryptResponse) error {
+		if resp.Version != 3 {
+			return errors.New("invalid key version " + strconv.Itoa(resp.Version))
+		}
+		plaintext, err := client.Decrypt(ctx, Enclave, &kms.DecryptRequest{
+			Name:           KeyName,
+			Version:        resp.Version,
+			Ciphertext:     resp.Ciphertext,
+			AssociatedData: record.AssociatedData,
+		})
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if !bytes.Equal(plaintext[0].Plaintext, plaintexts[record.Cursor]) {
+			return errors.New("plaintext mismatch for record " + record.Cursor)
+		}
+		delete(plaintexts, record.Cursor)
+		return nil
+	}
+
+	progress, err := rewrap.Run(ctx, conf, &records, sink)
+	if err != nil {
+		t.Fatalf("Failed to re-encrypt records: %v", err)
+	}
+	if progress.Read != 3*N || progress.Rewrapped != 2*N-1 || progress.Current != N || progress.Failed != 1 {
+		t.Fatalf("Progress mismatch: got %+v", progress)
+	}
+	if progress.Remaining() != 1 || progress.OldVersions[1] != 1 {
+		t.Fatalf("Invalid number of remaining records: got %v - want %d", progress.OldVersions, 1)
+	}
+	if progress.Checkpoint != strconv.Itoa(3*N-1) {
+		t.Fatalf("Checkpoint mismatch: got '%s' - want '%d'", progress.Checkpoint, 3*N-1)
+	}
+	if want := (3*N + conf.BatchSize - 1) / conf.BatchSize; calls != want {
+		t.Fatalf("Invalid number of progress reports: got %d - want %d", calls, want)
+	}
+	if len(plaintexts) != N+1 {
+		t.Fatalf("Invalid number of records not re-encrypted: got %d - want %d", len(plaintexts), N+1)
+	}
+}
@@ -5,16 +5,287 @@
 package kms
 
 import (
+	"bytes"
 	"cmp"
 	"encoding/json"
+	"errors"
+	"math"
+	"net/netip"
 	"slices"
+	"strings"
+	"time"
 
 	pb "github.com/minio/kms-go/kms/protobuf"
 )
 
 // Rule is a policy rule allowing for more fine-grain
 // API access control.
-type Rule struct{}
+//
+// A Rule defines conditions under which a policy pattern
+// applies to a request. The empty Rule applies to any
+// request. Otherwise, a Rule only applies to a request
+// if all its conditions hold. Refer to Rule.Applies.
+//
+// KMS servers that don't support rule conditions ignore
+// them when a policy is created. Such servers apply a
+// conditional Rule to any request. Hence, a conditional
+// allow rule grants access unconditionally. Conditions
+// should only be used with servers that support them.
+// Conditional deny rules are not affected since they
+// deny any request on such servers.
+//
+// Rule contains slices and, therefore, is not comparable.
+// Unlike previous versions, Rules cannot be compared with
+// == or used as map keys. Use IsEmpty to check whether a
+// Rule has no conditions.
+type Rule struct {
+	// SourceIPs, if not empty, restricts the Rule to
+	// requests sent from an IP address within one of
+	// these networks.
+	SourceIPs []netip.Prefix
+
+	// NotBefore, if not zero, restricts the Rule to requests
+	// received at or after this point in time.
+	NotBefore time.Time
+
+	// NotAfter, if not zero, restricts the Rule to requests
+	// received at or before this point in time.
+	NotAfter time.Time
+
+	// MaxBatchSize, if greater than zero, restricts the Rule
+	// to requests containing at most MaxBatchSize commands.
+	MaxBatchSize int
+
+	// AssociatedData and AssociatedDataPrefixes, if not empty,
+	// restrict the Rule to commands with associated data that
+	// is equal to one of the AssociatedData values or starts
+	// with one of the AssociatedDataPrefixes. For example, to
+	// restrict decryption to the associated data of a service.
+	//
+	// Commands without associated data, like KEY:STATUS, only
+	// match empty values or prefixes. Hence, such conditions
+	// should only be used for commands with associated data,
+	// like KEY:ENCRYPT, KEY:DECRYPT or KEY:GENERATE.
+	AssociatedData         [][]byte
+	AssociatedDataPrefixes [][]byte
+}
+
+// AccessRequest describes a request to a KMS server when
+// evaluating the conditions of policy Rules.
+type AccessRequest struct {
+	// SourceIP is the IP address the request has been sent
+	// from. It is invalid if unknown.
+	SourceIP netip.Addr
+
+	// Time is the point in time when the request has been
+	// received. It is zero if unknown.
+	Time time.Time
+
+	// BatchSize is the number of commands within the request.
+	BatchSize int
+
+	// AssociatedData is the associated data of the command,
+	// if any.
+	AssociatedData []byte
+}
+
+// Applies reports whether all conditions of the Rule hold
+// for the given request. Conditions on request attributes
+// that are unknown, like the source IP, don't hold. A nil
+// req is equal to an AccessRequest with no known attributes.
+func (r *Rule) Applies(req *AccessRequest) bool { return r.applies(req, false) }
+
+// applies reports whether all conditions of the Rule hold for
+// the given request. If unknown is true, conditions on unknown
+// request attributes hold. Otherwise, they don't.
+func (r *Rule) applies(req *AccessRequest, unknown bool) bool {
+	if req == nil {
+		req = &AccessRequest{}
+	}
+
+	if len(r.SourceIPs) > 0 {
+		if !req.SourceIP.IsValid() {
+			if !unknown {
+				return false
+			}
+		} else if addr := req.SourceIP.Unmap(); !slices.ContainsFunc(r.SourceIPs, func(p netip.Prefix) bool { return p.Contains(addr) }) {
+			return false
+		}
+	}
+	if !r.NotBefore.IsZero() || !r.NotAfter.IsZero() {
+		if req.Time.IsZero() {
+			if !unknown {
+				return false
+			}
+		} else if !r.NotBefore.IsZero() && req.Time.Before(r.NotBefore) {
+			return false
+		} else if !r.NotAfter.IsZero() && req.Time.After(r.NotAfter) {
+			return false
+		}
+	}
+	if r.MaxBatchSize > 0 && req.BatchSize > r.MaxBatchSize {
+		return false
+	}
+	if len(r.AssociatedData) > 0 || len(r.AssociatedDataPrefixes) > 0 {
+		return r.appliesToData(req.AssociatedData)
+	}
+	return true
+}
+
+// appliesToData reports whether the associated data is equal to
+// one of the Rule's AssociatedData values or starts with one of
+// its AssociatedDataPrefixes.
+func (r *Rule) appliesToData(data []byte) bool {
+	equal := func(v []byte) bool { return bytes.Equal(v, data) }
+	prefix := func(v []byte) bool { return bytes.HasPrefix(data, v) }
+	return slices.ContainsFunc(r.AssociatedData, equal) || slices.ContainsFunc(r.AssociatedDataPrefixes, prefix)
+}
+
+// IsEmpty reports whether the Rule has no conditions
+// and, therefore, applies to any request.
+func (r *Rule) IsEmpty() bool {
+	return len(r.SourceIPs) == 0 &&
+		r.NotBefore.IsZero() &&
+		r.NotAfter.IsZero() &&
+		r.MaxBatchSize == 0 &&
+		len(r.AssociatedData) == 0 &&
+		len(r.AssociatedDataPrefixes) == 0
+}
+
+// Validate returns an error if the Rule contains invalid
+// conditions. For example, an invalid IP network or a time
+// window that ends before it starts.
+func (r *Rule) Validate() error {
+	for _, p := range r.SourceIPs {
+		if !p.IsValid() {
+			return errors.New("kms: invalid rule: invalid source IP network '" + p.String() + "'")
+		}
+	}
+	if !r.NotBefore.IsZero() && !r.NotAfter.IsZero() && r.NotAfter.Before(r.NotBefore) {
+		return errors.New("kms: invalid rule: 'not_after' is before 'not_before'")
+	}
+	if r.MaxBatchSize < 0 {
+		return errors.New("kms: invalid rule: 'max_batch_size' is negative")
+	}
+	return nil
+}
+
+// MarshalPB converts the Rule into its protobuf representation.
+func (r *Rule) MarshalPB(v *pb.Rule) error {
+	if err := r.Validate(); err != nil {
+		return err
+	}
+
+	v.SourceIPs = nil
+	for _, p := range r.SourceIPs {
+		v.SourceIPs = append(v.SourceIPs, p.String())
+	}
+	v.NotBefore, v.NotAfter = nil, nil
+	if !r.NotBefore.IsZero() {
+		v.NotBefore = pb.Time(r.NotBefore)
+	}
+	if !r.NotAfter.IsZero() {
+		v.NotAfter = pb.Time(r.NotAfter)
+	}
+	v.MaxBatchSize = uint32(r.MaxBatchSize)
+	v.AssociatedData = r.AssociatedData
+	v.AssociatedDataPrefixes = r.AssociatedDataPrefixes
+	return nil
+}
+
+// UnmarshalPB initializes the Rule from its protobuf representation.
+func (r *Rule) UnmarshalPB(v *pb.Rule) error {
+	var rule Rule
+	for _, s := range v.SourceIPs {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return errors.New("kms: invalid rule: invalid source IP network '" + s + "'")
+		}
+		rule.SourceIPs = append(rule.SourceIPs, p.Masked())
+	}
+	if v.NotBefore != nil {
+		rule.NotBefore = v.NotBefore.AsTime()
+	}
+	if v.NotAfter != nil {
+		rule.NotAfter = v.NotAfter.AsTime()
+	}
+	if v.MaxBatchSize > math.MaxInt32 {
+		return errors.New("kms: invalid rule: 'max_batch_size' is too large")
+	}
+	rule.MaxBatchSize = int(v.MaxBatchSize)
+	rule.AssociatedData = v.AssociatedData
+	rule.AssociatedDataPrefixes = v.AssociatedDataPrefixes
+
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	*r = rule
+	return nil
+}
+
+// MarshalJSON returns the Rule's JSON representation. Conditions
+// that are not set are omitted. Associated data values and prefixes
+// are base64-encoded.
+func (r Rule) MarshalJSON() ([]byte, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	type JSON struct {
+		SourceIPs              []netip.Prefix `json:"source_ips,omitempty"`
+		NotBefore              *time.Time     `json:"not_before,omitempty"`
+		NotAfter               *time.Time     `json:"not_after,omitempty"`
+		MaxBatchSize           int            `json:"max_batch_size,omitempty"`
+		AssociatedData         [][]byte       `json:"associated_data,omitempty"`
+		AssociatedDataPrefixes [][]byte       `json:"associated_data_prefixes,omitempty"`
+	}
+	v := JSON{
+		SourceIPs:              r.SourceIPs,
+		MaxBatchSize:           r.MaxBatchSize,
+		AssociatedData:         r.AssociatedData,
+		AssociatedDataPrefixes: r.AssociatedDataPrefixes,
+	}
+	if !r.NotBefore.IsZero() {
+		v.NotBefore = &r.NotBefore
+	}
+	if !r.NotAfter.IsZero() {
+		v.NotAfter = &r.NotAfter
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON initializes the Rule from its JSON representation.
+// It returns an error if the Rule contains invalid conditions.
+func (r *Rule) UnmarshalJSON(b []byte) error {
+	type JSON struct {
+		SourceIPs              []netip.Prefix `json:"source_ips"`
+		NotBefore              time.Time      `json:"not_before"`
+		NotAfter               time.Time      `json:"not_after"`
+		MaxBatchSize           int            `json:"max_batch_size"`
+		AssociatedData         [][]byte       `json:"associated_data"`
+		AssociatedDataPrefixes [][]byte       `json:"associated_data_prefixes"`
+	}
+	var v JSON
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	rule := Rule{
+		NotBefore:              v.NotBefore,
+		NotAfter:               v.NotAfter,
+		MaxBatchSize:           v.MaxBatchSize,
+		AssociatedData:         v.AssociatedData,
+		AssociatedDataPrefixes: v.AssociatedDataPrefixes,
+	}
+	for _, p := range v.SourceIPs {
+		rule.SourceIPs = append(rule.SourceIPs, p.Masked())
+	}
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	*r = rule
+	return nil
+}
 
 // A RuleSet is a set of patterns and their associated rules.
 // It defines which rule should be applied when an argument
@@ -58,16 +329,47 @@ type Rule struct{}
 // RuleSet's in memory representation and allows future extensions.
 type RuleSet map[string]Rule
 
+// Match reports whether the resource matches at least one of
+// the RuleSet's patterns.
+//
+// A pattern ending with '*' matches all resources starting with
+// the pattern's prefix. Any other pattern only matches resources
+// equal to it. Empty patterns never match.
+func (r RuleSet) Match(resource string) bool {
+	for pattern := range r {
+		if match(pattern, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// match reports whether s matches the pattern.
+func match(pattern, s string) bool {
+	if pattern == "" {
+		return false
+	}
+	if i := len(pattern) - 1; pattern[i] == '*' {
+		return strings.HasPrefix(s, pattern[:i])
+	}
+	return s == pattern
+}
+
 // MarshalPB converts the RuleSet into its protobuf representation.
 func (r *RuleSet) MarshalPB(v *pb.RuleSet) error {
 	rs := *r
 
 	v.Rules = make(map[string]*pb.Rule, len(rs))
-	for pattern := range rs {
+	for pattern, rule := range rs {
 		if pattern == "" {
 			continue
 		}
-		v.Rules[pattern] = &pb.Rule{}
+
+		r := new(pb.Rule)
+		if err := rule.MarshalPB(r); err != nil {
+			return err
+		}
+		v.Rules[pattern] = r
 	}
 	return nil
 }
@@ -77,11 +379,18 @@ func (r *RuleSet) UnmarshalPB(v *pb.RuleSet) error {
 	*r = make(RuleSet, len(v.Rules))
 
 	rs := *r
-	for pattern := range v.Rules {
+	for pattern, rule := range v.Rules {
 		if pattern == "" {
 			continue
 		}
-		rs[pattern] = Rule{}
+
+		var rr Rule
+		if rule != nil {
+			if err := rr.UnmarshalPB(rule); err != nil {
+				return err
+			}
+		}
+		rs[pattern] = rr
 	}
 	return nil
 }
@@ -100,12 +409,9 @@ func (r RuleSet) MarshalJSON() ([]byte, error) {
 		return []byte{'[', ']'}, nil
 	}
 
-	var (
-		empty   = Rule{}
-		hasRule bool
-	)
+	var hasRule bool
 	for _, rule := range r {
-		if rule != empty {
+		if !rule.IsEmpty() {
 			hasRule = true
 			break
 		}
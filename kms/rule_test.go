@@ -7,7 +7,12 @@ package kms
 import (
 	"encoding/json"
 	"maps"
+	"net/netip"
+	"reflect"
 	"testing"
+	"time"
+
+	pb "github.com/minio/kms-go/kms/protobuf"
 )
 
 func TestRuleSet_Marshal(t *testing.T) {
@@ -41,7 +46,7 @@ func TestRuleSet_Unmarshal(t *testing.T) {
 			if test.ShouldFail {
 				continue
 			}
-			if !maps.Equal(set, test.Set) {
+			if !maps.EqualFunc(set, test.Set, equalRule) {
 				t.Fatalf("Test %d: RuleSet mismatch: got '%v' - want '%v'", i, set, test.Set)
 			}
 		}
@@ -68,6 +73,15 @@ var marshalRuleSetTests = []struct {
 		Set:  RuleSet{"my-key": {}, "foo": {}, "bar": {}},
 		JSON: `["bar","foo","my-key"]`,
 	},
+	{
+		Set: RuleSet{"my-key": {}, "foo*": {
+			SourceIPs:              []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+			NotAfter:               time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			MaxBatchSize:           10,
+			AssociatedDataPrefixes: [][]byte{[]byte("bucket=")},
+		}},
+		JSON: `{"foo*":{"source_ips":["10.0.0.0/8"],"not_after":"2025-01-01T00:00:00Z","max_batch_size":10,"associated_data_prefixes":["YnVja2V0PQ=="]},"my-key":{}}`,
+	},
 }
 
 var unmarshalRuleSetTests = []struct {
@@ -95,4 +109,153 @@ var unmarshalRuleSetTests = []struct {
 		JSON: []string{`["my-key", "foo", "bar*"]`, `{"my-key":{}, "foo": {}, "bar*": {}}`},
 		Set:  RuleSet{"my-key": {}, "foo": {}, "bar*": {}},
 	},
+	{
+		JSON: []string{`{"my-key":{"source_ips":["10.1.2.3/16", "::1/128"], "not_before":"2024-01-01T00:00:00Z"}}`},
+		Set: RuleSet{"my-key": {
+			SourceIPs: []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16"), netip.MustParsePrefix("::1/128")},
+			NotBefore: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		}},
+	},
+	{
+		JSON: []string{`{"my-key":{"associated_data":["YnVja2V0PWEK"], "associated_data_prefixes":["YnVja2V0PQ=="]}}`},
+		Set: RuleSet{"my-key": {
+			AssociatedData:         [][]byte{[]byte("bucket=a\n")},
+			AssociatedDataPrefixes: [][]byte{[]byte("bucket=")},
+		}},
+	},
+	{
+		JSON:       []string{`{"my-key":{"source_ips":["10.0.0.0/33"]}}`},
+		ShouldFail: true,
+	},
+	{
+		JSON:       []string{`{"my-key":{"not_before":"2025-01-01T00:00:00Z", "not_after":"2024-01-01T00:00:00Z"}}`},
+		ShouldFail: true,
+	},
+	{
+		JSON:       []string{`{"my-key":{"max_batch_size":-1}}`},
+		ShouldFail: true,
+	},
+}
+
+func TestRule_Applies(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range ruleAppliesTests {
+		if applies := test.Rule.Applies(&test.Request); applies != test.Applies {
+			t.Fatalf("Test %d: got '%v' - want '%v'", i, applies, test.Applies)
+		}
+	}
+
+	if rule := (Rule{}); !rule.Applies(nil) {
+		t.Fatal("Empty rule should apply to nil request")
+	}
+	if rule := (Rule{MaxBatchSize: 1, NotBefore: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}); rule.Applies(nil) {
+		t.Fatal("Conditional rule should not apply to nil request")
+	}
+}
+
+func TestRule_MarshalPB(t *testing.T) {
+	t.Parallel()
+
+	for i, test := range ruleAppliesTests {
+		var v pb.Rule
+		if err := test.Rule.MarshalPB(&v); err != nil {
+			t.Fatalf("Test %d: failed to marshal Rule: %v", i, err)
+		}
+
+		var rule Rule
+		if err := rule.UnmarshalPB(&v); err != nil {
+			t.Fatalf("Test %d: failed to unmarshal Rule: %v", i, err)
+		}
+		if !equalRule(rule, test.Rule) {
+			t.Fatalf("Test %d: Rule mismatch: got '%+v' - want '%+v'", i, rule, test.Rule)
+		}
+	}
+}
+
+var ruleAppliesTests = []struct {
+	Rule    Rule
+	Request AccessRequest
+	Applies bool
+}{
+	{ // 0
+		Rule:    Rule{},
+		Request: AccessRequest{},
+		Applies: true,
+	},
+	{ // 1
+		Rule:    Rule{SourceIPs: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}},
+		Request: AccessRequest{SourceIP: netip.MustParseAddr("10.1.2.3")},
+		Applies: true,
+	},
+	{ // 2
+		Rule:    Rule{SourceIPs: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}},
+		Request: AccessRequest{SourceIP: netip.MustParseAddr("::ffff:10.1.2.3")},
+		Applies: true,
+	},
+	{ // 3
+		Rule:    Rule{SourceIPs: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}},
+		Request: AccessRequest{SourceIP: netip.MustParseAddr("192.168.1.1")},
+		Applies: false,
+	},
+	{ // 4
+		Rule:    Rule{SourceIPs: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}},
+		Request: AccessRequest{},
+		Applies: false,
+	},
+	{ // 5
+		Rule: Rule{
+			NotBefore: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			NotAfter:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		Request: AccessRequest{Time: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		Applies: true,
+	},
+	{ // 6
+		Rule:    Rule{NotAfter: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		Request: AccessRequest{Time: time.Date(2025, 1, 1, 0, 0, 1, 0, time.UTC)},
+		Applies: false,
+	},
+	{ // 7
+		Rule:    Rule{NotBefore: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		Request: AccessRequest{Time: time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)},
+		Applies: false,
+	},
+	{ // 8
+		Rule:    Rule{MaxBatchSize: 2},
+		Request: AccessRequest{BatchSize: 2},
+		Applies: true,
+	},
+	{ // 9
+		Rule:    Rule{MaxBatchSize: 2},
+		Request: AccessRequest{BatchSize: 3},
+		Applies: false,
+	},
+	{ // 10
+		Rule:    Rule{AssociatedData: [][]byte{[]byte("a")}, AssociatedDataPrefixes: [][]byte{[]byte("bucket=")}},
+		Request: AccessRequest{AssociatedData: []byte("bucket=my-bucket")},
+		Applies: true,
+	},
+	{ // 11
+		Rule:    Rule{AssociatedData: [][]byte{[]byte("a")}, AssociatedDataPrefixes: [][]byte{[]byte("bucket=")}},
+		Request: AccessRequest{AssociatedData: []byte("a")},
+		Applies: true,
+	},
+	{ // 12
+		Rule:    Rule{AssociatedData: [][]byte{[]byte("a")}, AssociatedDataPrefixes: [][]byte{[]byte("bucket=")}},
+		Request: AccessRequest{AssociatedData: []byte("ab")},
+		Applies: false,
+	},
+	{ // 13
+		Rule:    Rule{AssociatedDataPrefixes: [][]byte{[]byte("bucket=")}},
+		Request: AccessRequest{},
+		Applies: false,
+	},
+	{ // 14
+		Rule:    Rule{NotAfter: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		Request: AccessRequest{},
+		Applies: false,
+	},
 }
+
+func equalRule(a, b Rule) bool { return reflect.DeepEqual(a, b) }
@@ -0,0 +1,221 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package kms
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/kms-go/kms/internal/https"
+)
+
+// HostSelector selects the KMS server a Client sends a request
+// to. Implementations must be safe for concurrent use.
+//
+// The Client selects a KMS server once per request. Retries
+// are sent to the next KMS server that is not excluded.
+type HostSelector interface {
+	// Select returns one of hosts. The hosts contain at
+	// least two KMS servers that are not excluded, for
+	// example since a request to them has failed.
+	Select(hosts []string) string
+
+	// Begin is called whenever the Client sends a request
+	// to host. The returned function, if not nil, is called
+	// with the request latency once the response headers
+	// have been received or sending the request has failed.
+	Begin(host string) func(latency time.Duration, err error)
+}
+
+// RoundRobin returns a HostSelector that selects
+// KMS servers in turn.
+func RoundRobin() HostSelector { return &roundRobin{} }
+
+// LeastLatency returns a HostSelector that prefers KMS servers
+// with a low latency. It tracks the exponentially weighted moving
+// average (EWMA) of the latencies of successful requests per KMS
+// server. Out of two randomly chosen KMS servers, it selects the
+// one with the lower average latency.
+//
+// KMS servers that haven't been selected for some time are treated
+// as if their latency were unknown, such that their latency gets
+// measured again.
+func LeastLatency() HostSelector { return &leastLatency{} }
+
+// LeastRequests returns a HostSelector that selects the
+// KMS server with the fewest requests in flight. Ties are
+// broken randomly.
+func LeastRequests() HostSelector { return &leastRequests{} }
+
+// ZoneSelector is a HostSelector that prefers KMS servers within
+// some zones, for example the availability zone of the Client,
+// over KMS servers in other zones.
+//
+// It selects a KMS server within the first zone in which at least
+// one KMS server is not excluded. If all KMS servers within all
+// zones are excluded, it falls back to any KMS server.
+type ZoneSelector struct {
+	// Zones is the list of zones in order of preference.
+	// Each zone is the list of KMS servers within it.
+	Zones [][]string
+
+	// Selector, if not nil, selects a KMS server within a
+	// zone or, if falling back, across all KMS servers. If
+	// nil, KMS servers are selected randomly.
+	Selector HostSelector
+}
+
+var _ HostSelector = (*ZoneSelector)(nil) // compiler check
+
+// Select returns one of hosts within the first zone containing
+// any of hosts, or one of all hosts if there is no such zone.
+func (s *ZoneSelector) Select(hosts []string) string {
+	candidates := make([]string, 0, len(hosts))
+	for _, zone := range s.Zones {
+		candidates = candidates[:0]
+		for _, host := range hosts {
+			if slices.Contains(zone, host) {
+				candidates = append(candidates, host)
+			}
+		}
+		if len(candidates) > 0 {
+			return s.selectHost(candidates)
+		}
+	}
+	return s.selectHost(hosts)
+}
+
+// Begin calls the Begin method of the Selector, if any.
+func (s *ZoneSelector) Begin(host string) func(time.Duration, error) {
+	if s.Selector == nil {
+		return nil
+	}
+	return s.Selector.Begin(host)
+}
+
+func (s *ZoneSelector) selectHost(hosts []string) string {
+	switch {
+	case len(hosts) == 1:
+		return hosts[0]
+	case s.Selector == nil:
+		return hosts[rand.Intn(len(hosts))]
+	default:
+		return s.Selector.Select(hosts)
+	}
+}
+
+type roundRobin struct {
+	n atomic.Uint64
+}
+
+func (r *roundRobin) Select(hosts []string) string {
+	return hosts[(r.n.Add(1)-1)%uint64(len(hosts))]
+}
+
+func (*roundRobin) Begin(string) func(time.Duration, error) { return nil }
+
+// ewmaTTL is the amount of time after which the
+// latency of a KMS server is no longer known if it
+// hasn't been measured again.
+const ewmaTTL = 10 * time.Second
+
+type leastLatency struct {
+	mu      sync.Mutex
+	latency map[string]ewma
+}
+
+// ewma is the moving average of a KMS server's latency.
+type ewma struct {
+	Latency time.Duration
+	Updated time.Time
+}
+
+func (l *leastLatency) Select(hosts []string) string {
+	i, j := rand.Intn(len(hosts)), rand.Intn(len(hosts)-1)
+	if j >= i {
+		j++
+	}
+	a, b := hosts[i], hosts[j]
+
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.score(a, now) <= l.score(b, now) {
+		return a
+	}
+	return b
+}
+
+func (l *leastLatency) Begin(host string) func(time.Duration, error) {
+	return func(latency time.Duration, err error) {
+		if err != nil {
+			return
+		}
+
+		now := time.Now()
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if l.latency == nil {
+			l.latency = map[string]ewma{}
+		}
+		if avg, ok := l.latency[host]; ok && now.Sub(avg.Updated) < ewmaTTL {
+			latency = https.MovingAverage(avg.Latency, latency)
+		}
+		l.latency[host] = ewma{Latency: latency, Updated: now}
+	}
+}
+
+// score returns the average latency of host, or 0 if it
+// is unknown. The caller must hold l.mu.
+func (l *leastLatency) score(host string, now time.Time) time.Duration {
+	if avg, ok := l.latency[host]; ok && now.Sub(avg.Updated) < ewmaTTL {
+		return avg.Latency
+	}
+	return 0
+}
+
+type leastRequests struct {
+	mu       sync.Mutex
+	inFlight map[string]int
+}
+
+func (l *leastRequests) Select(hosts []string) string {
+	r := rand.Intn(len(hosts))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	host := hosts[r]
+	for i := 1; i < len(hosts); i++ {
+		if h := hosts[(r+i)%len(hosts)]; l.inFlight[h] < l.inFlight[host] {
+			host = h
+		}
+	}
+	return host
+}
+
+func (l *leastRequests) Begin(host string) func(time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight == nil {
+		l.inFlight = map[string]int{}
+	}
+	l.inFlight[host]++
+
+	return func(time.Duration, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if l.inFlight[host]--; l.inFlight[host] <= 0 {
+			delete(l.inFlight, host)
+		}
+	}
+}
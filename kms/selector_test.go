@@ -0,0 +1,171 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package kms_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/kms-go/kms"
+	"github.com/minio/kms-go/kms/kmstest"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRoundRobin(t *testing.T) {
+	t.Parallel()
+
+	hosts := []string{"kms-1:7373", "kms-2:7373", "kms-3:7373"}
+	selector := kms.RoundRobin()
+	for i := 0; i < 2*len(hosts); i++ {
+		if host := selector.Select(hosts); host != hosts[i%len(hosts)] {
+			t.Fatalf("Invalid host: got '%s' - want '%s'", host, hosts[i%len(hosts)])
+		}
+	}
+}
+
+func TestLeastLatency(t *testing.T) {
+	t.Parallel()
+
+	hosts := []string{"kms-1:7373", "kms-2:7373"}
+	selector := kms.LeastLatency()
+	selector.Begin(hosts[0])(10*time.Millisecond, nil)
+	selector.Begin(hosts[1])(time.Millisecond, nil)
+
+	for i := 0; i < 10; i++ {
+		if host := selector.Select(hosts); host != hosts[1] {
+			t.Fatalf("Invalid host: got '%s' - want '%s'", host, hosts[1])
+		}
+	}
+
+	// The average adapts to new latencies, but failed
+	// requests are not taken into account.
+	for i := 0; i < 10; i++ {
+		selector.Begin(hosts[1])(100*time.Millisecond, nil)
+		selector.Begin(hosts[0])(time.Nanosecond, context.DeadlineExceeded)
+	}
+	if host := selector.Select(hosts); host != hosts[0] {
+		t.Fatalf("Invalid host: got '%s' - want '%s'", host, hosts[0])
+	}
+}
+
+func TestLeastRequests(t *testing.T) {
+	t.Parallel()
+
+	hosts := []string{"kms-1:7373", "kms-2:7373", "kms-3:7373"}
+	selector := kms.LeastRequests()
+	done1 := selector.Begin(hosts[0])
+	selector.Begin(hosts[1])
+	selector.Begin(hosts[1])
+
+	for i := 0; i < 10; i++ {
+		if host := selector.Select(hosts); host != hosts[2] {
+			t.Fatalf("Invalid host: got '%s' - want '%s'", host, hosts[2])
+		}
+	}
+
+	selector.Begin(hosts[2])
+	selector.Begin(hosts[2])
+	done1(time.Millisecond, nil)
+	for i := 0; i < 10; i++ {
+		if host := selector.Select(hosts); host != hosts[0] {
+			t.Fatalf("Invalid host: got '%s' - want '%s'", host, hosts[0])
+		}
+	}
+}
+
+func TestZoneSelector(t *testing.T) {
+	t.Parallel()
+
+	selector := &kms.ZoneSelector{
+		Zones: [][]string{
+			{"kms-1:7373", "kms-2:7373"},
+			{"kms-3:7373"},
+		},
+		Selector: kms.RoundRobin(),
+	}
+	for i, test := range zoneSelectorTests {
+		for _, want := range test.Selected {
+			if host := selector.Select(test.Hosts); host != want {
+				t.Fatalf("Test %d: got '%s' - want '%s'", i, host, want)
+			}
+		}
+	}
+}
+
+var zoneSelectorTests = []struct {
+	Hosts    []string
+	Selected []string
+}{
+	{ // 0
+		Hosts:    []string{"kms-1:7373", "kms-2:7373", "kms-3:7373", "kms-4:7373"},
+		Selected: []string{"kms-1:7373", "kms-2:7373", "kms-1:7373", "kms-2:7373"},
+	},
+	{ // 1
+		Hosts:    []string{"kms-2:7373", "kms-3:7373", "kms-4:7373"},
+		Selected: []string{"kms-2:7373", "kms-2:7373"},
+	},
+	{ // 2
+		Hosts:    []string{"kms-3:7373", "kms-4:7373"},
+		Selected: []string{"kms-3:7373", "kms-3:7373"},
+	},
+	{ // 3
+		Hosts:    []string{"kms-4:7373", "kms-5:7373"},
+		Selected: []string{"kms-4:7373", "kms-5:7373"},
+	},
+}
+
+func TestClient_HostSelector(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	const (
+		Enclave     = kmstest.DefaultEnclave
+		Unreachable = "127.0.0.1:1"
+	)
+	var (
+		ctx     = context.Background()
+		metrics = kms.NewMetrics()
+		rootCAs = x509.NewCertPool()
+	)
+	rootCAs.AddCert(srv.Certificate())
+
+	// Once the unreachable host is excluded, requests
+	// are sent to the other zone without retries.
+	client, err := kms.NewClient(&kms.Config{
+		Endpoints: []string{Unreachable, srv.Host()},
+		APIKey:    srv.APIKey,
+		TLS:       &tls.Config{RootCAs: rootCAs},
+		Metrics:   metrics,
+		HostSelector: &kms.ZoneSelector{
+			Zones:    [][]string{{Unreachable}, {srv.Host()}},
+			Selector: kms.LeastLatency(),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	for i := 0; i < 16; i++ {
+		name := "my-key-" + strconv.Itoa(i)
+		if err = client.CreateKey(ctx, Enclave, &kms.CreateKeyRequest{Name: name}); err != nil {
+			t.Fatalf("Failed to create key: %v", err)
+		}
+	}
+	expected := `
+# HELP kms_client_retries_total Number of requests retried with another host.
+# TYPE kms_client_retries_total counter
+kms_client_retries_total{host="` + srv.Host() + `"} 1
+`
+	if err = testutil.CollectAndCompare(metrics, strings.NewReader(expected), "kms_client_retries_total"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package kms
+
+import "github.com/minio/kms-go/kms/internal/https"
+
+// CircuitState is the state of a KMS server's circuit breaker.
+// Refer to CircuitBreaker.
+type CircuitState = https.CircuitState
+
+// All circuit breaker states.
+const (
+	// CircuitClosed indicates that the Client
+	// sends requests to the KMS server.
+	CircuitClosed = https.CircuitClosed
+
+	// CircuitOpen indicates that the KMS server is
+	// excluded since requests to it have failed.
+	CircuitOpen = https.CircuitOpen
+
+	// CircuitHalfOpen indicates that the KMS server is no
+	// longer excluded but a single failed request excludes
+	// it again.
+	CircuitHalfOpen = https.CircuitHalfOpen
+)
+
+// HostStats contains statistics about the requests a Client has
+// sent to a KMS server and the state of its circuit breaker.
+//
+// A request is successful if a response has been received, even
+// if it is an error response, unless the KMS server is unavailable,
+// i.e. it responded with 502, 503 or 504. Latency is the moving
+// average of the latencies of recent successful requests.
+type HostStats = https.HostStats
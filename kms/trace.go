@@ -0,0 +1,56 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package kms
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation name of the Client's tracer.
+const tracerName = "github.com/minio/kms-go/kms"
+
+// traceInterceptor returns an Interceptor that records every
+// call as span. The span carries the call's commands, enclave
+// and batch size as well as the host that handled the request.
+func traceInterceptor(tracer trace.Tracer) Interceptor {
+	return func(ctx context.Context, call *Call, next Invoker) (*http.Response, error) {
+		names := make([]string, 0, len(call.Commands))
+		for _, cmd := range call.Commands {
+			names = append(names, cmd.String())
+		}
+
+		name := "KMS"
+		if len(names) > 0 {
+			name += " " + names[0]
+		}
+		ctx, span := tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.StringSlice("kms.commands", names),
+				attribute.String("kms.enclave", call.Request.Enclave),
+				attribute.Int("kms.batch_size", len(call.Commands)),
+			),
+		)
+		defer span.End()
+
+		resp, err := next(ctx, call)
+		if call.Host != "" {
+			span.SetAttributes(attribute.String("kms.host", call.Host))
+		}
+		if call.StatusCode != 0 {
+			span.SetAttributes(attribute.Int("http.response.status_code", call.StatusCode))
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return resp, err
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2024 - MinIO, Inc. All rights reserved.
+// Use of this source code is governed by the AGPLv3
+// license that can be found in the LICENSE file.
+
+package kms_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"slices"
+	"testing"
+
+	"github.com/minio/kms-go/kms"
+	"github.com/minio/kms-go/kms/kmstest"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestClient_Tracing(t *testing.T) {
+	t.Parallel()
+
+	srv := kmstest.NewServer()
+	defer srv.Close()
+
+	const Unreachable = "127.0.0.1:1"
+	recorder := tracetest.NewSpanRecorder()
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(srv.Certificate())
+	client, err := kms.NewClient(&kms.Config{
+		Endpoints:      []string{Unreachable, srv.Host()},
+		APIKey:         srv.APIKey,
+		TLS:            &tls.Config{RootCAs: rootCAs},
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	const Enclave = kmstest.DefaultEnclave
+	ctx := context.Background()
+	if err = client.CreateKey(ctx, Enclave, &kms.CreateKeyRequest{Name: "my-key"}); err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	if _, err = client.KeyStatus(ctx, Enclave, &kms.KeyStatusRequest{Name: "my-key"}, &kms.KeyStatusRequest{Name: "my-key"}); err != nil {
+		t.Fatalf("Failed to fetch key status: %v", err)
+	}
+
+	var calls, attempts []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Parent().IsValid() {
+			attempts = append(attempts, span)
+		} else {
+			calls = append(calls, span)
+		}
+	}
+	if len(calls) != 2 {
+		t.Fatalf("Invalid number of call spans: got %d - want %d", len(calls), 2)
+	}
+	if !slices.Contains(calls[0].Attributes(), attribute.StringSlice("kms.commands", []string{"KEY:CREATE"})) {
+		t.Fatalf("Call 0: invalid attributes: got %v", calls[0].Attributes())
+	}
+	if attrs := calls[1].Attributes(); !slices.Contains(attrs, attribute.String("kms.enclave", Enclave)) || !slices.Contains(attrs, attribute.Int("kms.batch_size", 2)) {
+		t.Fatalf("Call 1: invalid attributes: got %v", attrs)
+	}
+
+	// Each request is sent to either host first. If it is sent to
+	// the unreachable host, the LB retries it with the KMS server.
+	for _, call := range calls {
+		var retries int
+		for _, span := range attempts {
+			if span.Parent().SpanID() != call.SpanContext().SpanID() {
+				continue
+			}
+
+			attrs := span.Attributes()
+			if !slices.Contains(attrs, attribute.Int("kms.retry", retries)) {
+				t.Fatalf("Call '%s': invalid retry attribute: got %v - want %d", call.Name(), attrs, retries)
+			}
+			retries++
+
+			want := attribute.String("kms.outcome", "ok")
+			if slices.Contains(attrs, attribute.String("kms.host", Unreachable)) {
+				want = attribute.String("kms.outcome", "host_error")
+			}
+			if !slices.Contains(attrs, want) {
+				t.Fatalf("Call '%s': invalid attempt outcome: got %v - want %v", call.Name(), attrs, want)
+			}
+		}
+		if retries < 1 || retries > 2 {
+			t.Fatalf("Call '%s': invalid number of attempts: got %d", call.Name(), retries)
+		}
+	}
+}